@@ -0,0 +1,494 @@
+// attach.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Attachments for Inventory CLI
+// Photos, invoices and datasheets stored as blobs in the same
+// SQLite file as the inventory, so one file carries everything.
+//
+
+package inventory
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/boseji/bsg/gen"
+)
+
+// attachmentsTable is the DDL of the attachments table.
+const attachmentsTable = `
+    CREATE TABLE IF NOT EXISTS attachments (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        item_id INTEGER NOT NULL,
+        filename TEXT NOT NULL,
+        mime_type TEXT,
+        sha256 TEXT NOT NULL,
+        size INTEGER NOT NULL,
+        created_at TEXT,
+        data BLOB
+    );`
+
+// Attachment describes a file stored with an item.
+//
+// The file content itself is not part of this struct,
+// use ExtractAttachment() or GetAttachmentData() to read it.
+//
+// Fields:
+//
+//	ID        - auto-increment primary key
+//	ItemID    - inventory item the file belongs to
+//	Filename  - base name of the original file
+//	MIMEType  - content type, e.g. "application/pdf"
+//	SHA256    - hex encoded SHA-256 of the content
+//	Size      - content length in bytes
+//	CreatedAt - time of attaching, "YYYY-MM-DD HH:MM"
+type Attachment struct {
+	ID        int    `json:"id"`
+	ItemID    int    `json:"item_id"`
+	Filename  string `json:"filename"`
+	MIMEType  string `json:"mime_type"`
+	SHA256    string `json:"sha256"`
+	Size      int64  `json:"size"`
+	CreatedAt string `json:"created_at"`
+}
+
+// attachmentFields is the column list scanned by scanAttachment().
+const attachmentFields = `id, item_id, filename,
+        COALESCE(mime_type, ''), sha256, size, COALESCE(created_at, '')`
+
+func scanAttachment(row rowScanner, extra ...interface{}) (
+	Attachment, error) {
+
+	var a Attachment
+	dest := append([]interface{}{
+		&a.ID, &a.ItemID, &a.Filename,
+		&a.MIMEType, &a.SHA256, &a.Size, &a.CreatedAt,
+	}, extra...)
+	err := row.Scan(dest...)
+	return a, err
+}
+
+// AttachFile stores the file at path as an attachment of the item
+// and returns the attachment ID.
+//
+// The MIME type is taken from the file extension, or detected
+// from the content when the extension is unknown.
+//
+// Usage:
+//
+//	id, err := AttachFile(tx, 1002, "invoice-2025-118.pdf")
+//
+// The item remarks record the new attachment:
+//
+//	[2025-06-21 15:00] attached invoice-2025-118.pdf (application/pdf)
+//
+// Notes:
+// - The item must exist
+// - Works with both *sql.DB and *sql.Tx.
+func AttachFile(exec ExecQuerier, itemID int, path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("read attachment failed: %v", err)
+	}
+
+	return AttachBytes(exec, itemID, filepath.Base(path), data)
+}
+
+// AttachBytes stores data as an attachment of the item under
+// the given filename and returns the attachment ID.
+//
+// Same as AttachFile(), for content that is not in a file,
+// e.g. an upload received by a web frontend.
+//
+// Works with both *sql.DB and *sql.Tx.
+func AttachBytes(
+	exec ExecQuerier, itemID int, filename string, data []byte,
+) (int, error) {
+
+	if _, err := GetItemByID(exec, itemID); err != nil {
+		return 0, fmt.Errorf("attach failed: %v", err)
+	}
+
+	filename = filepath.Base(filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		return 0, fmt.Errorf("attachment has no filename")
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(filename))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+
+	sum := sha256.Sum256(data)
+	res, err := exec.Exec(`
+        INSERT INTO attachments
+        (item_id, filename, mime_type, sha256, size, created_at, data)
+        VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		itemID, filename, mimeType, hex.EncodeToString(sum[:]),
+		len(data), gen.BST().Format("2006-01-02 15:04"), data)
+	if err != nil {
+		return 0, fmt.Errorf("insert attachment failed: %v", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("attachment id failed: %v", err)
+	}
+
+	msg := fmt.Sprintf("attached %s (%s)", filename, mimeType)
+	if err := AppendRemarksEntry(exec, itemID, msg); err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// ListAttachments returns the attachments of an item, or of all
+// items when itemID is 0, sorted by ID.
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListAttachments(db Querier, itemID int) ([]Attachment, error) {
+	rows, err := db.Query(`
+        SELECT `+attachmentFields+`
+        FROM attachments
+        WHERE ? = 0 OR item_id = ?
+        ORDER BY id`, itemID, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("attachments query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var list []Attachment
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		list = append(list, a)
+	}
+	return list, nil
+}
+
+// GetAttachmentData returns an attachment with its content.
+//
+// The content is verified against the stored SHA-256.
+//
+// Works with both *sql.DB and *sql.Tx.
+func GetAttachmentData(db Querier, id int) (Attachment, []byte, error) {
+	var data []byte
+	a, err := scanAttachment(db.QueryRow(`
+        SELECT `+attachmentFields+`, data
+        FROM attachments WHERE id = ?`, id), &data)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return a, nil, fmt.Errorf("attachment %d not found", id)
+		}
+		return a, nil, fmt.Errorf("query failed: %v", err)
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != a.SHA256 {
+		return a, nil, fmt.Errorf("attachment %d checksum mismatch", id)
+	}
+	return a, data, nil
+}
+
+// ExtractAttachment writes the content of an attachment to path.
+//
+// If path is a directory, the original filename is used inside it.
+//
+// Usage:
+//
+//	err := ExtractAttachment(db, 7, "/tmp")
+//
+// Errors:
+//   - returns error if the attachment does not exist
+//   - returns error if the checksum does not match
+//   - returns error if the file cannot be written
+func ExtractAttachment(db Querier, id int, path string) error {
+	a, data, err := GetAttachmentData(db, id)
+	if err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, a.Filename)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write attachment failed: %v", err)
+	}
+	return nil
+}
+
+// DeleteAttachment removes an attachment and logs the removal
+// in the item remarks.
+//
+// Returns error if the attachment does not exist.
+//
+// Works with both *sql.DB and *sql.Tx.
+func DeleteAttachment(exec ExecQuerier, id int) error {
+	a, err := scanAttachment(exec.QueryRow(`
+        SELECT `+attachmentFields+`
+        FROM attachments WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("attachment %d not found", id)
+		}
+		return fmt.Errorf("query failed: %v", err)
+	}
+
+	_, err = exec.Exec(`DELETE FROM attachments WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete attachment failed: %v", err)
+	}
+
+	return AppendRemarksEntry(exec, a.ItemID,
+		"removed attachment "+a.Filename)
+}
+
+// Backup writes a consistent copy of the whole database,
+// including attachments, to a new file.
+//
+// Uses SQLite "VACUUM INTO", so it is safe while the database
+// is in use and the copy is compacted.
+//
+// Usage:
+//
+//	err := Backup(db, "backup-2025-06-21.db")
+//
+// Notes:
+// - The destination file must not exist
+func Backup(db *sql.DB, filename string) error {
+	if _, err := os.Stat(filename); err == nil {
+		return fmt.Errorf("backup file %s already exists", filename)
+	}
+	_, err := db.Exec(`VACUUM INTO ?`, filename)
+	if err != nil {
+		return fmt.Errorf("backup failed: %v", err)
+	}
+	return nil
+}
+
+// ExportBundle writes a zip archive with the inventory and all
+// attachments.
+//
+// Archive layout:
+//
+//	inventory.json                  - as written by ExportJSON()
+//	attachments.json                - []Attachment metadata
+//	attachments/<item>/<id>-<name>  - attachment content
+//
+// Usage:
+//
+//	err := ExportBundle(db, "inventory-bundle.zip")
+//
+// Existing file will be overwritten.
+//
+// The archive is written straight to the file; on error the
+// partial file is removed.
+func ExportBundle(db *sql.DB, filename string) (err error) {
+	items, err := ListAll(db)
+	if err != nil {
+		return fmt.Errorf("export bundle failed: %v", err)
+	}
+	list, err := ListAttachments(db, 0)
+	if err != nil {
+		return fmt.Errorf("export bundle failed: %v", err)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("write bundle failed: %v", err)
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("write bundle failed: %v", cerr)
+		}
+		if err != nil {
+			os.Remove(filename)
+		}
+	}()
+
+	zw := zip.NewWriter(file)
+	if err := writeBundle(db, zw, items, list); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("zip close failed: %v", err)
+	}
+	return nil
+}
+
+// writeBundle adds the entries of ExportBundle() to a zip writer,
+// one attachment at a time.
+func writeBundle(db *sql.DB, zw *zip.Writer, items []Item,
+	list []Attachment) error {
+	add := func(name string, v interface{}) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal json failed: %v", err)
+		}
+		w, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("zip create failed: %v", err)
+		}
+		if _, err = w.Write(data); err != nil {
+			return fmt.Errorf("zip write failed: %v", err)
+		}
+		return nil
+	}
+
+	if err := add("inventory.json", items); err != nil {
+		return err
+	}
+	if err := add("attachments.json", list); err != nil {
+		return err
+	}
+
+	for _, a := range list {
+		_, data, err := GetAttachmentData(db, a.ID)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("attachments/%d/%d-%s", a.ItemID, a.ID,
+			strings.ReplaceAll(a.Filename, "/", "_"))
+		w, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("zip create failed: %v", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("zip write failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// AttachFile wraps AttachFile with automatic transaction.
+//
+// Usage:
+//
+//	id, err := inv.AttachFile(itemID, "invoice.pdf")
+func (inv *InventoryDB) AttachFile(itemID int, path string) (int, error) {
+	var id int
+	err := inv.withTx("attach_file", func(tx ExecQuerier) error {
+		var err error
+		id, err = AttachFile(tx, itemID, path)
+		return err
+	})
+	return id, err
+}
+
+// AttachBytes wraps AttachBytes with automatic transaction.
+//
+// Usage:
+//
+//	id, err := inv.AttachBytes(itemID, "photo.jpg", data)
+func (inv *InventoryDB) AttachBytes(
+	itemID int, filename string, data []byte) (int, error) {
+	var id int
+	err := inv.withTx("attach_bytes", func(tx ExecQuerier) error {
+		var err error
+		id, err = AttachBytes(tx, itemID, filename, data)
+		return err
+	})
+	return id, err
+}
+
+// ListAttachments wraps ListAttachments.
+//
+// Usage:
+//
+//	list, err := inv.ListAttachments(itemID)
+func (inv *InventoryDB) ListAttachments(itemID int) ([]Attachment, error) {
+	return ListAttachments(inv.db, itemID)
+}
+
+// ExtractAttachment wraps ExtractAttachment.
+//
+// Usage:
+//
+//	err := inv.ExtractAttachment(id, "invoice.pdf")
+func (inv *InventoryDB) ExtractAttachment(id int, path string) error {
+	return ExtractAttachment(inv.db, id, path)
+}
+
+// DeleteAttachment wraps DeleteAttachment with automatic transaction.
+//
+// Usage:
+//
+//	err := inv.DeleteAttachment(id)
+func (inv *InventoryDB) DeleteAttachment(id int) error {
+	return inv.withTx("delete_attachment", func(tx ExecQuerier) error {
+		return DeleteAttachment(tx, id)
+	})
+}
+
+// Backup wraps Backup.
+//
+// Usage:
+//
+//	err := inv.Backup("backup.db")
+func (inv *InventoryDB) Backup(filename string) error {
+	return Backup(inv.db, filename)
+}
+
+// ExportBundle wraps ExportBundle.
+//
+// Usage:
+//
+//	err := inv.ExportBundle("bundle.zip")
+func (inv *InventoryDB) ExportBundle(filename string) error {
+	return ExportBundle(inv.db, filename)
+}
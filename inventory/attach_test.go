@@ -0,0 +1,190 @@
+// attach_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for attachments, backups and zip bundles
+// Uses in-memory SQLite DB and temp files
+//
+
+package inventory_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func setupAttachment(t *testing.T) (*inventory.InventoryDB, int) {
+	inv := setupInventoryDB(t)
+	err := inv.AppendItem(inventory.Item{ID: 1001, Description: "UPS"})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "invoice.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.4 test"), 0644); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+
+	id, err := inv.AttachFile(1001, path)
+	if err != nil {
+		t.Fatalf("AttachFile failed: %v", err)
+	}
+	return inv, id
+}
+
+func TestInventoryDB_AttachFile(t *testing.T) {
+	inv, id := setupAttachment(t)
+	defer inv.Close()
+
+	list, err := inv.ListAttachments(1001)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListAttachments failed: %v %v", list, err)
+	}
+	a := list[0]
+	if a.ID != id || a.Filename != "invoice.pdf" ||
+		a.MIMEType != "application/pdf" || a.Size != 13 ||
+		len(a.SHA256) != 64 {
+		t.Errorf("unexpected attachment: %+v", a)
+	}
+
+	item, _ := inv.GetItemByID(1001)
+	if !strings.Contains(item.Remarks, "attached invoice.pdf") {
+		t.Errorf("missing attach remark: %q", item.Remarks)
+	}
+
+	_, err = inv.AttachBytes(9999, "photo.jpg", []byte("x"))
+	if err == nil {
+		t.Errorf("expected error for missing item")
+	}
+}
+
+func TestInventoryDB_ExtractAttachment(t *testing.T) {
+	inv, id := setupAttachment(t)
+	defer inv.Close()
+
+	dir := t.TempDir()
+	if err := inv.ExtractAttachment(id, dir); err != nil {
+		t.Fatalf("ExtractAttachment failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "invoice.pdf"))
+	if err != nil || string(data) != "%PDF-1.4 test" {
+		t.Errorf("unexpected extracted content: %q %v", data, err)
+	}
+
+	if err := inv.ExtractAttachment(9999, dir); err == nil {
+		t.Errorf("expected error for missing attachment")
+	}
+}
+
+func TestInventoryDB_DeleteAttachment(t *testing.T) {
+	inv, id := setupAttachment(t)
+	defer inv.Close()
+
+	if err := inv.DeleteAttachment(id); err != nil {
+		t.Fatalf("DeleteAttachment failed: %v", err)
+	}
+	list, _ := inv.ListAttachments(0)
+	if len(list) != 0 {
+		t.Errorf("expected no attachments, got %d", len(list))
+	}
+	if err := inv.DeleteAttachment(id); err == nil {
+		t.Errorf("expected error for deleted attachment")
+	}
+}
+
+func TestInventoryDB_Backup(t *testing.T) {
+	inv, _ := setupAttachment(t)
+	defer inv.Close()
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	if err := inv.Backup(path); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if err := inv.Backup(path); err == nil {
+		t.Errorf("expected error for existing backup file")
+	}
+
+	restored := inventory.NewInventoryDB(path)
+	defer restored.Close()
+	list, err := restored.ListAttachments(1001)
+	if err != nil || len(list) != 1 {
+		t.Errorf("backup missing attachments: %v %v", list, err)
+	}
+}
+
+func TestInventoryDB_ExportBundle(t *testing.T) {
+	inv, id := setupAttachment(t)
+	defer inv.Close()
+
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	if err := inv.ExportBundle(path); err != nil {
+		t.Fatalf("ExportBundle failed: %v", err)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open zip failed: %v", err)
+	}
+	defer zr.Close()
+
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	for _, want := range []string{
+		"inventory.json", "attachments.json",
+		"attachments/1001/" + strconv.Itoa(id) + "-invoice.pdf",
+	} {
+		if !names[want] {
+			t.Errorf("bundle missing %s: %v", want, names)
+		}
+	}
+}
@@ -0,0 +1,552 @@
+// audit.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Actors and mutation audit log for Inventory CLI
+// Every change to the inventory table is recorded by SQLite triggers
+// in the append-only audit_log table, with who, when and what.
+//
+
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/boseji/bsg/gen"
+)
+
+// AnonymousActor is recorded for changes made through an
+// InventoryDB without an actor.
+const AnonymousActor = "anonymous"
+
+// AuditTimeLayout is the format of audit log timestamps.
+const AuditTimeLayout = "2006-01-02 15:04:05"
+
+// Audit log actions, recorded per changed row.
+const (
+	AuditInsert        = "insert"
+	AuditUpdate        = "update"
+	AuditReplace       = "replace"
+	AuditDelete        = "delete"
+	AuditResetSequence = "reset_sequence"
+)
+
+// auditLogTable is the DDL of the audit_log table.
+//
+// The before and after columns hold the item row as JSON,
+// NULL for inserts (before) and deletes (after).
+// The changeset_id groups the rows changed by one InventoryDB
+// operation, see the changesets table.
+const auditLogTable = `
+    CREATE TABLE IF NOT EXISTS audit_log (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        ts TEXT NOT NULL,
+        actor TEXT,
+        operation TEXT,
+        action TEXT NOT NULL,
+        item_id INTEGER,
+        before TEXT,
+        after TEXT,
+        changeset_id INTEGER
+    );`
+
+// auditLogIndex speeds up the history of a single item.
+const auditLogIndex = `
+    CREATE INDEX IF NOT EXISTS audit_log_item
+    ON audit_log (item_id);`
+
+// auditSessionTable holds the actor and operation of the
+// transaction in progress. It has at most one row, written by
+// beginAuditSession() and removed again before commit.
+const auditSessionTable = `
+    CREATE TABLE IF NOT EXISTS audit_session (
+        ts TEXT,
+        actor TEXT,
+        operation TEXT,
+        changeset_id INTEGER
+    );`
+
+// auditSessionColumns names the audit_log columns filled
+// from auditSessionValues.
+const auditSessionColumns = `ts, actor, operation, changeset_id`
+
+// auditSessionValues selects ts, actor, operation and changeset
+// for a new audit_log row. Outside an audit session the actor,
+// operation and changeset are NULL and the time is taken from
+// SQLite in IST (UTC+05:30).
+const auditSessionValues = `
+        COALESCE((SELECT ts FROM audit_session LIMIT 1),
+            strftime('%Y-%m-%d %H:%M:%S', 'now', '+330 minutes')),
+        (SELECT actor FROM audit_session LIMIT 1),
+        (SELECT operation FROM audit_session LIMIT 1),
+        (SELECT changeset_id FROM audit_session LIMIT 1)`
+
+// actorKey is the context key for the actor name.
+type actorKey struct{}
+
+// NewActorContext returns a copy of ctx carrying the actor name.
+//
+// Usage:
+//
+//	ctx := NewActorContext(r.Context(), "alice")
+//	err := inv.WithContext(ctx).DeleteItem(1002)
+func NewActorContext(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor name stored in ctx, if any.
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok && actor != ""
+}
+
+// WithActor returns a copy of inv that records changes under
+// the given actor. The copy shares the database connection.
+//
+// Usage:
+//
+//	err := inv.WithActor("alice").EditItem(item)
+func (inv *InventoryDB) WithActor(actor string) *InventoryDB {
+	c := *inv
+	c.actor = actor
+	return &c
+}
+
+// WithContext returns a copy of inv that records changes under
+// the actor stored in ctx (see NewActorContext). If ctx has no
+// actor, inv is returned unchanged.
+func (inv *InventoryDB) WithContext(ctx context.Context) *InventoryDB {
+	if actor, ok := ActorFromContext(ctx); ok {
+		return inv.WithActor(actor)
+	}
+	return inv
+}
+
+// Actor returns the actor recorded for changes made through inv,
+// AnonymousActor if none was set.
+func (inv *InventoryDB) Actor() string {
+	if inv.actor == "" {
+		return AnonymousActor
+	}
+	return inv.actor
+}
+
+// beginAuditSession opens a changeset for the transaction and
+// records it with the actor and operation for the audit triggers.
+func beginAuditSession(exec Execer, actor, op string) error {
+	ts := gen.BST().Format(AuditTimeLayout)
+	_, err := exec.Exec(`DELETE FROM audit_session`)
+	var res sql.Result
+	if err == nil {
+		res, err = exec.Exec(`
+            INSERT INTO changesets (ts, actor, operation)
+            VALUES (?, ?, ?)`, ts, actor, op)
+	}
+	var id int64
+	if err == nil {
+		id, err = res.LastInsertId()
+	}
+	if err == nil {
+		_, err = exec.Exec(`
+            INSERT INTO audit_session (`+auditSessionColumns+`)
+            VALUES (?, ?, ?, ?)`, ts, actor, op, id)
+	}
+	if err != nil {
+		return fmt.Errorf("begin audit session failed: %v", err)
+	}
+	return nil
+}
+
+// endAuditSession removes the audit session before commit,
+// so later changes made outside InventoryDB are not
+// attributed to this actor.
+func endAuditSession(exec Execer) error {
+	_, err := exec.Exec(`DELETE FROM audit_session`)
+	if err != nil {
+		return fmt.Errorf("end audit session failed: %v", err)
+	}
+	return nil
+}
+
+// jsonValue renders a column for json_object(). REAL values are
+// printed with 17 significant digits, enough to read back the same
+// float64, where json_object() itself keeps only 15.
+func jsonValue(col string) string {
+	return fmt.Sprintf(`CASE typeof(%[1]s) WHEN 'real'
+            THEN json(printf('%%!.17g', %[1]s)) ELSE %[1]s END`, col)
+}
+
+// installAuditTriggers (re)creates the triggers that record every
+// insert, update and delete on the inventory table in audit_log,
+// and the triggers that reject updates and deletes of audit_log
+// rows.
+//
+// The item JSON is built from the actual table columns, so columns
+// added by later versions are audited without further changes.
+// Called by OpenDB after the schema is up to date.
+func installAuditTriggers(db *sql.DB) error {
+	cols, err := tableColumns(db, "inventory")
+	if err != nil {
+		return err
+	}
+
+	row := func(ref string) string {
+		parts := make([]string, len(cols))
+		for i, c := range cols {
+			parts[i] = fmt.Sprintf("'%s', %s", c, jsonValue(ref+c))
+		}
+		return "json_object(" + strings.Join(parts, ", ") + ")"
+	}
+	pending := `item_id = NEW.id AND action = 'replace' AND after IS NULL`
+
+	triggers := []string{
+		// INSERT OR REPLACE removes the old row without firing the
+		// delete trigger, so capture it before the insert.
+		`CREATE TRIGGER inventory_audit_replace
+        BEFORE INSERT ON inventory
+        WHEN NEW.id IS NOT NULL
+            AND EXISTS (SELECT 1 FROM inventory WHERE id = NEW.id)
+        BEGIN
+            INSERT INTO audit_log
+            (` + auditSessionColumns + `, action, item_id, before)
+            SELECT ` + auditSessionValues + `,
+                'replace', NEW.id, ` + row("") + `
+            FROM inventory WHERE id = NEW.id;
+        END;`,
+		`CREATE TRIGGER inventory_audit_insert
+        AFTER INSERT ON inventory
+        BEGIN
+            INSERT INTO audit_log
+            (` + auditSessionColumns + `, action, item_id, after)
+            SELECT ` + auditSessionValues + `,
+                'insert', NEW.id, ` + row("NEW.") + `
+            WHERE NOT EXISTS (
+                SELECT 1 FROM audit_log WHERE ` + pending + `);
+            UPDATE audit_log SET after = ` + row("NEW.") + `
+            WHERE ` + pending + `;
+        END;`,
+		`CREATE TRIGGER inventory_audit_update
+        AFTER UPDATE ON inventory
+        WHEN ` + row("OLD.") + ` IS NOT ` + row("NEW.") + `
+        BEGIN
+            INSERT INTO audit_log
+            (` + auditSessionColumns + `, action, item_id, before, after)
+            SELECT ` + auditSessionValues + `,
+                'update', NEW.id, ` + row("OLD.") + `, ` + row("NEW.") + `;
+        END;`,
+		`CREATE TRIGGER inventory_audit_delete
+        AFTER DELETE ON inventory
+        BEGIN
+            INSERT INTO audit_log
+            (` + auditSessionColumns + `, action, item_id, before)
+            SELECT ` + auditSessionValues + `,
+                'delete', OLD.id, ` + row("OLD.") + `;
+        END;`,
+		// audit_log is append-only. The one update allowed fills
+		// in the after row of a pending replace entry, above.
+		`CREATE TRIGGER audit_log_no_update
+        BEFORE UPDATE ON audit_log
+        WHEN NOT (OLD.action = 'replace' AND OLD.after IS NULL
+            AND NEW.id = OLD.id AND NEW.ts = OLD.ts
+            AND NEW.actor IS OLD.actor
+            AND NEW.operation IS OLD.operation
+            AND NEW.action = OLD.action
+            AND NEW.item_id IS OLD.item_id
+            AND NEW.before IS OLD.before
+            AND NEW.changeset_id IS OLD.changeset_id)
+        BEGIN
+            SELECT RAISE(ABORT, 'audit_log is append-only');
+        END;`,
+		`CREATE TRIGGER audit_log_no_delete
+        BEFORE DELETE ON audit_log
+        BEGIN
+            SELECT RAISE(ABORT, 'audit_log is append-only');
+        END;`,
+	}
+
+	for _, name := range []string{
+		"inventory_audit_replace", "inventory_audit_insert",
+		"inventory_audit_update", "inventory_audit_delete",
+		"audit_log_no_update", "audit_log_no_delete",
+	} {
+		_, err := db.Exec("DROP TRIGGER IF EXISTS " + name)
+		if err != nil {
+			return fmt.Errorf("drop audit trigger failed: %v", err)
+		}
+	}
+	for _, ddl := range triggers {
+		if _, err := db.Exec(ddl); err != nil {
+			return fmt.Errorf("create audit trigger failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// logAudit adds an audit_log entry for a change that is not
+// a row change on the inventory table, e.g. ResetSequence().
+func logAudit(exec Execer, action string) error {
+	_, err := exec.Exec(`
+        INSERT INTO audit_log (`+auditSessionColumns+`, action)
+        SELECT `+auditSessionValues+`, ?`, action)
+	if err != nil {
+		return fmt.Errorf("audit log failed: %v", err)
+	}
+	return nil
+}
+
+// AuditEntry is one record of the audit log.
+//
+// Fields:
+//
+//	ID        - sequence number, increases with every change
+//	Time      - when, in AuditTimeLayout
+//	Actor     - who, empty for changes made outside InventoryDB
+//	Operation - InventoryDB method, e.g. "edit_item", "import_csv"
+//	Action    - row change: insert, update, replace, delete
+//	ItemID    - affected item, 0 for table-wide actions
+//	Before    - item before the change, nil for inserts
+//	After     - item after the change, nil for deletes
+//	Changeset - InventoryDB operation the change belongs to,
+//	            0 for changes made outside InventoryDB
+type AuditEntry struct {
+	ID        int    `json:"id"`
+	Time      string `json:"time"`
+	Actor     string `json:"actor"`
+	Operation string `json:"operation"`
+	Action    string `json:"action"`
+	ItemID    int    `json:"item_id"`
+	Before    *Item  `json:"before,omitempty"`
+	After     *Item  `json:"after,omitempty"`
+	Changeset int    `json:"changeset,omitempty"`
+}
+
+// Changes returns the fields that differ between Before and After.
+// A missing side is treated as an empty item.
+func (e AuditEntry) Changes() []FieldChange {
+	var before, after Item
+	if e.Before != nil {
+		before = *e.Before
+	}
+	if e.After != nil {
+		after = *e.After
+	}
+	return DiffItems(before, after)
+}
+
+// AuditQuery selects audit log entries.
+//
+// Blank fields are ignored, so the zero value selects everything.
+//
+// Fields:
+//
+//	ItemID    - only entries for this item
+//	Actor     - only entries by this actor
+//	Operation - only entries of this operation
+//	Since     - entries at or after this time (prefix of AuditTimeLayout,
+//	            e.g. "2025-06-21")
+//	Until     - entries before this time
+//	AfterID   - entries with a higher sequence number (for paging)
+//	Changeset - only entries of this changeset
+//	Limit     - maximum number of entries, 0 for no limit
+type AuditQuery struct {
+	ItemID    int
+	Actor     string
+	Operation string
+	Since     string
+	Until     string
+	AfterID   int
+	Changeset int
+	Limit     int
+}
+
+// ListAudit returns audit log entries matching the query,
+// oldest first.
+//
+// Usage:
+//
+//	entries, err := ListAudit(db, AuditQuery{ItemID: 1002})
+//	for _, e := range entries {
+//	    fmt.Println(e.Time, e.Actor, e.Operation, e.Changes())
+//	}
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListAudit(db Querier, q AuditQuery) ([]AuditEntry, error) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if q.ItemID != 0 {
+		conds = append(conds, "item_id = ?")
+		args = append(args, q.ItemID)
+	}
+	if q.Actor != "" {
+		conds = append(conds, "actor = ?")
+		args = append(args, q.Actor)
+	}
+	if q.Operation != "" {
+		conds = append(conds, "operation = ?")
+		args = append(args, q.Operation)
+	}
+	if q.Since != "" {
+		conds = append(conds, "ts >= ?")
+		args = append(args, q.Since)
+	}
+	if q.Until != "" {
+		conds = append(conds, "ts < ?")
+		args = append(args, q.Until)
+	}
+	if q.AfterID != 0 {
+		conds = append(conds, "id > ?")
+		args = append(args, q.AfterID)
+	}
+	if q.Changeset != 0 {
+		conds = append(conds, "changeset_id = ?")
+		args = append(args, q.Changeset)
+	}
+
+	query := `
+        SELECT id, ts, COALESCE(actor, ''), COALESCE(operation, ''),
+            action, COALESCE(item_id, 0),
+            COALESCE(before, ''), COALESCE(after, ''),
+            COALESCE(changeset_id, 0)
+        FROM audit_log`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY id"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("audit query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var (
+			e             AuditEntry
+			before, after string
+		)
+		err := rows.Scan(&e.ID, &e.Time, &e.Actor, &e.Operation,
+			&e.Action, &e.ItemID, &before, &after, &e.Changeset)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		if e.Before, err = auditItem(before); err != nil {
+			return nil, fmt.Errorf("audit %d: %v", e.ID, err)
+		}
+		if e.After, err = auditItem(after); err != nil {
+			return nil, fmt.Errorf("audit %d: %v", e.ID, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// auditItem decodes an item row stored as JSON, nil if blank.
+func auditItem(data string) (*Item, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var item Item
+	if err := json.Unmarshal([]byte(data), &item); err != nil {
+		return nil, fmt.Errorf("unmarshal audit item failed: %v", err)
+	}
+	return &item, nil
+}
+
+// WriteAuditReport prints audit entries, one per line,
+// followed by their field changes:
+//
+//	#12 2025-06-21 15:00:02 alice edit_item update 1002
+//	    location: Rack 1 → Rack 5
+//	    remarks: + [2025-06-21 15:00] moved
+//
+// Remarks changes show only the appended text.
+//
+// Errors are returned if writing fails.
+func WriteAuditReport(w io.Writer, entries []AuditEntry) error {
+	for _, e := range entries {
+		actor := e.Actor
+		if actor == "" {
+			actor = "-"
+		}
+		_, err := fmt.Fprintf(w, "#%d %s %s %s %s %d\n",
+			e.ID, e.Time, actor, e.Operation, e.Action, e.ItemID)
+		if err != nil {
+			return fmt.Errorf("write report failed: %v", err)
+		}
+
+		for _, c := range e.Changes() {
+			line := c.String()
+			if c.Field == "remarks" &&
+				strings.HasPrefix(c.After, c.Before) {
+				line = "remarks: + " +
+					strings.TrimSpace(c.After[len(c.Before):])
+			}
+			if _, err := fmt.Fprintf(w, "    %s\n", line); err != nil {
+				return fmt.Errorf("write report failed: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// ListAudit wraps ListAudit.
+//
+// Usage:
+//
+//	entries, err := inv.ListAudit(AuditQuery{Actor: "alice"})
+func (inv *InventoryDB) ListAudit(q AuditQuery) ([]AuditEntry, error) {
+	return ListAudit(inv.db, q)
+}
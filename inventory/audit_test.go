@@ -0,0 +1,279 @@
+// audit_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for actors and the mutation audit log
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestInventoryDB_Audit_Mutations(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	alice := inv.WithActor("alice")
+	err := alice.AppendItem(inventory.Item{
+		ID: 1001, Description: "UPS", Location: "Rack 1",
+	})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+
+	item, _ := inv.GetItemByID(1001)
+	item.Location = "Rack 5"
+	item.Remarks = "moved"
+	ctx := inventory.NewActorContext(context.Background(), "bob")
+	if err := inv.WithContext(ctx).EditItem(item); err != nil {
+		t.Fatalf("EditItem failed: %v", err)
+	}
+
+	// Replace keeps the previous row in the log
+	if err := inv.AppendItem(inventory.Item{
+		ID: 1001, Description: "UPS 3KVA", Location: "Rack 5",
+	}); err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+
+	if err := alice.DeleteItem(1001); err != nil {
+		t.Fatalf("DeleteItem failed: %v", err)
+	}
+
+	entries, err := inv.ListAudit(inventory.AuditQuery{ItemID: 1001})
+	if err != nil {
+		t.Fatalf("ListAudit failed: %v", err)
+	}
+
+	want := []struct{ actor, op, action string }{
+		{"alice", "append_item", inventory.AuditInsert},
+		{"bob", "edit_item", inventory.AuditUpdate},
+		{inventory.AnonymousActor, "append_item", inventory.AuditReplace},
+		{"alice", "delete_item", inventory.AuditDelete},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Actor != w.actor || e.Operation != w.op ||
+			e.Action != w.action {
+			t.Errorf("entry %d: got %s/%s/%s, want %v",
+				i, e.Actor, e.Operation, e.Action, w)
+		}
+	}
+
+	found := false
+	for _, c := range entries[1].Changes() {
+		if c.String() == "location: Rack 1 → Rack 5" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("missing location change: %+v", entries[1].Changes())
+	}
+	if entries[2].Before == nil || entries[2].After == nil ||
+		entries[2].After.Description != "UPS 3KVA" {
+		t.Errorf("unexpected replace entry: %+v", entries[2])
+	}
+	if entries[3].After != nil || entries[3].Before.ID != 1001 {
+		t.Errorf("unexpected delete entry: %+v", entries[3])
+	}
+}
+
+func TestInventoryDB_Audit_UnitCostPrecision(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	third := 1.0 / 3
+	err := inv.AppendItem(inventory.Item{
+		ID: 1001, Description: "Resistor", UnitCost: third,
+	})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+	item, _ := inv.GetItemByID(1001)
+	item.UnitCost = 0.1 + 0.2
+	if err := inv.EditItem(item); err != nil {
+		t.Fatalf("EditItem failed: %v", err)
+	}
+	if err := inv.DeleteItem(1001); err != nil {
+		t.Fatalf("DeleteItem failed: %v", err)
+	}
+
+	entries, err := inv.ListAudit(inventory.AuditQuery{ItemID: 1001})
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("ListAudit failed: %+v %v", entries, err)
+	}
+	if got := entries[0].After.UnitCost; got != third {
+		t.Errorf("insert: unit cost %v, want %v", got, third)
+	}
+	if got := entries[1].Before.UnitCost; got != third {
+		t.Errorf("update before: unit cost %v, want %v", got, third)
+	}
+	if got := entries[1].After.UnitCost; got != 0.1+0.2 {
+		t.Errorf("update after: unit cost %v, want %v", got, 0.1+0.2)
+	}
+	if got := entries[2].Before.UnitCost; got != 0.1+0.2 {
+		t.Errorf("delete: unit cost %v, want %v", got, 0.1+0.2)
+	}
+}
+
+func TestInventoryDB_Audit_ImportAndReset(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	err := inv.WithActor("importer").ImportJSONFromString(`[
+        {"id": 1001, "description": "UPS"},
+        {"id": 1002, "description": "PDU"}
+    ]`)
+	if err != nil {
+		t.Fatalf("ImportJSONFromString failed: %v", err)
+	}
+	if err := inv.WithActor("admin").ResetSequence(); err != nil {
+		t.Fatalf("ResetSequence failed: %v", err)
+	}
+
+	entries, err := inv.ListAudit(inventory.AuditQuery{
+		Operation: "import_json_from_string",
+	})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 import entries: %v %v", entries, err)
+	}
+
+	entries, _ = inv.ListAudit(inventory.AuditQuery{Actor: "admin"})
+	if len(entries) != 1 ||
+		entries[0].Action != inventory.AuditResetSequence {
+		t.Errorf("unexpected reset entries: %+v", entries)
+	}
+}
+
+func TestInventoryDB_Audit_RollbackLeavesNoTrace(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	_ = inv.WithTransaction(func(tx inventory.Execer) error {
+		_ = inventory.AddItem(tx, inventory.Item{Description: "Temp"})
+		return context.Canceled
+	})
+
+	entries, _ := inv.ListAudit(inventory.AuditQuery{})
+	if len(entries) != 0 {
+		t.Errorf("expected empty audit log, got %+v", entries)
+	}
+}
+
+func TestInventoryDB_Audit_DirectChanges(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	// Changes outside InventoryDB are still logged, without actor
+	err := inventory.AddItem(inv.DB(), inventory.Item{Description: "Raw"})
+	if err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+
+	entries, _ := inv.ListAudit(inventory.AuditQuery{})
+	if len(entries) != 1 || entries[0].Actor != "" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	var buf bytes.Buffer
+	if err := inventory.WriteAuditReport(&buf, entries); err != nil {
+		t.Fatalf("WriteAuditReport failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), " - ") ||
+		!strings.Contains(buf.String(), "description:  → Raw") {
+		t.Errorf("unexpected report:\n%s", buf.String())
+	}
+}
+
+func TestInventoryDB_Audit_AppendOnly(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	if err := inv.AppendItem(inventory.Item{ID: 1001}); err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+	// A replace fills in its pending after row
+	err := inv.AppendItem(inventory.Item{ID: 1001, Description: "UPS"})
+	if err != nil {
+		t.Fatalf("AppendItem replace failed: %v", err)
+	}
+
+	for _, stmt := range []string{
+		`UPDATE audit_log SET actor = 'mallory'`,
+		`UPDATE audit_log SET after = NULL WHERE action = 'replace'`,
+		`DELETE FROM audit_log`,
+	} {
+		if _, err := inv.DB().Exec(stmt); err == nil ||
+			!strings.Contains(err.Error(), "append-only") {
+			t.Errorf("%s: expected append-only error, got %v", stmt, err)
+		}
+	}
+	entries, _ := inv.ListAudit(inventory.AuditQuery{})
+	if len(entries) != 2 || entries[1].After == nil ||
+		entries[1].After.Description != "UPS" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestActorFromContext(t *testing.T) {
+	if _, ok := inventory.ActorFromContext(context.Background()); ok {
+		t.Errorf("expected no actor in empty context")
+	}
+	ctx := inventory.NewActorContext(context.Background(), "carol")
+	if actor, ok := inventory.ActorFromContext(ctx); !ok || actor != "carol" {
+		t.Errorf("unexpected actor %q", actor)
+	}
+}
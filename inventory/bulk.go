@@ -0,0 +1,228 @@
+// bulk.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Bulk edits for Inventory CLI
+// Applies one partial update to every item selected by a filter,
+// with a preview of the changes.
+//
+
+package inventory
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BulkChange is the preview of a bulk update for one item.
+type BulkChange struct {
+	ItemID      int           `json:"item_id"`
+	Description string        `json:"description"`
+	Changes     []FieldChange `json:"changes"`
+}
+
+// PreviewBulkUpdate shows what BulkUpdate() would change,
+// without writing anything.
+//
+// Every selected item is listed, with no changes when the patch
+// leaves it as it is. The patched items are validated, so a
+// preview fails the same way the update would.
+//
+// Usage:
+//
+//	status := "Decommissioned"
+//	list, err := PreviewBulkUpdate(db, ItemFilter{Location: "Rack 4"},
+//	    ItemPatch{Status: &status})
+//
+// Works with both *sql.DB and *sql.Tx.
+func PreviewBulkUpdate(db Querier, f ItemFilter,
+	p ItemPatch) ([]BulkChange, error) {
+	items, err := queryFiltered(db, f)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []BulkChange
+	for _, item := range items {
+		after := p.Apply(item)
+		if err := after.check(); err != nil {
+			return nil, fmt.Errorf("item %d: %v", item.ID, err)
+		}
+		list = append(list, BulkChange{
+			ItemID:      item.ID,
+			Description: item.Description,
+			Changes:     DiffItems(item, after),
+		})
+	}
+	return list, nil
+}
+
+// BulkUpdate applies a partial update to every item selected by
+// the filter and appends the remark to each. It returns the IDs
+// of the updated items, sorted.
+//
+// Each item is updated with UpdateItem(), so the changed fields
+// are recorded in its remarks as well. Run it inside a
+// transaction (see InventoryDB.BulkUpdate) so either all items
+// change or none do.
+//
+// Usage:
+//
+//	from, to := "Old Store", "New Store"
+//	ids, err := BulkUpdate(tx, ItemFilter{Location: from},
+//	    ItemPatch{Location: &to}, "store moved")
+//
+// A blank filter is refused, so a mistyped filter cannot rewrite
+// the whole inventory; use BulkUpdateAll() to update every item.
+// The patch Remarks field is ignored, the remark argument is used
+// instead.
+//
+// Works with both *sql.DB and *sql.Tx.
+func BulkUpdate(exec ExecQuerier, f ItemFilter, p ItemPatch,
+	remark string) ([]int, error) {
+	return bulkUpdate(exec, f, p, remark, FormatChanges)
+}
+
+// BulkUpdateAll is BulkUpdate() for every item in the inventory.
+//
+// Usage:
+//
+//	status := "Audited"
+//	ids, err := BulkUpdateAll(tx, ItemPatch{Status: &status}, "audit 2025")
+//
+// Works with both *sql.DB and *sql.Tx.
+func BulkUpdateAll(exec ExecQuerier, p ItemPatch,
+	remark string) ([]int, error) {
+	return bulkPatch(exec, ItemFilter{}, p, remark, FormatChanges)
+}
+
+// bulkUpdate is BulkUpdate() with the DiffFormat to record the
+// changed fields with.
+func bulkUpdate(exec ExecQuerier, f ItemFilter, p ItemPatch,
+	remark string, format DiffFormat) ([]int, error) {
+	if f == (ItemFilter{}) {
+		return nil, fmt.Errorf(
+			"blank filter selects every item, use BulkUpdateAll")
+	}
+	return bulkPatch(exec, f, p, remark, format)
+}
+
+// bulkPatch applies the patch and remark to every item selected
+// by the filter, a blank filter selecting all of them.
+func bulkPatch(exec ExecQuerier, f ItemFilter, p ItemPatch,
+	remark string, format DiffFormat) ([]int, error) {
+	p.Remarks = nil
+	if p.IsEmpty() && strings.TrimSpace(remark) == "" {
+		return nil, fmt.Errorf("nothing to update")
+	}
+	if strings.TrimSpace(remark) != "" {
+		p.Remarks = &remark
+	}
+
+	items, err := queryFiltered(exec, f)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		if _, err := updateItem(exec, item.ID, p, format); err != nil {
+			return nil, fmt.Errorf("item %d: %v", item.ID, err)
+		}
+		ids = append(ids, item.ID)
+	}
+	return ids, nil
+}
+
+// PreviewBulkUpdate wraps PreviewBulkUpdate.
+//
+// Usage:
+//
+//	list, err := inv.PreviewBulkUpdate(filter, patch)
+func (inv *InventoryDB) PreviewBulkUpdate(f ItemFilter,
+	p ItemPatch) ([]BulkChange, error) {
+	return PreviewBulkUpdate(inv.db, f, p)
+}
+
+// BulkUpdate wraps BulkUpdate with automatic transaction.
+//
+// Requires RoleManager.
+//
+// Usage:
+//
+//	ids, err := inv.BulkUpdate(filter, patch, "rack 4 retired")
+func (inv *InventoryDB) BulkUpdate(f ItemFilter, p ItemPatch,
+	remark string) ([]int, error) {
+	var ids []int
+	err := inv.withTx("bulk_update", func(tx ExecQuerier) error {
+		var err error
+		ids, err = bulkUpdate(tx, f, p, remark, inv.diffFormat)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// BulkUpdateAll wraps BulkUpdateAll with automatic transaction.
+//
+// Requires RoleManager.
+//
+// Usage:
+//
+//	ids, err := inv.BulkUpdateAll(patch, "annual audit")
+func (inv *InventoryDB) BulkUpdateAll(p ItemPatch,
+	remark string) ([]int, error) {
+	var ids []int
+	err := inv.withTx("bulk_update", func(tx ExecQuerier) error {
+		var err error
+		ids, err = bulkPatch(tx, ItemFilter{}, p, remark, inv.diffFormat)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
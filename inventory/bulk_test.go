@@ -0,0 +1,141 @@
+// bulk_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for bulk edits
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestInventoryDB_BulkUpdate(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "Switch", Location: "Rack 4",
+			Status: "Installed"},
+		{ID: 1002, Description: "Router", Location: "Rack 4",
+			Status: "Decommissioned"},
+		{ID: 1003, Description: "Patch Panel", Location: "Rack 1",
+			Status: "Installed"},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	status := "Decommissioned"
+	f := inventory.ItemFilter{Location: "Rack 4"}
+	p := inventory.ItemPatch{Status: &status}
+
+	preview, err := inv.PreviewBulkUpdate(f, p)
+	if err != nil {
+		t.Fatalf("PreviewBulkUpdate failed: %v", err)
+	}
+	if len(preview) != 2 || len(preview[0].Changes) != 1 ||
+		preview[0].Changes[0].After != "Decommissioned" ||
+		len(preview[1].Changes) != 0 {
+		t.Errorf("unexpected preview: %+v", preview)
+	}
+	if got, _ := inv.GetItemByID(1001); got.Status != "Installed" {
+		t.Errorf("preview changed the item: %+v", got)
+	}
+
+	ids, err := inv.BulkUpdate(f, p, "rack 4 retired")
+	if err != nil {
+		t.Fatalf("BulkUpdate failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 1001 || ids[1] != 1002 {
+		t.Errorf("unexpected IDs: %v", ids)
+	}
+	for _, id := range ids {
+		got, _ := inv.GetItemByID(id)
+		if got.Status != "Decommissioned" ||
+			!strings.Contains(got.Remarks, "] rack 4 retired") {
+			t.Errorf("item not updated: %+v", got)
+		}
+	}
+	if got, _ := inv.GetItemByID(1003); got.Status != "Installed" {
+		t.Errorf("unselected item changed: %+v", got)
+	}
+
+	bad := "yesterday"
+	_, err = inv.BulkUpdateAll(inventory.ItemPatch{ExpiryDate: &bad}, "")
+	if err == nil {
+		t.Error("expected error for invalid date")
+	}
+	if got, _ := inv.GetItemByID(1001); got.ExpiryDate != "" {
+		t.Errorf("failed bulk update not rolled back: %+v", got)
+	}
+	if _, err := inv.BulkUpdate(f, inventory.ItemPatch{}, " "); err == nil {
+		t.Error("expected error for empty update")
+	}
+
+	retired := "Retired"
+	_, err = inv.BulkUpdate(inventory.ItemFilter{},
+		inventory.ItemPatch{Status: &retired}, "")
+	if err == nil {
+		t.Error("expected error for blank filter")
+	}
+	if got, _ := inv.GetItemByID(1003); got.Status != "Installed" {
+		t.Errorf("blank filter updated items: %+v", got)
+	}
+	ids, err = inv.BulkUpdateAll(inventory.ItemPatch{Status: &retired}, "")
+	if err != nil {
+		t.Fatalf("BulkUpdateAll failed: %v", err)
+	}
+	all, _ := inv.ListAll()
+	if len(ids) != len(all) {
+		t.Errorf("BulkUpdateAll updated %v, want %d items", ids, len(all))
+	}
+}
@@ -50,8 +50,18 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 )
 
+// csvHeader lists the CSV columns written by ExportCSV(),
+// in order. ImportCSV() maps columns by these header names.
+var csvHeader = []string{
+	"id", "description", "location", "status", "remarks", "parent_id",
+	"purchase_date", "warranty_until", "expiry_date",
+	"quantity", "reorder_level", "reorder_qty", "unit_cost",
+}
+
 // ExportCSV writes all inventory records to a CSV file.
 //
 // Usage:
@@ -60,12 +70,27 @@ import (
 //
 // The CSV will have the following columns:
 //
-//	id, description, location, status, remarks
+//	id, description, location, status, remarks, parent_id,
+//	purchase_date, warranty_until, expiry_date,
+//	quantity, reorder_level, reorder_qty, unit_cost
 //
 // Existing file will be overwritten.
 //
 // Returns error if file cannot be written or query fails.
 func ExportCSV(db *sql.DB, filename string) error {
+	return ExportCSVFiltered(db, filename, ItemFilter{})
+}
+
+// ExportCSVFiltered writes the inventory records selected
+// by the filter to a CSV file.
+//
+// Usage:
+//
+//	f := ItemFilter{WarrantyTo: "2025-12-31"}
+//	err := ExportCSVFiltered(db, "warranty.csv", f)
+//
+// The columns are the same as ExportCSV().
+func ExportCSVFiltered(db *sql.DB, filename string, f ItemFilter) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("create csv failed: %v", err)
@@ -75,30 +100,25 @@ func ExportCSV(db *sql.DB, filename string) error {
 	writer := csv.NewWriter(file)
 	defer writer.Flush()
 
-	header := []string{"id", "description", "location", "status", "remarks"}
-	if err := writer.Write(header); err != nil {
+	if err := writer.Write(csvHeader); err != nil {
 		return fmt.Errorf("write csv header failed: %v", err)
 	}
 
-	rows, err := db.Query(`SELECT id, description, location, status, remarks FROM inventory ORDER BY id`)
+	iter, err := NewFilteredItemIterator(db, f)
 	if err != nil {
 		return fmt.Errorf("query inventory failed: %v", err)
 	}
-	defer rows.Close()
+	defer iter.Close()
 
-	for rows.Next() {
-		var item Item
-		if err := rows.Scan(&item.ID, &item.Description, &item.Location, &item.Status, &item.Remarks); err != nil {
+	for {
+		item, ok, err := iter.Next()
+		if err != nil {
 			return fmt.Errorf("scan failed: %v", err)
 		}
-		record := []string{
-			fmt.Sprintf("%d", item.ID),
-			item.Description,
-			item.Location,
-			item.Status,
-			item.Remarks,
+		if !ok {
+			break
 		}
-		if err := writer.Write(record); err != nil {
+		if err := writer.Write(itemToCSV(item)); err != nil {
 			return fmt.Errorf("write csv row failed: %v", err)
 		}
 	}
@@ -106,6 +126,133 @@ func ExportCSV(db *sql.DB, filename string) error {
 	return nil
 }
 
+// writeCSVFile writes a header and rows to a CSV file.
+// Existing file will be overwritten.
+func writeCSVFile(filename string, header []string, rows [][]string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("create csv failed: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("write csv header failed: %v", err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("write csv failed: %v", err)
+	}
+	return nil
+}
+
+// itemToCSV returns the CSV record for an item,
+// with fields in csvHeader order.
+func itemToCSV(item Item) []string {
+	parent := ""
+	if item.ParentID != 0 {
+		parent = strconv.Itoa(item.ParentID)
+	}
+	return []string{
+		strconv.Itoa(item.ID),
+		item.Description,
+		item.Location,
+		item.Status,
+		item.Remarks,
+		parent,
+		item.PurchaseDate,
+		item.WarrantyUntil,
+		item.ExpiryDate,
+		strconv.Itoa(item.Quantity),
+		strconv.Itoa(item.ReorderLevel),
+		strconv.Itoa(item.ReorderQty),
+		strconv.FormatFloat(item.UnitCost, 'f', -1, 64),
+	}
+}
+
+// itemFromCSV builds an Item from a CSV record.
+//
+// The header maps column names to their position in the record.
+// Columns missing from the header are left at their zero value,
+// so files written by older versions still import.
+func itemFromCSV(header map[string]int, row []string) (Item, error) {
+	var item Item
+	field := func(name string) string {
+		if i, ok := header[name]; ok {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	text := func(name string) string {
+		if i, ok := header[name]; ok {
+			return row[i]
+		}
+		return ""
+	}
+	number := func(name string, dst *int) error {
+		v := field(name)
+		if v == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", name, v)
+		}
+		*dst = n
+		return nil
+	}
+
+	if err := number("id", &item.ID); err != nil {
+		return item, err
+	}
+	item.Description = text("description")
+	item.Location = text("location")
+	item.Status = text("status")
+	item.Remarks = text("remarks")
+	if err := number("parent_id", &item.ParentID); err != nil {
+		return item, err
+	}
+	item.PurchaseDate = field("purchase_date")
+	item.WarrantyUntil = field("warranty_until")
+	item.ExpiryDate = field("expiry_date")
+	for _, n := range []struct {
+		name string
+		dst  *int
+	}{
+		{"quantity", &item.Quantity},
+		{"reorder_level", &item.ReorderLevel},
+		{"reorder_qty", &item.ReorderQty},
+	} {
+		if err := number(n.name, n.dst); err != nil {
+			return item, err
+		}
+	}
+	if v := field("unit_cost"); v != "" {
+		cost, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return item, fmt.Errorf("invalid unit_cost %q", v)
+		}
+		item.UnitCost = cost
+	}
+	return item, item.check()
+}
+
+// csvHeaderIndex maps the header names of a CSV file
+// to their column positions.
+//
+// Names are matched case-insensitively. The "id" column is required.
+func csvHeaderIndex(row []string) (map[string]int, error) {
+	header := make(map[string]int, len(row))
+	for i, name := range row {
+		name = strings.ToLower(strings.TrimSpace(name))
+		name = strings.TrimPrefix(name, "\ufeff")
+		header[name] = i
+	}
+	if _, ok := header["id"]; !ok {
+		return nil, fmt.Errorf("csv header missing id column")
+	}
+	return header, nil
+}
+
 // ImportCSV reads inventory records from a CSV file and imports them.
 //
 // Existing records with matching IDs will be replaced.
@@ -114,47 +261,80 @@ func ExportCSV(db *sql.DB, filename string) error {
 //
 //	err := ImportCSV(db, "inventory.csv")
 //
-// CSV format must have columns:
+// The first row must be a header naming the columns:
 //
-//	id, description, location, status, remarks
+//	id, description, location, status, remarks, parent_id,
+//	purchase_date, warranty_until, expiry_date,
+//	quantity, reorder_level, reorder_qty, unit_cost
+//
+// Columns are matched by name, in any order. Only "id" is required,
+// so files exported by older versions (with fewer columns) still import.
 //
 // Each row is imported using AppendItem().
 //
 // Returns error on file error, parse error, or DB error.
 func ImportCSV(exec Execer, filename string) error {
+	items, err := ReadCSVItems(filename)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		if err := AppendItem(exec, item); err != nil {
+			return fmt.Errorf("import row %d failed: %v", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// ReadCSVItems parses a CSV file written by ExportCSV() into items,
+// without touching the database.
+//
+// Usage:
+//
+//	items, err := ReadCSVItems("inventory.csv")
+//
+// Useful for:
+//   - Validating a file before import
+//   - Comparing a file against the database
+//
+// Returns error on file error or parse error.
+func ReadCSVItems(filename string) ([]Item, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		return fmt.Errorf("open csv failed: %v", err)
+		return nil, fmt.Errorf("open csv failed: %v", err)
 	}
 	defer file.Close()
 
 	reader := csv.NewReader(file)
 	rows, err := reader.ReadAll()
 	if err != nil {
-		return fmt.Errorf("read csv failed: %v", err)
+		return nil, fmt.Errorf("read csv failed: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
 	}
 
-	for i, row := range rows {
-		if i == 0 {
-			continue
-		}
-		if len(row) != 5 {
-			return fmt.Errorf("csv row %d has wrong column count", i)
-		}
-
-		var item Item
-		fmt.Sscanf(row[0], "%d", &item.ID)
-		item.Description = row[1]
-		item.Location = row[2]
-		item.Status = row[3]
-		item.Remarks = row[4]
+	header, err := csvHeaderIndex(rows[0])
+	if err != nil {
+		return nil, err
+	}
 
-		if err := AppendItem(exec, item); err != nil {
-			return fmt.Errorf("import row %d failed: %v", i, err)
+	var items []Item
+	for i, row := range rows[1:] {
+		if len(row) != len(rows[0]) {
+			return nil, fmt.Errorf(
+				"csv row %d has wrong column count", i+1)
 		}
+		item, err := itemFromCSV(header, row)
+		if err != nil {
+			return nil, fmt.Errorf("csv row %d: %v", i+1, err)
+		}
+		items = append(items, item)
 	}
 
-	return nil
+	return items, nil
 }
 
 // ViewCSV prints the content of a CSV file to stdout.
@@ -199,6 +379,16 @@ func (inv *InventoryDB) ExportCSV(filename string) error {
 	return ExportCSV(inv.db, filename)
 }
 
+// ExportCSVFiltered writes filtered records to CSV using InventoryDB.
+//
+// Usage:
+//
+//	err := inv.ExportCSVFiltered("store.csv", filter)
+func (inv *InventoryDB) ExportCSVFiltered(
+	filename string, f ItemFilter) error {
+	return ExportCSVFiltered(inv.db, filename, f)
+}
+
 // ImportCSV imports inventory records from CSV using InventoryDB.
 //
 // Usage:
@@ -207,7 +397,7 @@ func (inv *InventoryDB) ExportCSV(filename string) error {
 //
 // The import runs inside a transaction.
 func (inv *InventoryDB) ImportCSV(filename string) error {
-	return inv.WithTransaction(func(tx Execer) error {
+	return inv.withTx("import_csv", func(tx ExecQuerier) error {
 		return ImportCSV(tx, filename)
 	})
 }
@@ -167,3 +167,69 @@ func TestExportCSV_BadPath(t *testing.T) {
 		t.Fatalf("expected error for bad path")
 	}
 }
+
+func TestImportCSV_HeaderMapping(t *testing.T) {
+	inv := setupCSVTestDB(t)
+	defer inv.Close()
+
+	// Older 5 column layout, columns in a different order
+	tmpfile := filepath.Join(t.TempDir(), "legacy.csv")
+	data := "status,id,description,location,remarks\n" +
+		"Active,1010,Switch,Rack 2,setup\n"
+	if err := os.WriteFile(tmpfile, []byte(data), 0644); err != nil {
+		t.Fatalf("write csv failed: %v", err)
+	}
+
+	if err := inv.ImportCSV(tmpfile); err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+
+	got, err := inv.GetItemByID(1010)
+	if err != nil {
+		t.Fatalf("GetItemByID failed: %v", err)
+	}
+	if got.Status != "Active" || got.Description != "Switch" {
+		t.Errorf("unexpected item after import: %+v", got)
+	}
+}
+
+func TestImportCSV_StockLevels(t *testing.T) {
+	inv := setupCSVTestDB(t)
+	defer inv.Close()
+
+	tmpfile := filepath.Join(t.TempDir(), "stock.csv")
+	data := "id,description,quantity,reorder_level,reorder_qty\n" +
+		"1011,Fuse 5A,4,10,20\n" +
+		"1012,Fuse 10A,,,\n"
+	if err := os.WriteFile(tmpfile, []byte(data), 0644); err != nil {
+		t.Fatalf("write csv failed: %v", err)
+	}
+
+	if err := inv.ImportCSV(tmpfile); err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+
+	got, _ := inv.GetItemByID(1011)
+	if got.Quantity != 4 || got.ReorderLevel != 10 || got.ReorderQty != 20 {
+		t.Errorf("unexpected stock levels: %+v", got)
+	}
+	got, _ = inv.GetItemByID(1012)
+	if got.Quantity != 0 || got.ReorderQty != 0 {
+		t.Errorf("unexpected blank stock levels: %+v", got)
+	}
+}
+
+func TestImportCSV_MissingIDColumn(t *testing.T) {
+	inv := setupCSVTestDB(t)
+	defer inv.Close()
+
+	tmpfile := filepath.Join(t.TempDir(), "noid.csv")
+	data := "description,location\nSwitch,Rack 2\n"
+	if err := os.WriteFile(tmpfile, []byte(data), 0644); err != nil {
+		t.Fatalf("write csv failed: %v", err)
+	}
+
+	if err := inv.ImportCSV(tmpfile); err == nil {
+		t.Errorf("expected error for missing id column")
+	}
+}
@@ -58,13 +58,19 @@ package inventory
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/boseji/bsg/gen"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// ErrItemNotFound is matched by the error GetItemByID returns
+// for an unknown ID, use errors.Is(err, ErrItemNotFound).
+var ErrItemNotFound = errors.New("not found")
+
 // OpenDB opens or creates the SQLite database file at dbFile path.
 //
 // It ensures that the 'inventory' table exists with the required fields:
@@ -73,6 +79,25 @@ import (
 // - location    TEXT
 // - status      TEXT
 // - remarks     TEXT
+// - parent_id   INTEGER (kit this item belongs to, NULL if none)
+// - purchase_date, warranty_until, expiry_date  TEXT (YYYY-MM-DD)
+// - quantity, reorder_level, reorder_qty  INTEGER (default 0)
+// - unit_cost   REAL (default 0)
+//
+// Databases created by older versions are upgraded in place
+// by adding any missing columns.
+//
+// Supporting tables (see schemaTables) are created as well:
+// - maintenance_plans  preventive maintenance schedules
+// - attachments        files stored with items
+// - audit_log          append-only log of every change (via triggers)
+// - users, api_tokens  access control
+// - stocktakes, stocktake_items  physical verification sessions
+// - stock_balances, stock_movements  per-location stock
+// - suppliers, purchases  procurement records
+// - changesets         one row per InventoryDB operation, for Revert()
+// - item_versions, sync_meta, sync_base  row versions for Sync()
+// - delta_sources      last delta applied from each source
 //
 // It also ensures that the autoincrement sequence is initialized:
 // - If the sequence is missing, sets it to IndexStart.
@@ -99,13 +124,76 @@ func OpenDB(dbFile string) *sql.DB {
         description TEXT,
         location TEXT,
         status TEXT,
-        remarks TEXT
+        remarks TEXT,
+        parent_id INTEGER,
+        purchase_date TEXT,
+        warranty_until TEXT,
+        expiry_date TEXT,
+        quantity INTEGER NOT NULL DEFAULT 0,
+        reorder_level INTEGER NOT NULL DEFAULT 0,
+        reorder_qty INTEGER NOT NULL DEFAULT 0,
+        unit_cost REAL NOT NULL DEFAULT 0
     );
     `)
 	if err != nil {
 		log.Fatalf("failed to create table: %v", err)
 	}
 
+	// Upgrade tables created by older versions
+	for _, col := range itemColumnUpgrades {
+		err = ensureColumn(db, "inventory", col.name, col.decl)
+		if err != nil {
+			log.Fatalf("failed to upgrade table: %v", err)
+		}
+	}
+
+	// Create the supporting tables if not present
+	for _, ddl := range schemaTables {
+		if _, err = db.Exec(ddl); err != nil {
+			log.Fatalf("failed to create table: %v", err)
+		}
+	}
+	for _, col := range tableColumnUpgrades {
+		err = ensureColumn(db, col.table, col.name, col.decl)
+		if err != nil {
+			log.Fatalf("failed to upgrade table: %v", err)
+		}
+	}
+	if _, err = db.Exec(auditLogChangesetIndex); err != nil {
+		log.Fatalf("failed to create index: %v", err)
+	}
+	if _, err = db.Exec(syncIDInit); err != nil {
+		log.Fatalf("failed to set sync id: %v", err)
+	}
+	if err = initSyncTables(db); err != nil {
+		log.Fatalf("failed to upgrade sync tables: %v", err)
+	}
+
+	// Give a copied database file its own sync ID
+	if err = checkOrigin(db); err != nil {
+		log.Fatalf("failed to check database origin: %v", err)
+	}
+
+	// Audit every change to the inventory table
+	if err = installAuditTriggers(db); err != nil {
+		log.Fatalf("failed to install audit triggers: %v", err)
+	}
+
+	// Reject kit cycles on every insert and parent change
+	if err = installKitTriggers(db); err != nil {
+		log.Fatalf("failed to install kit triggers: %v", err)
+	}
+
+	// Keep item quantities equal to their stock balances
+	if err = installStockTriggers(db); err != nil {
+		log.Fatalf("failed to install stock triggers: %v", err)
+	}
+
+	// Track row versions for Sync()
+	if err = installVersionTriggers(db); err != nil {
+		log.Fatalf("failed to install version triggers: %v", err)
+	}
+
 	// Initialize sequence only if not already set
 	_, err = db.Exec(`
     INSERT INTO sqlite_sequence (name, seq)
@@ -120,6 +208,141 @@ func OpenDB(dbFile string) *sql.DB {
 	return db
 }
 
+// schemaTables lists the DDL of the tables that support the
+// inventory table. OpenDB runs each statement in order,
+// so every statement must be idempotent.
+var schemaTables = []string{
+	maintenancePlansTable,
+	attachmentsTable,
+	auditLogTable,
+	auditLogIndex,
+	auditSessionTable,
+	usersTable,
+	apiTokensTable,
+	stocktakesTable,
+	stocktakeItemsTable,
+	stockBalancesTable,
+	stockMovementsTable,
+	suppliersTable,
+	purchasesTable,
+	changesetsTable,
+	itemVersionsTable,
+	syncMetaTable,
+	syncBaseTable,
+	deltaSourcesTable,
+}
+
+// itemColumnUpgrades lists the columns added to the inventory table
+// after its first release. OpenDB adds them to older databases.
+var itemColumnUpgrades = []struct {
+	name string
+	decl string
+}{
+	{"parent_id", "INTEGER"},
+	{"purchase_date", "TEXT"},
+	{"warranty_until", "TEXT"},
+	{"expiry_date", "TEXT"},
+	{"quantity", "INTEGER NOT NULL DEFAULT 0"},
+	{"reorder_level", "INTEGER NOT NULL DEFAULT 0"},
+	{"reorder_qty", "INTEGER NOT NULL DEFAULT 0"},
+	{"unit_cost", "REAL NOT NULL DEFAULT 0"},
+}
+
+// tableColumnUpgrades lists the columns added to the supporting
+// tables after their first release. OpenDB adds them to older
+// databases.
+var tableColumnUpgrades = []struct {
+	table string
+	name  string
+	decl  string
+}{
+	{"audit_log", "changeset_id", "INTEGER"},
+	{"audit_session", "changeset_id", "INTEGER"},
+	{"item_versions", "uid", "TEXT"},
+}
+
+// tableColumns returns the column names of a table, in order.
+func tableColumns(db *sql.DB, table string) ([]string, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, fmt.Errorf("table info failed: %v", err)
+	}
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var (
+			cid     int
+			name    string
+			ctype   string
+			notnull int
+			dflt    sql.NullString
+			pk      int
+		)
+		err := rows.Scan(&cid, &name, &ctype, &notnull, &dflt, &pk)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		cols = append(cols, name)
+	}
+	return cols, nil
+}
+
+// ensureColumn adds the column to the table if it is not present.
+//
+// Usage:
+//
+//	err := ensureColumn(db, "inventory", "parent_id", "INTEGER")
+//
+// Notes:
+// - Uses PRAGMA table_info to inspect the existing columns
+// - Safe to call multiple times
+func ensureColumn(db *sql.DB, table, column, decl string) error {
+	cols, err := tableColumns(db, table)
+	if err != nil {
+		return err
+	}
+	for _, name := range cols {
+		if name == column {
+			return nil
+		}
+	}
+
+	_, err = db.Exec(fmt.Sprintf(
+		"ALTER TABLE %s ADD COLUMN %s %s", table, column, decl))
+	if err != nil {
+		return fmt.Errorf("add column %s failed: %v", column, err)
+	}
+	return nil
+}
+
+// itemFields is the column list used by every query that returns
+// an Item. The order must match scanItem().
+//
+// Nullable columns added in later versions are coalesced
+// to their zero values.
+const itemFields = `id, description, location, status, remarks,
+        COALESCE(parent_id, 0), COALESCE(purchase_date, ''),
+        COALESCE(warranty_until, ''), COALESCE(expiry_date, ''),
+        quantity, reorder_level, reorder_qty, unit_cost`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanItem reads one Item from a row selected with itemFields.
+func scanItem(row rowScanner) (Item, error) {
+	var item Item
+	err := row.Scan(
+		&item.ID, &item.Description, &item.Location,
+		&item.Status, &item.Remarks, &item.ParentID,
+		&item.PurchaseDate, &item.WarrantyUntil, &item.ExpiryDate,
+		&item.Quantity, &item.ReorderLevel, &item.ReorderQty,
+		&item.UnitCost)
+	return item, err
+}
+
 // AppendItem inserts or replaces an item in the inventory table,
 // using the provided ID. If an item with the same ID already exists,
 // it will be replaced with the new values.
@@ -161,14 +384,25 @@ func OpenDB(dbFile string) *sql.DB {
 // - If ID is not set, use AddItem() instead
 // - Works with both *sql.DB and *sql.Tx.
 func AppendItem(exec Execer, item Item) error {
+	if err := item.check(); err != nil {
+		return err
+	}
+
 	_, err := exec.Exec(`
         INSERT OR REPLACE INTO inventory
-        (id, description, location, status, remarks)
-        VALUES (?, ?, ?, ?, ?)`,
+        (id, description, location, status, remarks, parent_id,
+         purchase_date, warranty_until, expiry_date,
+         quantity, reorder_level, reorder_qty, unit_cost)
+        VALUES (?, ?, ?, ?, ?, NULLIF(?, 0),
+         NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?)`,
 		item.ID, item.Description, item.Location,
-		item.Status, item.FormatRemarks())
+		item.Status, item.FormatRemarks(), item.ParentID,
+		item.PurchaseDate, item.WarrantyUntil, item.ExpiryDate,
+		item.Quantity, item.ReorderLevel, item.ReorderQty,
+		item.UnitCost)
 	if err != nil {
-		return fmt.Errorf("insert or replace failed: %v", err)
+		return fmt.Errorf("insert or replace failed: %w",
+			triggerError(err))
 	}
 	return nil
 }
@@ -251,18 +485,105 @@ func AppendRemarksEntry(exec Execer, id int, message string) error {
 // - Remarks will always follow consistent format
 // - Works with both *sql.DB and *sql.Tx.
 func AddItem(exec Execer, item Item) error {
+	if err := item.check(); err != nil {
+		return err
+	}
+
 	_, err := exec.Exec(`
         INSERT INTO inventory
-        (description, location, status, remarks)
-        VALUES (?, ?, ?, ?)`,
+        (description, location, status, remarks, parent_id,
+         purchase_date, warranty_until, expiry_date,
+         quantity, reorder_level, reorder_qty, unit_cost)
+        VALUES (?, ?, ?, ?, NULLIF(?, 0),
+         NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?)`,
 		item.Description, item.Location,
-		item.Status, item.FormatRemarks())
+		item.Status, item.FormatRemarks(), item.ParentID,
+		item.PurchaseDate, item.WarrantyUntil, item.ExpiryDate,
+		item.Quantity, item.ReorderLevel, item.ReorderQty,
+		item.UnitCost)
 	if err != nil {
-		return fmt.Errorf("insert failed: %v", err)
+		return fmt.Errorf("insert failed: %w", triggerError(err))
 	}
 	return nil
 }
 
+// triggerError returns the sentinel error for a write aborted by
+// one of the inventory triggers (ErrKitCycle, ErrStockMismatch),
+// so callers can match it with errors.Is; any other error is
+// returned unchanged.
+func triggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, sentinel := range []error{ErrKitCycle, ErrStockMismatch} {
+		if strings.Contains(err.Error(), sentinel.Error()) {
+			return sentinel
+		}
+	}
+	return err
+}
+
+// DiffFormat renders the field changes made by an edit as a
+// remarks entry, see FormatChanges(). An empty result records
+// nothing, and a nil DiffFormat records no changes.
+type DiffFormat func(changes []FieldChange) string
+
+// FormatChanges joins field changes into one line:
+//
+//	location: Rack 1 → Rack 5; status: Spare → Installed
+//
+// It is the default DiffFormat of InventoryDB.
+func FormatChanges(changes []FieldChange) string {
+	parts := make([]string, 0, len(changes))
+	for _, c := range changes {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// diffEntry renders the changes from stored to item as a
+// timestamped remarks entry, or "" if there is nothing to record.
+func diffEntry(format DiffFormat, stored, item Item) string {
+	if format == nil {
+		return ""
+	}
+	diff := Item{Remarks: format(DiffItems(stored, item))}
+	if strings.TrimSpace(diff.Remarks) == "" {
+		return ""
+	}
+	return diff.FormatRemarks()
+}
+
+// editChanges returns the item as EditItem() would store it.
+func editChanges(stored, item Item) Item {
+	after := stored
+	after.Description = item.Description
+	after.Location = item.Location
+	after.Status = item.Status
+	if item.PurchaseDate != "" {
+		after.PurchaseDate = item.PurchaseDate
+	}
+	if item.WarrantyUntil != "" {
+		after.WarrantyUntil = item.WarrantyUntil
+	}
+	if item.ExpiryDate != "" {
+		after.ExpiryDate = item.ExpiryDate
+	}
+	if item.Quantity != 0 {
+		after.Quantity = item.Quantity
+	}
+	if item.ReorderLevel != 0 {
+		after.ReorderLevel = item.ReorderLevel
+	}
+	if item.ReorderQty != 0 {
+		after.ReorderQty = item.ReorderQty
+	}
+	if item.UnitCost != 0 {
+		after.UnitCost = item.UnitCost
+	}
+	return after
+}
+
 // EditItem updates the item's fields (description, location, status)
 // and appends the new remarks text to the existing remarks field.
 //
@@ -296,25 +617,91 @@ func AddItem(exec Execer, item Item) error {
 // - If item ID does not exist, no rows are updated
 // - If used inside transaction (tx), pass tx as exec
 // - To append a single new log entry, use AppendRemarksEntry()
+// - To also record the changed fields, use EditItemDiff()
 // - To display remarks nicely, use item.FormatRemarks()
+// - Blank dates (purchase, warranty, expiry) keep the stored value
+// - Zero quantity, reorder settings and cost keep the stored value
+// - Use UpdateItem() to set them to zero
 // - Works with both *sql.DB and *sql.Tx.
 func EditItem(exec Execer, item Item) error {
+	if err := item.check(); err != nil {
+		return err
+	}
+	return editItem(exec, item, item.FormatRemarks())
+}
+
+// editItem runs the UPDATE of EditItem() with the given remarks
+// entry.
+func editItem(exec Execer, item Item, entry string) error {
 	_, err := exec.Exec(`
         UPDATE inventory
         SET description = ?, location = ?,
             status = ?,
-            remarks = COALESCE(remarks, '') || char(10) || ?
+            remarks = COALESCE(remarks, '') || char(10) || ?,
+            purchase_date = COALESCE(NULLIF(?, ''), purchase_date),
+            warranty_until = COALESCE(NULLIF(?, ''), warranty_until),
+            expiry_date = COALESCE(NULLIF(?, ''), expiry_date),
+            quantity = COALESCE(NULLIF(?, 0), quantity),
+            reorder_level = COALESCE(NULLIF(?, 0), reorder_level),
+            reorder_qty = COALESCE(NULLIF(?, 0), reorder_qty),
+            unit_cost = COALESCE(NULLIF(?, 0), unit_cost)
         WHERE id = ?`,
 		item.Description, item.Location,
 		item.Status,
-		item.FormatRemarks(),
+		entry,
+		item.PurchaseDate, item.WarrantyUntil, item.ExpiryDate,
+		item.Quantity, item.ReorderLevel, item.ReorderQty,
+		item.UnitCost,
 		item.ID)
 	if err != nil {
-		return fmt.Errorf("update failed: %v", err)
+		return fmt.Errorf("update failed: %w", triggerError(err))
 	}
 	return nil
 }
 
+// EditItemDiff is EditItem() that also records the fields it
+// changes, rendered by format, as one more remarks entry:
+//
+//	(previous remarks)
+//	[2025-06-20 16:22] maintenance check completed
+//	[2025-06-20 16:22] location: Warehouse 1 → Warehouse 3
+//
+// The stored row is read first to find the changed fields. When
+// item.Remarks is blank only the changes are appended; a nil
+// format records none, like EditItem().
+//
+// Usage:
+//
+//	err := EditItemDiff(tx, item, FormatChanges)
+//
+// InventoryDB.EditItem uses the format set with
+// WithDiffFormat(), FormatChanges by default.
+//
+// Works with both *sql.DB and *sql.Tx.
+func EditItemDiff(exec ExecQuerier, item Item, format DiffFormat) error {
+	if err := item.check(); err != nil {
+		return err
+	}
+	stored, err := GetItemByID(exec, item.ID)
+	if errors.Is(err, ErrItemNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	entry := item.FormatRemarks()
+	if diff := diffEntry(format, stored,
+		editChanges(stored, item)); diff != "" {
+		if strings.TrimSpace(item.Remarks) == "" {
+			entry = diff
+		} else {
+			entry += "\n" + diff
+		}
+	}
+	return editItem(exec, item, entry)
+}
+
 // DeleteItem removes a record from the inventory table by ID.
 //
 // If the specified ID does not exist, the operation is a no-op
@@ -340,6 +727,9 @@ func EditItem(exec Execer, item Item) error {
 // - This is a destructive operation (cannot be undone)
 // - Should typically be logged via remarks before use
 // - Use AppendRemarksEntry() if you want an audit trail before delete
+// - Components of a deleted kit are detached (parent_id set to NULL)
+// - Maintenance plans and attachments of the item are deleted
+// - Stock balances are deleted and logged as MoveDelete movements
 // - Works with both *sql.DB and *sql.Tx.
 func DeleteItem(exec Execer, id int) error {
 	_, err := exec.Exec(`
@@ -348,6 +738,33 @@ func DeleteItem(exec Execer, id int) error {
 	if err != nil {
 		return fmt.Errorf("delete failed: %v", err)
 	}
+
+	_, err = exec.Exec(`
+        DELETE FROM maintenance_plans
+        WHERE item_id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete maintenance plans failed: %v", err)
+	}
+
+	_, err = exec.Exec(`
+        DELETE FROM attachments
+        WHERE item_id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete attachments failed: %v", err)
+	}
+
+	if err := removeBalances(exec, id); err != nil {
+		return err
+	}
+
+	// Components of a deleted kit become top-level items
+	_, err = exec.Exec(`
+        UPDATE inventory
+        SET parent_id = NULL
+        WHERE parent_id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("detach components failed: %v", err)
+	}
 	return nil
 }
 
@@ -378,6 +795,7 @@ func DeleteItem(exec Execer, id int) error {
 // - Does not delete records (use DeleteItem or manual purge first)
 // - Safe to call multiple times
 // - Has no effect if records still exist with higher IDs
+// - Recorded in the audit log
 // - Works with both *sql.DB and *sql.Tx.
 func ResetSequence(exec Execer) error {
 	_, err := exec.Exec(`
@@ -387,7 +805,7 @@ func ResetSequence(exec Execer) error {
 	if err != nil {
 		return fmt.Errorf("reset sequence failed: %v", err)
 	}
-	return nil
+	return logAudit(exec, AuditResetSequence)
 }
 
 // ListAll returns all items in the inventory table, sorted by ID.
@@ -427,7 +845,7 @@ func ResetSequence(exec Execer) error {
 //     use ListItemsPaged() or ItemIterator().
 func ListAll(db *sql.DB) ([]Item, error) {
 	rows, err := db.Query(`
-        SELECT id, description, location, status, remarks
+        SELECT ` + itemFields + `
         FROM inventory ORDER BY id`)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %v", err)
@@ -436,9 +854,7 @@ func ListAll(db *sql.DB) ([]Item, error) {
 
 	var items []Item
 	for rows.Next() {
-		var item Item
-		err := rows.Scan(&item.ID, &item.Description,
-			&item.Location, &item.Status, &item.Remarks)
+		item, err := scanItem(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scan failed: %v", err)
 		}
@@ -479,17 +895,14 @@ func ListAll(db *sql.DB) ([]Item, error) {
 //   - This is a read-only query (no transaction needed)
 //   - The remarks field is returned as raw string
 //     (use item.FormatRemarks() for formatted display)
-func GetItemByID(db *sql.DB, id int) (Item, error) {
-	var item Item
-	row := db.QueryRow(`
-        SELECT id, description, location, status, remarks
-        FROM inventory WHERE id = ?`, id)
-	err := row.Scan(
-		&item.ID, &item.Description,
-		&item.Location, &item.Status, &item.Remarks)
+//   - Works with both *sql.DB and *sql.Tx.
+func GetItemByID(db Querier, id int) (Item, error) {
+	item, err := scanItem(db.QueryRow(`
+        SELECT `+itemFields+`
+        FROM inventory WHERE id = ?`, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return item, fmt.Errorf("item %d not found", id)
+			return item, fmt.Errorf("item %d %w", id, ErrItemNotFound)
 		}
 		return item, fmt.Errorf("query failed: %v", err)
 	}
@@ -531,7 +944,7 @@ func ListItemsPaged(
 	db *sql.DB, afterID int, limit int) ([]Item, error) {
 
 	rows, err := db.Query(`
-        SELECT id, description, location, status, remarks
+        SELECT `+itemFields+`
         FROM inventory
         WHERE id > ?
         ORDER BY id
@@ -543,10 +956,7 @@ func ListItemsPaged(
 
 	var items []Item
 	for rows.Next() {
-		var item Item
-		err := rows.Scan(
-			&item.ID, &item.Description, &item.Location,
-			&item.Status, &item.Remarks)
+		item, err := scanItem(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scan failed: %v", err)
 		}
@@ -47,6 +47,8 @@ package inventory_test
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/boseji/bvl/inventory"
@@ -181,6 +183,82 @@ func TestEditItem_NotFound(t *testing.T) {
 	}
 }
 
+func TestEditItemDiff(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	item := inventory.Item{
+		ID: 1001, Description: "Switch", Location: "Rack 1",
+		Status: "Installed",
+	}
+	if err := inventory.AppendItem(db, item); err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+
+	item.Location = "Rack 5"
+	err := inventory.EditItemDiff(db, item, inventory.FormatChanges)
+	if err != nil {
+		t.Fatalf("EditItemDiff failed: %v", err)
+	}
+	got, _ := inventory.GetItemByID(db, 1001)
+	if !strings.HasSuffix(got.Remarks, "] location: Rack 1 → Rack 5") {
+		t.Errorf("change not recorded: %q", got.Remarks)
+	}
+
+	count := func(c []inventory.FieldChange) string {
+		return fmt.Sprintf("%d field(s) changed", len(c))
+	}
+	item.Status, item.Remarks = "Spare", "pulled out"
+	if err := inventory.EditItemDiff(db, item, count); err != nil {
+		t.Fatalf("EditItemDiff failed: %v", err)
+	}
+	got, _ = inventory.GetItemByID(db, 1001)
+	if !strings.Contains(got.Remarks, "] pulled out\n[") ||
+		!strings.HasSuffix(got.Remarks, "] 1 field(s) changed") {
+		t.Errorf("unexpected remarks: %q", got.Remarks)
+	}
+
+	// EditItem itself records no changes
+	item.Location, item.Remarks = "Store", "moved"
+	if err := inventory.EditItem(db, item); err != nil {
+		t.Fatalf("EditItem failed: %v", err)
+	}
+	got, _ = inventory.GetItemByID(db, 1001)
+	if strings.Contains(got.Remarks, "Rack 5 → Store") ||
+		!strings.HasSuffix(got.Remarks, "] moved") {
+		t.Errorf("unexpected remarks: %q", got.Remarks)
+	}
+}
+
+func TestEditItem_KeepsUnsetStock(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	err := inventory.AppendItem(db, inventory.Item{
+		ID: 1001, Description: "Fuse 5A", Quantity: 40,
+		ReorderLevel: 10, ReorderQty: 50, UnitCost: 12.5,
+	})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+
+	// An Item built without the stock fields keeps them
+	err = inventory.EditItem(db, inventory.Item{
+		ID: 1001, Description: "Fuse 5A", Location: "Drawer 2",
+	})
+	if err != nil {
+		t.Fatalf("EditItem failed: %v", err)
+	}
+	got, _ := inventory.GetItemByID(db, 1001)
+	if got.Quantity != 40 || got.ReorderLevel != 10 ||
+		got.ReorderQty != 50 || got.UnitCost != 12.5 {
+		t.Errorf("stock fields changed: %+v", got)
+	}
+	if strings.Contains(got.Remarks, "quantity") {
+		t.Errorf("unchanged fields recorded: %q", got.Remarks)
+	}
+}
+
 func TestAppendRemarksEntry(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
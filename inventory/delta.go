@@ -0,0 +1,433 @@
+// delta.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Delta export and import for Inventory CLI
+// Ships only the items changed since a sequence number or time,
+// in a versioned envelope, and applies them idempotently.
+//
+
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/boseji/bsg/gen"
+)
+
+// DeltaFormat and DeltaVersion identify the delta envelope.
+// ReadDelta() refuses other formats and newer versions.
+const (
+	DeltaFormat  = "bvl-delta"
+	DeltaVersion = 1
+)
+
+// Delta change actions.
+const (
+	DeltaUpsert = "upsert"
+	DeltaDelete = "delete"
+)
+
+// deltaSourcesTable is the DDL of the delta_sources table.
+// It records, per exporting database, the last applied sequence.
+const deltaSourcesTable = `
+    CREATE TABLE IF NOT EXISTS delta_sources (
+        source TEXT PRIMARY KEY,
+        last_seq INTEGER NOT NULL,
+        applied_at TEXT
+    );`
+
+// DeltaChange is the latest state of one changed item.
+//
+// Fields:
+//
+//	Seq       - audit log sequence number of the last change
+//	Time      - when, in AuditTimeLayout
+//	Action    - DeltaUpsert or DeltaDelete
+//	ItemID    - ID of the item in the exporting database
+//	UID       - uid of the item, see itemVersionsTable
+//	ParentUID - uid of its parent, if any
+//	Item      - the item row for DeltaUpsert, nil for DeltaDelete
+type DeltaChange struct {
+	Seq       int    `json:"seq"`
+	Time      string `json:"time"`
+	Action    string `json:"action"`
+	ItemID    int    `json:"item_id"`
+	UID       string `json:"uid"`
+	ParentUID string `json:"parent_uid,omitempty"`
+	Item      *Item  `json:"item,omitempty"`
+}
+
+// Delta is the envelope of a delta export:
+//
+//	{
+//	  "format": "bvl-delta",
+//	  "version": 1,
+//	  "source": "3f9c0e...",
+//	  "from_seq": 120,
+//	  "to_seq": 184,
+//	  "created": "2025-06-21 15:00",
+//	  "changes": [ ... ]
+//	}
+//
+// Source is the sync ID of the exporting database. The next export
+// for the same receiver should start after ToSeq.
+type Delta struct {
+	Format  string        `json:"format"`
+	Version int           `json:"version"`
+	Source  string        `json:"source"`
+	FromSeq int           `json:"from_seq"`
+	ToSeq   int           `json:"to_seq"`
+	Since   string        `json:"since,omitempty"`
+	Created string        `json:"created"`
+	Changes []DeltaChange `json:"changes"`
+}
+
+// DeltaResult counts what ImportDelta() did, and lists the new
+// items that were given another ID than in the source.
+type DeltaResult struct {
+	Upserted   int            `json:"upserted"`
+	Deleted    int            `json:"deleted"`
+	Skipped    int            `json:"skipped"`
+	Renumbered []SyncRenumber `json:"renumbered,omitempty"`
+}
+
+// ExportDelta returns the items changed after the audit log
+// sequence afterSeq and, if since is set, at or after that time
+// (prefix of AuditTimeLayout).
+//
+// Each changed item appears once, with its latest state: an
+// upsert with the current row from the inventory table, or a
+// delete if it no longer exists.
+//
+// Usage:
+//
+//	d, err := ExportDelta(db, 120, "")
+//	err = WriteDelta(file, d)
+//
+// Works with both *sql.DB and *sql.Tx.
+func ExportDelta(db Querier, afterSeq int, since string) (Delta, error) {
+	d := Delta{
+		Format: DeltaFormat, Version: DeltaVersion,
+		FromSeq: afterSeq, ToSeq: afterSeq, Since: since,
+		Created: gen.BST().Format("2006-01-02 15:04"),
+		Changes: []DeltaChange{},
+	}
+	var err error
+	if d.Source, err = syncID(db); err != nil {
+		return d, err
+	}
+	err = db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM audit_log`).
+		Scan(&d.ToSeq)
+	if err != nil {
+		return d, fmt.Errorf("audit query failed: %v", err)
+	}
+	if d.ToSeq < afterSeq {
+		d.ToSeq = afterSeq
+	}
+
+	entries, err := ListAudit(db, AuditQuery{
+		AfterID: afterSeq, Since: since,
+	})
+	if err != nil {
+		return d, err
+	}
+	versions, err := loadVersions(db)
+	if err != nil {
+		return d, err
+	}
+	uids := make(map[int]string, len(versions))
+	for uid, v := range versions {
+		uids[v.id] = uid
+	}
+	latest := make(map[int]AuditEntry)
+	var order []int
+	for _, e := range entries {
+		if e.ItemID == 0 || e.ID > d.ToSeq {
+			continue
+		}
+		if _, ok := latest[e.ItemID]; !ok {
+			order = append(order, e.ItemID)
+		}
+		latest[e.ItemID] = e
+	}
+
+	for _, id := range order {
+		e := latest[id]
+		c := DeltaChange{
+			Seq: e.ID, Time: e.Time, Action: DeltaDelete,
+			ItemID: id, UID: uids[id],
+		}
+		if e.After != nil {
+			item, err := GetItemByID(db, id)
+			if err != nil && !errors.Is(err, ErrItemNotFound) {
+				return d, err
+			}
+			if err == nil {
+				c.Action, c.Item = DeltaUpsert, &item
+				if item.ParentID != 0 {
+					c.ParentUID = uids[item.ParentID]
+				}
+			}
+		}
+		d.Changes = append(d.Changes, c)
+	}
+	return d, nil
+}
+
+// WriteDelta writes a delta as indented JSON.
+func WriteDelta(w io.Writer, d Delta) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(d); err != nil {
+		return fmt.Errorf("write delta failed: %v", err)
+	}
+	return nil
+}
+
+// ReadDelta reads a delta written by WriteDelta() and checks
+// its format and version.
+func ReadDelta(r io.Reader) (Delta, error) {
+	var d Delta
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return d, fmt.Errorf("read delta failed: %v", err)
+	}
+	if d.Format != DeltaFormat {
+		return d, fmt.Errorf("not a delta file: format %q", d.Format)
+	}
+	if d.Version < 1 || d.Version > DeltaVersion {
+		return d, fmt.Errorf("unsupported delta version %d", d.Version)
+	}
+	if d.Source == "" {
+		return d, fmt.Errorf("delta has no source")
+	}
+	return d, nil
+}
+
+// LastDeltaSeq returns the last sequence applied from a source,
+// 0 if none. Pass it as afterSeq to the source's next export.
+//
+// Works with both *sql.DB and *sql.Tx.
+func LastDeltaSeq(db Querier, source string) (int, error) {
+	var seq int
+	err := db.QueryRow(`
+        SELECT COALESCE(MAX(last_seq), 0) FROM delta_sources
+        WHERE source = ?`, source).Scan(&seq)
+	if err != nil {
+		return 0, fmt.Errorf("delta source query failed: %v", err)
+	}
+	return seq, nil
+}
+
+// ImportDelta applies a delta and records its ToSeq as the last
+// applied sequence of its source.
+//
+// Changes at or below the last applied sequence are skipped, so
+// applying the same delta twice, or overlapping deltas, is safe.
+//
+// Items are matched by uid, not by ID, so a local item that only
+// shares its ID with an item of the source is never touched:
+//   - an upsert of a known item writes the row as exported, with
+//     its remarks, under the local ID
+//   - an upsert of a new item keeps its ID when no local row ever
+//     had it, else gets the next free ID (DeltaResult.Renumbered)
+//   - a delete uses DeleteItem() on the local item with that uid,
+//     and is skipped when there is none
+//
+// Usage:
+//
+//	d, err := ReadDelta(file)
+//	res, err := ImportDelta(tx, d)
+//
+// Works with both *sql.DB and *sql.Tx.
+func ImportDelta(exec ExecQuerier, d Delta) (DeltaResult, error) {
+	var res DeltaResult
+	own, err := syncID(exec)
+	if err != nil {
+		return res, err
+	}
+	if d.Source == own {
+		return res, fmt.Errorf("delta comes from this database")
+	}
+	last, err := LastDeltaSeq(exec, d.Source)
+	if err != nil {
+		return res, err
+	}
+	ids, err := allocateDelta(exec, d, last, &res)
+	if err != nil {
+		return res, err
+	}
+
+	for _, c := range d.Changes {
+		id, known := ids[c.UID]
+		switch {
+		case c.Seq <= last:
+			res.Skipped++
+			continue
+		case c.Action == DeltaUpsert:
+			item := *c.Item
+			item.ID, item.ParentID = id, ids[c.ParentUID]
+			err = restoreItem(exec, item)
+			if err == nil {
+				_, err = exec.Exec(`
+                    UPDATE item_versions SET uid = ?
+                    WHERE item_id = ?`, c.UID, id)
+			}
+			res.Upserted++
+		case !known:
+			res.Skipped++
+			continue
+		default:
+			err = DeleteItem(exec, id)
+			res.Deleted++
+		}
+		if err != nil {
+			return res, fmt.Errorf("change %d: %v", c.Seq, err)
+		}
+	}
+
+	if d.ToSeq > last {
+		_, err = exec.Exec(`
+            INSERT OR REPLACE INTO delta_sources
+            (source, last_seq, applied_at) VALUES (?, ?, ?)`,
+			d.Source, d.ToSeq, gen.BST().Format(AuditTimeLayout))
+		if err != nil {
+			return res, fmt.Errorf("record delta source failed: %v", err)
+		}
+	}
+	return res, nil
+}
+
+// allocateDelta checks the changes to apply and maps the uid of
+// every item they name to its local ID, giving new items an ID
+// as Sync() does (see idAllocator).
+func allocateDelta(db Querier, d Delta, last int,
+	res *DeltaResult) (map[string]int, error) {
+	versions, err := loadVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]int, len(versions))
+	for uid, v := range versions {
+		ids[uid] = v.id
+	}
+	alloc, err := newIDAllocator(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var renumber []DeltaChange
+	for _, c := range d.Changes {
+		if c.Seq <= last {
+			continue
+		}
+		switch {
+		case c.UID == "":
+			return nil, fmt.Errorf("change %d: item has no uid", c.Seq)
+		case c.Action == DeltaDelete:
+			continue
+		case c.Action != DeltaUpsert || c.Item == nil:
+			return nil, fmt.Errorf("change %d: invalid action %q",
+				c.Seq, c.Action)
+		case c.Item.ID != c.ItemID:
+			return nil, fmt.Errorf("change %d: item id mismatch", c.Seq)
+		}
+		if _, ok := ids[c.UID]; ok {
+			continue
+		}
+		if !alloc.claim(c.ItemID) {
+			renumber = append(renumber, c)
+			continue
+		}
+		ids[c.UID] = c.ItemID
+	}
+	for _, c := range renumber {
+		ids[c.UID] = alloc.nextID()
+		res.Renumbered = append(res.Renumbered, SyncRenumber{
+			Side: "local", From: c.ItemID, To: ids[c.UID],
+		})
+	}
+	return ids, nil
+}
+
+// ExportDelta wraps ExportDelta.
+//
+// Usage:
+//
+//	d, err := inv.ExportDelta(lastSeq, "")
+func (inv *InventoryDB) ExportDelta(afterSeq int,
+	since string) (Delta, error) {
+	return ExportDelta(inv.db, afterSeq, since)
+}
+
+// ImportDelta wraps ImportDelta with automatic transaction.
+//
+// Usage:
+//
+//	res, err := inv.ImportDelta(d)
+func (inv *InventoryDB) ImportDelta(d Delta) (DeltaResult, error) {
+	var res DeltaResult
+	err := inv.withTx("import_delta", func(tx ExecQuerier) error {
+		var err error
+		res, err = ImportDelta(tx, d)
+		return err
+	})
+	if err != nil {
+		return DeltaResult{}, err
+	}
+	return res, nil
+}
+
+// LastDeltaSeq wraps LastDeltaSeq.
+//
+// Usage:
+//
+//	seq, err := inv.LastDeltaSeq(source)
+func (inv *InventoryDB) LastDeltaSeq(source string) (int, error) {
+	return LastDeltaSeq(inv.db, source)
+}
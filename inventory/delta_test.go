@@ -0,0 +1,222 @@
+// delta_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for delta export and import
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestInventoryDB_Delta(t *testing.T) {
+	head := setupInventoryDB(t)
+	defer head.Close()
+	branch := setupInventoryDB(t)
+	defer branch.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "Scope", Location: "Lab"},
+		{ID: 1002, Description: "Probe", Location: "Lab"},
+	} {
+		if err := head.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	ship := func(afterSeq int) inventory.Delta {
+		d, err := head.ExportDelta(afterSeq, "")
+		if err != nil {
+			t.Fatalf("ExportDelta failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := inventory.WriteDelta(&buf, d); err != nil {
+			t.Fatalf("WriteDelta failed: %v", err)
+		}
+		d, err = inventory.ReadDelta(&buf)
+		if err != nil {
+			t.Fatalf("ReadDelta failed: %v", err)
+		}
+		return d
+	}
+
+	d := ship(0)
+	if len(d.Changes) != 2 || d.Changes[0].Action != inventory.DeltaUpsert {
+		t.Fatalf("unexpected delta: %+v", d)
+	}
+	res, err := branch.ImportDelta(d)
+	if err != nil || res.Upserted != 2 {
+		t.Fatalf("ImportDelta failed: %+v %v", res, err)
+	}
+
+	// Importing again changes nothing
+	res, err = branch.ImportDelta(d)
+	if err != nil || res.Skipped != 2 || res.Upserted != 0 {
+		t.Errorf("import not idempotent: %+v %v", res, err)
+	}
+
+	scope, _ := head.GetItemByID(1001)
+	scope.Location, scope.Remarks = "Rack 5", "moved"
+	head.EditItem(scope)
+	head.DeleteItem(1002)
+
+	last, err := branch.LastDeltaSeq(d.Source)
+	if err != nil || last != d.ToSeq {
+		t.Fatalf("unexpected last seq %d: %v", last, err)
+	}
+	d = ship(last)
+	if len(d.Changes) != 2 || d.Changes[1].Action != inventory.DeltaDelete {
+		t.Fatalf("unexpected delta: %+v", d)
+	}
+	if res, err = branch.ImportDelta(d); err != nil || res.Deleted != 1 {
+		t.Fatalf("ImportDelta failed: %+v %v", res, err)
+	}
+	a, _ := head.GetItemByID(1001)
+	b, _ := branch.GetItemByID(1001)
+	if a != b {
+		t.Errorf("copies differ:\n%+v\n%+v", a, b)
+	}
+	if _, err := branch.GetItemByID(1002); err == nil {
+		t.Error("deleted item still present")
+	}
+
+	if _, err := head.ImportDelta(d); err == nil {
+		t.Error("expected error importing own delta")
+	}
+	for _, doc := range []string{
+		`{"format": "other", "version": 1, "source": "x"}`,
+		`{"format": "bvl-delta", "version": 99, "source": "x"}`,
+		`{"format": "bvl-delta", "version": 1}`,
+	} {
+		if _, err := inventory.ReadDelta(strings.NewReader(doc)); err == nil {
+			t.Errorf("expected error for %s", doc)
+		}
+	}
+}
+
+func TestInventoryDB_Delta_SameIDs(t *testing.T) {
+	head := setupInventoryDB(t)
+	defer head.Close()
+	branch := setupInventoryDB(t)
+	defer branch.Close()
+
+	head.AppendItem(inventory.Item{ID: 1001, Description: "Scope"})
+	head.AppendItem(inventory.Item{ID: 1002, Description: "Probe"})
+	branch.AppendItem(inventory.Item{ID: 1001, Description: "Drill"})
+	drill, _ := branch.GetItemByID(1001)
+
+	d, err := head.ExportDelta(0, "")
+	if err != nil {
+		t.Fatalf("ExportDelta failed: %v", err)
+	}
+	res, err := branch.ImportDelta(d)
+	if err != nil || res.Upserted != 2 || len(res.Renumbered) != 1 ||
+		res.Renumbered[0].From != 1001 || res.Renumbered[0].To != 1003 {
+		t.Fatalf("unexpected import: %+v %v", res, err)
+	}
+	if got, _ := branch.GetItemByID(1001); got != drill {
+		t.Errorf("local item overwritten: %+v", got)
+	}
+	if got, _ := branch.GetItemByID(1003); got.Description != "Scope" {
+		t.Errorf("new item not renumbered: %+v", got)
+	}
+
+	// Later changes follow the item, not the ID
+	scope, _ := head.GetItemByID(1001)
+	scope.Location = "Lab"
+	head.EditItem(scope)
+	d, err = head.ExportDelta(d.ToSeq, "")
+	if err != nil {
+		t.Fatalf("ExportDelta failed: %v", err)
+	}
+	if _, err := branch.ImportDelta(d); err != nil {
+		t.Fatalf("ImportDelta failed: %v", err)
+	}
+	if got, _ := branch.GetItemByID(1001); got != drill {
+		t.Errorf("local item changed by delta: %+v", got)
+	}
+	if got, _ := branch.GetItemByID(1003); got.Location != "Lab" {
+		t.Errorf("change not applied to the renumbered item: %+v", got)
+	}
+
+	d.Changes[0].UID = ""
+	d.Source = "other"
+	if _, err := branch.ImportDelta(d); err == nil {
+		t.Error("expected error for a change without uid")
+	}
+}
+
+func TestInventoryDB_Delta_UnitCost(t *testing.T) {
+	head := setupInventoryDB(t)
+	defer head.Close()
+	branch := setupInventoryDB(t)
+	defer branch.Close()
+
+	err := head.AppendItem(inventory.Item{ID: 1001,
+		Description: "Resistor", Quantity: 3, UnitCost: 1.0 / 3})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+	d, err := head.ExportDelta(0, "")
+	if err != nil {
+		t.Fatalf("ExportDelta failed: %v", err)
+	}
+	if _, err := branch.ImportDelta(d); err != nil {
+		t.Fatalf("ImportDelta failed: %v", err)
+	}
+
+	a, _ := head.GetItemByID(1001)
+	b, _ := branch.GetItemByID(1001)
+	if a != b || b.UnitCost != 1.0/3 {
+		t.Errorf("copies differ:\n%+v\n%+v", a, b)
+	}
+}
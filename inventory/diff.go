@@ -0,0 +1,277 @@
+// diff.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Snapshot diff for Inventory CLI
+// Compares two sets of items, from database files or exports,
+// and reports added, removed and modified items.
+//
+
+package inventory
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ItemChange is an item present in both snapshots with
+// different fields.
+type ItemChange struct {
+	ItemID  int           `json:"item_id"`
+	Before  Item          `json:"before"`
+	After   Item          `json:"after"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// ItemDiff lists what changed from snapshot a to snapshot b,
+// each list sorted by ID.
+type ItemDiff struct {
+	Added    []Item       `json:"added"`
+	Removed  []Item       `json:"removed"`
+	Modified []ItemChange `json:"modified"`
+}
+
+// Empty reports whether the snapshots are the same.
+func (d ItemDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 &&
+		len(d.Modified) == 0
+}
+
+// Diff compares two snapshots of items, matched by ID.
+//
+// Usage:
+//
+//	d := Diff(before, after)
+//	err := WriteDiff(os.Stdout, d)
+func Diff(a, b []Item) ItemDiff {
+	old := make(map[int]Item, len(a))
+	for _, item := range a {
+		old[item.ID] = item
+	}
+	seen := make(map[int]bool, len(b))
+
+	var d ItemDiff
+	for _, item := range b {
+		seen[item.ID] = true
+		before, ok := old[item.ID]
+		if !ok {
+			d.Added = append(d.Added, item)
+			continue
+		}
+		if changes := DiffItems(before, item); len(changes) > 0 {
+			d.Modified = append(d.Modified, ItemChange{
+				ItemID: item.ID, Before: before, After: item,
+				Changes: changes,
+			})
+		}
+	}
+	for _, item := range a {
+		if !seen[item.ID] {
+			d.Removed = append(d.Removed, item)
+		}
+	}
+
+	sort.Slice(d.Added, func(i, j int) bool {
+		return d.Added[i].ID < d.Added[j].ID
+	})
+	sort.Slice(d.Removed, func(i, j int) bool {
+		return d.Removed[i].ID < d.Removed[j].ID
+	})
+	sort.Slice(d.Modified, func(i, j int) bool {
+		return d.Modified[i].ItemID < d.Modified[j].ItemID
+	})
+	return d
+}
+
+// readDBItems reads the items of a database file without
+// changing it. Columns are matched by name, so files of older
+// versions can be read too.
+func readDBItems(filename string) ([]Item, error) {
+	db, err := sql.Open("sqlite3", "file:"+filename+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("open database failed: %v", err)
+	}
+	defer db.Close()
+
+	cols, err := tableColumns(db, "inventory")
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("%s has no inventory table", filename)
+	}
+	parts := make([]string, len(cols))
+	for i, c := range cols {
+		parts[i] = fmt.Sprintf("'%s', %s", c, jsonValue(c))
+	}
+	rows, err := db.Query(`SELECT json_object(` +
+		strings.Join(parts, ", ") + `) FROM inventory ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query inventory failed: %v", err)
+	}
+	defer rows.Close()
+
+	var items []Item
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		item, err := auditItem(data)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// ReadSnapshot reads the items of a database file (.db, .sqlite,
+// .sqlite3), a CSV export (.csv), a JSON export (.json) or an
+// Excel export (.xlsx), chosen by the file extension.
+//
+// Database files are opened read-only.
+func ReadSnapshot(filename string) ([]Item, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return readDBItems(filename)
+	case ".csv":
+		return ReadCSVItems(filename)
+	case ".json":
+		return ReadJSONItems(filename)
+	case ".xlsx":
+		return ReadXLSXItems(filename)
+	}
+	return nil, fmt.Errorf("unknown snapshot type %q", filename)
+}
+
+// DiffFiles compares two snapshot files of any supported type,
+// like `bvl diff before.db after.csv`. See ReadSnapshot().
+//
+// Usage:
+//
+//	d, err := DiffFiles("backup.db", "inventory.db")
+func DiffFiles(a, b string) (ItemDiff, error) {
+	before, err := ReadSnapshot(a)
+	if err != nil {
+		return ItemDiff{}, err
+	}
+	after, err := ReadSnapshot(b)
+	if err != nil {
+		return ItemDiff{}, err
+	}
+	return Diff(before, after), nil
+}
+
+// WriteDiff prints a diff as text, as `bvl diff` shows it:
+//
+//	$ bvl diff backup.db inventory.db
+//	+ 1012 Ladder
+//	- 1007 Old scope
+//	~ 1003 Scope
+//	    location: Lab → Rack 5
+//
+// Errors are returned if writing fails.
+func WriteDiff(w io.Writer, d ItemDiff) error {
+	var b strings.Builder
+	for _, item := range d.Added {
+		fmt.Fprintf(&b, "+ %d %s\n", item.ID, item.Description)
+	}
+	for _, item := range d.Removed {
+		fmt.Fprintf(&b, "- %d %s\n", item.ID, item.Description)
+	}
+	for _, c := range d.Modified {
+		fmt.Fprintf(&b, "~ %d %s\n", c.ItemID, c.After.Description)
+		for _, f := range c.Changes {
+			fmt.Fprintf(&b, "    %s\n", f)
+		}
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("write diff failed: %v", err)
+	}
+	return nil
+}
+
+// ExportDiffJSON writes a diff to a file as indented JSON.
+func ExportDiffJSON(filename string, d ItemDiff) error {
+	return writeJSONFile(d, filename)
+}
+
+// ExportDiffPatch writes the added and modified items of a diff,
+// as they are in snapshot b, to a CSV or JSON file (by extension)
+// that ImportCSV() or ImportJSON() can apply to snapshot a.
+//
+// Usage:
+//
+//	err := ExportDiffPatch("patch.csv", d)
+//	err = inv.ImportCSV("patch.csv")
+//
+// The importers never delete, so removed items are not part of
+// the patch; delete them with DeleteItem() if wanted.
+func ExportDiffPatch(filename string, d ItemDiff) error {
+	items := append([]Item(nil), d.Added...)
+	for _, c := range d.Modified {
+		items = append(items, c.After)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		rows := make([][]string, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, itemToCSV(item))
+		}
+		return writeCSVFile(filename, csvHeader, rows)
+	case ".json":
+		return writeJSONItems(items, filename)
+	}
+	return fmt.Errorf("patch must be a .csv or .json file")
+}
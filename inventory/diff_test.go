@@ -0,0 +1,149 @@
+// diff_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for snapshot diffs
+// Uses SQLite database files and exports in a temp directory
+//
+
+package inventory_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestDiffFiles(t *testing.T) {
+	dir := t.TempDir()
+	dbFile := filepath.Join(dir, "inventory.db")
+	inv := inventory.NewInventoryDB(dbFile)
+	defer inv.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "Scope", Location: "Lab"},
+		{ID: 1002, Description: "Old scope", Location: "Lab"},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+	before := filepath.Join(dir, "before.csv")
+	if err := inv.ExportCSV(before); err != nil {
+		t.Fatalf("ExportCSV failed: %v", err)
+	}
+
+	scope, _ := inv.GetItemByID(1001)
+	scope.Location, scope.Remarks = "Rack 5", "moved"
+	if err := inv.EditItem(scope); err != nil {
+		t.Fatalf("EditItem failed: %v", err)
+	}
+	if err := inv.DeleteItem(1002); err != nil {
+		t.Fatalf("DeleteItem failed: %v", err)
+	}
+	err := inv.AppendItem(inventory.Item{ID: 1003, Description: "Probe"})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+
+	d, err := inventory.DiffFiles(before, dbFile)
+	if err != nil {
+		t.Fatalf("DiffFiles failed: %v", err)
+	}
+	if len(d.Added) != 1 || d.Added[0].ID != 1003 ||
+		len(d.Removed) != 1 || d.Removed[0].ID != 1002 ||
+		len(d.Modified) != 1 || d.Modified[0].ItemID != 1001 {
+		t.Fatalf("unexpected diff: %+v", d)
+	}
+
+	var out strings.Builder
+	if err := inventory.WriteDiff(&out, d); err != nil {
+		t.Fatalf("WriteDiff failed: %v", err)
+	}
+	for _, want := range []string{
+		"+ 1003 Probe", "- 1002 Old scope", "~ 1001 Scope",
+		"    location: Lab → Rack 5",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("diff missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := inventory.ExportDiffJSON(
+		filepath.Join(dir, "diff.json"), d); err != nil {
+		t.Errorf("ExportDiffJSON failed: %v", err)
+	}
+
+	// Applying the patch to the old snapshot leaves only the removal
+	patch := filepath.Join(dir, "patch.json")
+	if err := inventory.ExportDiffPatch(patch, d); err != nil {
+		t.Fatalf("ExportDiffPatch failed: %v", err)
+	}
+	old := inventory.NewInventoryDB(filepath.Join(dir, "old.db"))
+	defer old.Close()
+	if err := old.ImportCSV(before); err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+	if err := old.ImportJSON(patch); err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	d, err = inventory.DiffFiles(dbFile, filepath.Join(dir, "old.db"))
+	if err != nil {
+		t.Fatalf("DiffFiles failed: %v", err)
+	}
+	if len(d.Added) != 1 || d.Added[0].ID != 1002 ||
+		len(d.Removed)+len(d.Modified) != 0 {
+		t.Errorf("patch not applied: %+v", d)
+	}
+
+	if _, err := inventory.DiffFiles(before, "inventory.xml"); err == nil {
+		t.Error("expected error for unknown file type")
+	}
+	if err := inventory.ExportDiffPatch("patch.txt", d); err == nil {
+		t.Error("expected error for unknown patch type")
+	}
+}
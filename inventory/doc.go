@@ -68,14 +68,17 @@
 // Data Model:
 //
 //   - Item struct:
-//     ID, Description, Location, Status, Remarks (with FormatRemarks)
+//     ID, Description, Location, Status, Remarks (with FormatRemarks),
+//     ParentID, PurchaseDate, WarrantyUntil, ExpiryDate,
+//     Quantity, ReorderLevel, ReorderQty, UnitCost
 //   - FormatRemarks(): consistent timestamped remarks
 //   - JSON tags for web/app/API compatibility
 //
 // Database Operations:
 //
 // - AddItem()
-// - EditItem()
+// - EditItemDiff() and InventoryDB.EditItem() record changed fields
+// - WithDiffFormat() sets their format per InventoryDB
 // - DeleteItem()
 // - AppendItem()
 // - AppendRemarksEntry()
@@ -85,6 +88,195 @@
 // - NewItemIterator() with streaming Next()
 // - ResetSequence()
 //
+// Kits and Assemblies:
+//
+// - SetParent() with cycle guard, also enforced by triggers on every write
+// - ListComponents()
+// - GetKitTree() with KitNode.Tree() text view
+// - ListKits()
+// - UpdateKit() with optional cascade to components
+// - ExportKitsJSON() / ImportKitsJSON() nested JSON
+//
+// Maintenance Schedules:
+//
+// - AddMaintenancePlan() / DeleteMaintenancePlan()
+// - ListMaintenancePlans()
+// - RecordMaintenance() logs to remarks, advances next due date
+// - ListMaintenanceDue() upcoming and overdue report
+// - WriteMaintenanceReport() text report
+// - ParseDays() for "30d" style windows
+//
+// Filters, Warranty and Expiry:
+//
+// - ItemFilter with Where() for location, status and date ranges
+// - NewFilteredItemIterator() / ListFiltered()
+// - ExportCSVFiltered() / ExportJSONFiltered()
+// - ListExpiring() warranty and expiry report
+// - WriteExpiryReport() text report
+// - CheckExpiry() returns ErrItemsExpired for cron
+//
+// Attachments and Backups:
+//
+// - AttachFile() / AttachBytes() with MIME type, SHA-256 and size
+// - ListAttachments()
+// - ExtractAttachment() / GetAttachmentData() with checksum check
+// - DeleteAttachment()
+// - Backup() full copy of the database file
+// - ExportBundle() zip with inventory and attachments
+//
+// Actors and Audit Log:
+//
+// - WithActor() / WithContext() with NewActorContext()
+// - audit_log table filled by triggers for every change
+// - audit_log is append-only: updates and deletes are rejected
+// - ListAudit() with AuditQuery
+// - AuditEntry.Changes() and DiffItems() field diffs
+// - WriteAuditReport() text report
+//
+// Access Control:
+//
+// - Roles: viewer, clerk, manager, admin
+// - AddUser() / DeleteUser() / ListUsers()
+// - Permission checks on every InventoryDB change, *PermissionError
+// - CreateAPIToken() / RevokeAPIToken() with hashed tokens
+// - AuthenticateToken() and TokenAuth() HTTP middleware
+//
+// Labels:
+//
+// - Code128 barcode and QR code of the item ID
+// - WriteLabelSVG() / WriteLabelPNG() for one item
+// - WriteLabelSheetSVG() / WriteLabelSheetPNG() for A4 label stocks
+// - GenerateLabels() with ParseItemFilter() for `--filter` terms
+//
+// Thermal Labels:
+//
+// - ZPL and EPL item tags for Zebra-style printers
+// - ThermalTemplate: size, DPI, margins and printed fields
+// - LoadThermalTemplate() from a JSON file
+// - WriteThermalLabels() / PrintThermalLabels() to a file or raw device
+//
+// Scanner Quick Entry:
+//
+// - Scan() reads scanned IDs line by line from any io.Reader
+// - ScanAction: lookup, location, status, checkout, checkin, remark
+// - ApplyScanAction() changes one item and logs it in remarks
+// - ErrItemNotFound from GetItemByID() for unknown IDs
+//
+// Stocktake:
+//
+// - StartStocktake() snapshots the items selected by a filter
+// - MarkStocktakeItem(): found, missing or found elsewhere
+// - ScanStocktake() / CountStocktakeItem() for scanned counts
+// - StocktakeVariance() and WriteVarianceReport()
+// - ReconcileStocktake() updates locations and statuses in one transaction
+//
+// Reorder Points:
+//
+// - Quantity, ReorderLevel and ReorderQty on items
+// - ListLowStock() and WriteLowStockReport()
+// - DraftPurchaseList() / ExportPurchaseListCSV()
+// - AdjustQuantity() logs stock changes in remarks
+// - EditItem() keeps stored stock fields left at zero
+//
+// Stock Locations:
+//
+// - stock_balances: on-hand quantity per item and location
+// - Transfer() writes paired movements in one transaction
+// - ReceiveStock() / IssueStock() keep the item quantity as the total
+// - Other quantity writes to items with balances are rejected
+// - ItemBalances() / LocationBalances() / ListMovements()
+//
+// Suppliers and Purchases:
+//
+// - suppliers table: name, contact, GSTIN, notes
+// - AddSupplier() / UpdateSupplier() / DeleteSupplier() / ListSuppliers()
+// - RecordPurchase(): invoice number, date, unit cost and quantity
+// - SupplierPurchases() and ItemPurchases() queries
+//
+// Valuation and Depreciation:
+//
+// - UnitCost on items, used when there are no purchases
+// - Valuate(): average cost or FIFO from purchase records
+// - NewValuationReport() grouped by location or status, with totals (items have no tags to group by)
+// - DepreciationSchedule(): straight line and written-down value
+// - BookValues() as of a date, pro rata in the running year
+// - ExportValuationCSV() / ExportBookValuesCSV() / ExportScheduleCSV() / ExportReportJSON()
+//
+// Partial Updates:
+//
+// - ItemPatch with pointer fields, nil keeps the stored value
+// - UpdateItem() changes only the set columns and records the diff
+// - ParseMergePatch() reads JSON Merge Patch (RFC 7396)
+// - MergePatchHandler() serves PATCH /items/{id}
+// - Body limited to MaxPatchBytes; 400 only for invalid patches
+//
+// Bulk Edits:
+//
+// - PreviewBulkUpdate() lists the field changes without writing
+// - BulkUpdate(): one ItemPatch and remark for every filtered item, in one transaction
+// - A blank filter is refused, BulkUpdateAll() updates every item
+// - Returns the IDs of the updated items
+//
+// Editor Round-Trip:
+//
+// - WriteEditTSV() / ReadEditTSV() tab separated edit file
+// - PlanEdit(): modified, added and deleted rows
+// - EditPlan.WriteSummary() change summary
+// - EditItems() runs $EDITOR, confirms and applies in one transaction
+// - Items changed while the editor was open fail with ErrEditConflict
+//
+// Changesets and Undo:
+//
+// - changesets table: one row per InventoryDB operation
+// - audit_log rows carry their changeset, AuditQuery.Changeset
+// - ListChangesets() / Revert() restore item rows with original IDs and remarks
+// - Stock balances of a deleted item are restored from the movement log
+// - Undo() reverts the actor's latest operation
+// - ErrRevertConflict when an item changed since
+//
+// Offline Sync:
+//
+// - item_versions: per-row version, timestamp and uid kept by triggers
+// - Sync() / SyncWith() merge two database files both ways
+// - Items matched by uid, clashing IDs renumbered (SyncRenumber)
+// - Field-level three-way merge against the last sync (sync_base)
+// - Appended remark logs unioned, deletes propagated
+// - A copied database file gets a new sync ID when opened
+// - SyncConflict report with WriteSyncReport()
+//
+// Snapshot Diff:
+//
+// - ReadSnapshot(): .db (read-only), CSV or JSON export
+// - Diff() / DiffFiles(): added, removed and modified items with field changes
+// - WriteDiff() text, ExportDiffJSON()
+// - ExportDiffPatch() CSV or JSON for the importers
+//
+// Delta Export and Import:
+//
+// - ExportDelta(): items changed after a sequence number or time
+// - Delta envelope with format, version, source and sequence range
+// - WriteDelta() / ReadDelta() with format and version checks
+// - ImportDelta() applies idempotently, delta_sources keeps the last sequence
+// - Items matched by uid, never by ID; clashing new IDs renumbered
+// - LastDeltaSeq() for the next export
+//
+// JSON Lines (NDJSON):
+//
+// - WriteNDJSON() streams filtered items through an ItemIterator
+// - ExportNDJSON() / ImportNDJSONFile()
+// - ImportNDJSON() decodes line by line, *LineError with the line number
+// - Optional continue-on-error with the skipped lines in NDJSONResult
+//
+// Excel Support:
+//
+// - ExportXLSX() / ExportXLSXFiltered()
+// - Header row, frozen panes, column widths and wrapped cells
+// - ImportXLSX() - same column mapping as ImportCSV()
+// - Date-formatted cells read as YYYY-MM-DD
+// - ReadXLSXItems()
+// - Pure Go (excelize), Excel is not needed
+// - InventoryDB wrappers
+//
 // CSV Support:
 //
 // - ExportCSV()
@@ -96,7 +288,9 @@
 // JSON Support:
 //
 // - ExportJSON()
-// - ImportJSON()
+// - ExportJSONEnvelope() with version, count, checksum and sequence
+// - ImportJSON() accepts the bare array or the envelope
+// - ReadJSONItems() reads both forms too, for ReadSnapshot() and DiffFiles()
 // - ViewJSON()
 // - ExportJSONToString()
 // - ImportJSONFromString()
@@ -115,6 +309,30 @@
 // - inventorydb_test.go: InventoryDB methods
 // - csv_test.go: CSV
 // - json_test.go: JSON
+// - kit_test.go: Kits
+// - maint_test.go: Maintenance schedules
+// - filter_test.go: Filters
+// - expiry_test.go: Warranty and expiry
+// - attach_test.go: Attachments
+// - audit_test.go: Audit log
+// - rbac_test.go: Access control
+// - label_test.go: Labels
+// - thermal_test.go: Thermal labels
+// - scan_test.go: Scanner quick entry
+// - stocktake_test.go: Stocktake sessions
+// - reorder_test.go: Reorder points and low stock
+// - stock_test.go: Stock balances and transfers
+// - supplier_test.go: Suppliers and purchases
+// - valuation_test.go: Valuation and depreciation
+// - patch_test.go: Partial updates and merge patch
+// - bulk_test.go: Bulk edits
+// - editor_test.go: Editor round-trip
+// - revert_test.go: Changesets, revert and undo
+// - sync_test.go: Offline sync
+// - diff_test.go: Snapshot diffs
+// - delta_test.go: Delta export and import
+// - ndjson_test.go: JSON Lines import and export
+// - xlsx_test.go: Excel import and export
 // - All error paths covered
 // - Rollback scenarios covered
 //
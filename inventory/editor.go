@@ -0,0 +1,458 @@
+// editor.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Editor round-trip for Inventory CLI
+// Dumps items to a TSV file, lets the user edit it in $EDITOR,
+// and applies the modified, added and deleted rows together.
+//
+
+package inventory
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// editHeader lists the columns of the edit file, in order.
+// The remarks column starts blank; text typed there is appended
+// to the item remarks as a new entry.
+var editHeader = []string{
+	"id", "description", "location", "status",
+	"purchase_date", "warranty_until", "expiry_date",
+	"quantity", "reorder_level", "reorder_qty", "unit_cost",
+	"remarks",
+}
+
+// editHelp is written at the top of the edit file.
+const editHelp = `# Edit the rows below, columns are separated by tabs.
+# Change a field to modify an item, type in remarks to log a note.
+# Add a row with a blank id to add an item, delete a row to delete it.
+# Lines starting with # are ignored. Save an unchanged file to abort.
+`
+
+// WriteEditTSV writes items as a tab separated edit file,
+// with a help comment and a header row:
+//
+//	id	description	location	status	...	remarks
+//
+// Usage:
+//
+//	err := WriteEditTSV(file, items)
+//
+// Errors are returned if writing fails.
+func WriteEditTSV(w io.Writer, items []Item) error {
+	if _, err := io.WriteString(w, editHelp); err != nil {
+		return fmt.Errorf("write edit file failed: %v", err)
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = '\t'
+	if err := writer.Write(editHeader); err != nil {
+		return fmt.Errorf("write edit header failed: %v", err)
+	}
+	for _, item := range items {
+		err := writer.Write([]string{
+			strconv.Itoa(item.ID),
+			item.Description, item.Location, item.Status,
+			item.PurchaseDate, item.WarrantyUntil, item.ExpiryDate,
+			strconv.Itoa(item.Quantity),
+			strconv.Itoa(item.ReorderLevel),
+			strconv.Itoa(item.ReorderQty),
+			strconv.FormatFloat(item.UnitCost, 'f', -1, 64),
+			"",
+		})
+		if err != nil {
+			return fmt.Errorf("write edit row failed: %v", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write edit file failed: %v", err)
+	}
+	return nil
+}
+
+// ReadEditTSV parses an edit file written by WriteEditTSV().
+//
+// Columns are matched by header name like ImportCSV(), and
+// rows are validated the same way. Comment lines are skipped.
+//
+// Returns error on parse error.
+func ReadEditTSV(r io.Reader) ([]Item, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = '\t'
+	reader.Comment = '#'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read edit file failed: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("edit file has no header")
+	}
+
+	header, err := csvHeaderIndex(rows[0])
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	for i, row := range rows[1:] {
+		item, err := itemFromCSV(header, row)
+		if err != nil {
+			return nil, fmt.Errorf("edit row %d: %v", i+1, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// ErrEditConflict is returned by ApplyEditPlan() when an item
+// changed after it was written to the edit file.
+var ErrEditConflict = errors.New("changed since the edit began")
+
+// EditChange is an item modified in the edit file.
+// Before is the item as given to the editor, Remark is the text
+// typed in its remarks column.
+type EditChange struct {
+	Item    Item          `json:"item"`
+	Before  Item          `json:"before"`
+	Changes []FieldChange `json:"changes"`
+	Remark  string        `json:"remark,omitempty"`
+}
+
+// EditPlan is the outcome of an edit, ready to apply.
+type EditPlan struct {
+	Modified []EditChange `json:"modified,omitempty"`
+	Added    []Item       `json:"added,omitempty"`
+	Deleted  []Item       `json:"deleted,omitempty"`
+}
+
+// Empty reports whether the plan changes nothing.
+func (p EditPlan) Empty() bool {
+	return len(p.Modified) == 0 && len(p.Added) == 0 &&
+		len(p.Deleted) == 0
+}
+
+// PlanEdit compares the items given to the editor with the
+// items read back.
+//
+// Rows with a blank id are added, missing IDs are deleted, and
+// rows whose fields or remarks changed are modified. An ID that
+// was not given to the editor, or appears twice, is an error.
+func PlanEdit(before, after []Item) (EditPlan, error) {
+	var plan EditPlan
+	original := make(map[int]Item, len(before))
+	for _, item := range before {
+		original[item.ID] = item
+	}
+
+	seen := make(map[int]bool, len(after))
+	for _, item := range after {
+		if item.ID == 0 {
+			plan.Added = append(plan.Added, item)
+			continue
+		}
+		old, ok := original[item.ID]
+		if !ok {
+			return plan, fmt.Errorf("item %d was not in the edit file",
+				item.ID)
+		}
+		if seen[item.ID] {
+			return plan, fmt.Errorf("item %d appears twice", item.ID)
+		}
+		seen[item.ID] = true
+
+		remark := strings.TrimSpace(item.Remarks)
+		item.ParentID = old.ParentID
+		item.Remarks = old.Remarks
+		changes := DiffItems(old, item)
+		if len(changes) > 0 || remark != "" {
+			plan.Modified = append(plan.Modified, EditChange{
+				Item: item, Before: old, Changes: changes,
+				Remark: remark,
+			})
+		}
+	}
+
+	for _, item := range before {
+		if !seen[item.ID] {
+			plan.Deleted = append(plan.Deleted, item)
+		}
+	}
+	return plan, nil
+}
+
+// WriteSummary prints the plan, one line per change:
+//
+//	modified 1003: location: Lab → Rack 5
+//	added    Oscilloscope
+//	deleted  1007: Old scope
+//	1 modified, 1 added, 1 deleted
+//
+// Errors are returned if writing fails.
+func (p EditPlan) WriteSummary(w io.Writer) error {
+	var lines []string
+	for _, c := range p.Modified {
+		text := FormatChanges(c.Changes)
+		if c.Remark != "" {
+			if text != "" {
+				text += "; "
+			}
+			text += "remark: " + c.Remark
+		}
+		lines = append(lines,
+			fmt.Sprintf("modified %d: %s", c.Item.ID, text))
+	}
+	for _, item := range p.Added {
+		lines = append(lines, "added    "+item.Description)
+	}
+	for _, item := range p.Deleted {
+		lines = append(lines,
+			fmt.Sprintf("deleted  %d: %s", item.ID, item.Description))
+	}
+	lines = append(lines, fmt.Sprintf("%d modified, %d added, %d deleted",
+		len(p.Modified), len(p.Added), len(p.Deleted)))
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	if err != nil {
+		return fmt.Errorf("write summary failed: %v", err)
+	}
+	return nil
+}
+
+// editPatch returns a patch that sets only the changed fields
+// of the item, so fields not edited keep their stored values.
+func editPatch(item Item, changes []FieldChange, remark string) ItemPatch {
+	var p ItemPatch
+	for _, c := range changes {
+		switch c.Field {
+		case "description":
+			p.Description = &item.Description
+		case "location":
+			p.Location = &item.Location
+		case "status":
+			p.Status = &item.Status
+		case "purchase_date":
+			p.PurchaseDate = &item.PurchaseDate
+		case "warranty_until":
+			p.WarrantyUntil = &item.WarrantyUntil
+		case "expiry_date":
+			p.ExpiryDate = &item.ExpiryDate
+		case "quantity":
+			p.Quantity = &item.Quantity
+		case "reorder_level":
+			p.ReorderLevel = &item.ReorderLevel
+		case "reorder_qty":
+			p.ReorderQty = &item.ReorderQty
+		case "unit_cost":
+			p.UnitCost = &item.UnitCost
+		}
+	}
+	if remark != "" {
+		p.Remarks = &remark
+	}
+	return p
+}
+
+// checkUnchanged re-reads the item and returns ErrEditConflict
+// if it no longer matches the snapshot given to the editor.
+func checkUnchanged(db Querier, snapshot Item) error {
+	stored, err := GetItemByID(db, snapshot.ID)
+	if err != nil {
+		return err
+	}
+	if len(DiffItems(snapshot, stored)) > 0 {
+		return fmt.Errorf("item %d %w", snapshot.ID, ErrEditConflict)
+	}
+	return nil
+}
+
+// ApplyEditPlan applies a plan: modified items are updated with
+// UpdateItem() (so their changes are logged in remarks), added
+// items use AddItem() and deleted items DeleteItem().
+//
+// Only the edited fields of a modified item are written. Modified
+// and deleted items are read again first, and the plan is refused
+// with ErrEditConflict if any of them changed after PlanEdit()
+// saw it. Run it inside a transaction so the check and the
+// changes see the same rows.
+//
+// Usage:
+//
+//	plan, err := PlanEdit(before, after)
+//	err = ApplyEditPlan(tx, plan)
+//
+// Works with both *sql.DB and *sql.Tx.
+func ApplyEditPlan(exec ExecQuerier, p EditPlan) error {
+	return applyEditPlan(exec, p, FormatChanges)
+}
+
+// applyEditPlan is ApplyEditPlan() with the DiffFormat to record
+// the changed fields with.
+func applyEditPlan(exec ExecQuerier, p EditPlan, format DiffFormat) error {
+	for _, c := range p.Modified {
+		if err := checkUnchanged(exec, c.Before); err != nil {
+			return err
+		}
+	}
+	for _, item := range p.Deleted {
+		if err := checkUnchanged(exec, item); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range p.Modified {
+		_, err := updateItem(exec, c.Item.ID,
+			editPatch(c.Item, c.Changes, c.Remark), format)
+		if err != nil {
+			return fmt.Errorf("item %d: %v", c.Item.ID, err)
+		}
+	}
+	for _, item := range p.Added {
+		if err := AddItem(exec, item); err != nil {
+			return fmt.Errorf("add %q: %v", item.Description, err)
+		}
+	}
+	for _, item := range p.Deleted {
+		if err := DeleteItem(exec, item.ID); err != nil {
+			return fmt.Errorf("item %d: %v", item.ID, err)
+		}
+	}
+	return nil
+}
+
+// editorCommand returns the editor to run: the given command,
+// else $VISUAL, else $EDITOR, else vi.
+func editorCommand(editor string) []string {
+	for _, e := range []string{
+		editor, os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi",
+	} {
+		if args := strings.Fields(e); len(args) > 0 {
+			return args
+		}
+	}
+	return nil
+}
+
+// EditItems lets the user edit the items selected by the filter
+// in a text editor, like `kubectl edit`.
+//
+// The items are written to a temporary TSV file (see
+// WriteEditTSV()) and the editor is run on it. When it exits,
+// the file is read back and compared with the items. If
+// anything changed, confirm is called with the plan (nil
+// confirms) and, when it agrees, the whole plan is applied in
+// one transaction. Items changed by someone else while the editor
+// was open are refused with ErrEditConflict, see ApplyEditPlan().
+//
+// Usage:
+//
+//	plan, err := inv.EditItems(ItemFilter{Location: "Lab"}, "",
+//	    func(p EditPlan) bool {
+//	        p.WriteSummary(os.Stdout)
+//	        return askYesNo("apply?")
+//	    })
+//
+// An empty editor uses $VISUAL or $EDITOR. The returned plan is
+// empty when nothing changed or confirm declined.
+//
+// Requires RoleManager.
+func (inv *InventoryDB) EditItems(f ItemFilter, editor string,
+	confirm func(EditPlan) bool) (EditPlan, error) {
+	before, err := queryFiltered(inv.db, f)
+	if err != nil {
+		return EditPlan{}, err
+	}
+
+	file, err := os.CreateTemp("", "bvl-edit-*.tsv")
+	if err != nil {
+		return EditPlan{}, fmt.Errorf("create edit file failed: %v", err)
+	}
+	defer os.Remove(file.Name())
+	err = WriteEditTSV(file, before)
+	if cerr := file.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("write edit file failed: %v", cerr)
+	}
+	if err != nil {
+		return EditPlan{}, err
+	}
+
+	args := append(editorCommand(editor), file.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return EditPlan{}, fmt.Errorf("editor failed: %v", err)
+	}
+
+	file, err = os.Open(file.Name())
+	if err != nil {
+		return EditPlan{}, fmt.Errorf("open edit file failed: %v", err)
+	}
+	defer file.Close()
+	after, err := ReadEditTSV(file)
+	if err != nil {
+		return EditPlan{}, err
+	}
+	plan, err := PlanEdit(before, after)
+	if err != nil || plan.Empty() {
+		return EditPlan{}, err
+	}
+	if confirm != nil && !confirm(plan) {
+		return EditPlan{}, nil
+	}
+
+	err = inv.withTx("edit_items", func(tx ExecQuerier) error {
+		return applyEditPlan(tx, plan, inv.diffFormat)
+	})
+	if err != nil {
+		return EditPlan{}, err
+	}
+	return plan, nil
+}
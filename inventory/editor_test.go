@@ -0,0 +1,195 @@
+// editor_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for the editor round-trip
+// Uses in-memory SQLite DB and a shell script as the editor
+//
+
+package inventory_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestPlanEdit(t *testing.T) {
+	before := []inventory.Item{
+		{ID: 1001, Description: "Scope", Location: "Lab", Status: "OK"},
+		{ID: 1002, Description: "Old scope", Location: "Lab",
+			Status: "Broken"},
+		{ID: 1003, Description: "Probe", Location: "Lab", Status: "OK",
+			Remarks: "[2025-01-01 10:00] bought"},
+	}
+
+	var buf strings.Builder
+	if err := inventory.WriteEditTSV(&buf, before); err != nil {
+		t.Fatalf("WriteEditTSV failed: %v", err)
+	}
+	text := buf.String()
+	if strings.Contains(text, "bought") {
+		t.Errorf("remarks log written to edit file:\n%s", text)
+	}
+	text = strings.Replace(text,
+		"1001\tScope\tLab", "1001\tScope\tRack 5", 1)
+	text = strings.Replace(text,
+		"1002\tOld scope\tLab\tBroken\t\t\t\t0\t0\t0\t0\t\n", "", 1)
+	text = strings.Replace(text, "\t0\t0\t0\t0\t\n",
+		"\t0\t0\t0\t0\tcalibrated\n", -1)
+	text += "\tSignal generator\tLab\tNew\t\t\t\t1\t0\t0\t0\t\n"
+
+	after, err := inventory.ReadEditTSV(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("ReadEditTSV failed: %v", err)
+	}
+	plan, err := inventory.PlanEdit(before, after)
+	if err != nil {
+		t.Fatalf("PlanEdit failed: %v", err)
+	}
+	if len(plan.Modified) != 2 || len(plan.Added) != 1 ||
+		len(plan.Deleted) != 1 || plan.Deleted[0].ID != 1002 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if c := plan.Modified[0]; len(c.Changes) != 1 ||
+		c.Changes[0].Field != "location" || c.Remark != "calibrated" {
+		t.Errorf("unexpected change: %+v", c)
+	}
+	if c := plan.Modified[1]; len(c.Changes) != 0 || c.Remark == "" {
+		t.Errorf("unexpected remark only change: %+v", c)
+	}
+
+	var out strings.Builder
+	if err := plan.WriteSummary(&out); err != nil {
+		t.Fatalf("WriteSummary failed: %v", err)
+	}
+	for _, want := range []string{
+		"modified 1001: location: Lab → Rack 5; remark: calibrated",
+		"added    Signal generator",
+		"deleted  1002: Old scope",
+		"2 modified, 1 added, 1 deleted",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("summary missing %q:\n%s", want, out.String())
+		}
+	}
+
+	dup := append(after, after[0])
+	if _, err := inventory.PlanEdit(before, dup); err == nil {
+		t.Error("expected error for duplicate ID")
+	}
+	stray := []inventory.Item{{ID: 1009, Description: "Stray"}}
+	if _, err := inventory.PlanEdit(before, stray); err == nil {
+		t.Error("expected error for unknown ID")
+	}
+}
+
+func TestInventoryDB_EditItems(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("editor script needs a POSIX shell")
+	}
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "Scope", Location: "Lab", Status: "OK"},
+		{ID: 1002, Description: "Probe", Location: "Store", Status: "OK"},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	script := "#!/bin/sh\nsed -i.bak 's/\tOK\t/\tSpare\t/' \"$1\"\n"
+	if err := os.WriteFile(editor, []byte(script), 0o755); err != nil {
+		t.Fatalf("write editor failed: %v", err)
+	}
+
+	asked := false
+	plan, err := inv.EditItems(inventory.ItemFilter{Location: "Lab"},
+		editor, func(p inventory.EditPlan) bool {
+			asked = true
+			return false
+		})
+	if err != nil || !asked || !plan.Empty() {
+		t.Fatalf("declined edit: %+v %v", plan, err)
+	}
+	if got, _ := inv.GetItemByID(1001); got.Status != "OK" {
+		t.Errorf("declined edit applied: %+v", got)
+	}
+
+	plan, err = inv.EditItems(inventory.ItemFilter{Location: "Lab"},
+		editor, nil)
+	if err != nil || len(plan.Modified) != 1 {
+		t.Fatalf("EditItems failed: %+v %v", plan, err)
+	}
+	got, _ := inv.GetItemByID(1001)
+	if got.Status != "Spare" ||
+		!strings.HasSuffix(got.Remarks, "] status: OK → Spare") {
+		t.Errorf("edit not applied: %+v", got)
+	}
+	if got, _ := inv.GetItemByID(1002); got.Status != "OK" {
+		t.Errorf("unselected item changed: %+v", got)
+	}
+
+	// Item changed by someone else while the editor was open
+	_, err = inv.EditItems(inventory.ItemFilter{Location: "Store"},
+		editor, func(p inventory.EditPlan) bool {
+			inv.AppendRemarksEntry(1002, "moved meanwhile")
+			return true
+		})
+	if !errors.Is(err, inventory.ErrEditConflict) {
+		t.Fatalf("expected edit conflict, got %v", err)
+	}
+	if got, _ := inv.GetItemByID(1002); got.Status != "OK" {
+		t.Errorf("conflicting edit applied: %+v", got)
+	}
+}
@@ -0,0 +1,240 @@
+// expiry.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Warranty and expiry tracking for Inventory CLI
+// Reports items whose warranty or shelf life ends soon,
+// and fails a check when stock has already expired (for cron).
+//
+
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ErrItemsExpired is returned by CheckExpiry when at least one
+// item is past its expiry date. A CLI can map it to a non-zero
+// exit status.
+var ErrItemsExpired = errors.New("items expired")
+
+// Kinds of ExpiryAlert.
+const (
+	AlertWarranty = "warranty"
+	AlertExpiry   = "expiry"
+)
+
+// ExpiryAlert is an item whose warranty or expiry date falls
+// within a report window.
+//
+// Fields:
+//
+//	Item     - the inventory item
+//	Kind     - AlertWarranty or AlertExpiry
+//	Date     - the warranty-until or expiry date
+//	DaysLeft - days from today, negative once passed
+type ExpiryAlert struct {
+	Item     Item   `json:"item"`
+	Kind     string `json:"kind"`
+	Date     string `json:"date"`
+	DaysLeft int    `json:"days_left"`
+}
+
+// Expired reports whether the date has already passed.
+func (a ExpiryAlert) Expired() bool {
+	return a.DaysLeft < 0
+}
+
+// ListExpiring returns alerts for the items whose warranty or
+// expiry date is within the given number of days, earliest first.
+//
+// The report includes:
+// - Items whose warranty ends between today and the window end
+// - Items whose expiry date is before the window end
+// - Expired stock is included until it is disposed of
+//
+// Usage:
+//
+//	alerts, err := ListExpiring(db, 30)
+//	for _, a := range alerts {
+//	    fmt.Println(a.Date, a.Kind, a.Item.Description)
+//	}
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListExpiring(db Querier, withinDays int) ([]ExpiryAlert, error) {
+	now := today()
+	until, err := addDays(now, withinDays)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+        SELECT `+itemFields+`
+        FROM inventory
+        WHERE (warranty_until >= ? AND warranty_until <= ?)
+           OR expiry_date <= ?
+        ORDER BY id`, now, until, until)
+	if err != nil {
+		return nil, fmt.Errorf("expiry query failed: %v", err)
+	}
+	items, err := scanAllItems(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	start, _ := time.Parse(DateLayout, now)
+	var alerts []ExpiryAlert
+	add := func(item Item, kind, date string) error {
+		t, err := time.Parse(DateLayout, date)
+		if err != nil {
+			return fmt.Errorf("item %d has invalid %s date %q",
+				item.ID, kind, date)
+		}
+		alerts = append(alerts, ExpiryAlert{
+			Item: item, Kind: kind, Date: date,
+			DaysLeft: int(t.Sub(start).Hours() / 24),
+		})
+		return nil
+	}
+
+	for _, item := range items {
+		w := item.WarrantyUntil
+		if w != "" && w >= now && w <= until {
+			if err := add(item, AlertWarranty, w); err != nil {
+				return nil, err
+			}
+		}
+		if e := item.ExpiryDate; e != "" && e <= until {
+			if err := add(item, AlertExpiry, e); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].Date < alerts[j].Date
+	})
+	return alerts, nil
+}
+
+// WriteExpiryReport prints expiry alerts as text columns:
+//
+//	EXPIRED  expiry    2025-05-31  1011   Fire extinguisher  Hall
+//	soon     warranty  2025-07-15  1002   UPS 3KVA           Rack 1
+//
+// Errors are returned if writing fails.
+func WriteExpiryReport(w io.Writer, alerts []ExpiryAlert) error {
+	for _, a := range alerts {
+		state := "soon"
+		if a.Expired() {
+			state = "EXPIRED"
+		}
+		_, err := fmt.Fprintf(w, "%-8s %-9s %-10s %-6d %-20s %s\n",
+			state, a.Kind, a.Date, a.Item.ID,
+			a.Item.Description, a.Item.Location)
+		if err != nil {
+			return fmt.Errorf("write report failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// CheckExpiry writes the expiry report for the window to w and
+// returns ErrItemsExpired if any item is already past its
+// expiry date.
+//
+// Intended for cron jobs: the report goes to stdout (and mail),
+// the error decides the exit status.
+//
+// Usage:
+//
+//	err := CheckExpiry(db, os.Stdout, 30)
+//	if errors.Is(err, ErrItemsExpired) {
+//	    os.Exit(1)
+//	}
+//
+// Works with both *sql.DB and *sql.Tx.
+func CheckExpiry(db Querier, w io.Writer, withinDays int) error {
+	alerts, err := ListExpiring(db, withinDays)
+	if err != nil {
+		return err
+	}
+	if err := WriteExpiryReport(w, alerts); err != nil {
+		return err
+	}
+
+	expired := 0
+	for _, a := range alerts {
+		if a.Kind == AlertExpiry && a.Expired() {
+			expired++
+		}
+	}
+	if expired > 0 {
+		return fmt.Errorf("%d %w", expired, ErrItemsExpired)
+	}
+	return nil
+}
+
+// ListExpiring wraps ListExpiring.
+//
+// Usage:
+//
+//	alerts, err := inv.ListExpiring(30)
+func (inv *InventoryDB) ListExpiring(withinDays int) ([]ExpiryAlert, error) {
+	return ListExpiring(inv.db, withinDays)
+}
+
+// CheckExpiry wraps CheckExpiry.
+//
+// Usage:
+//
+//	err := inv.CheckExpiry(os.Stdout, 30)
+func (inv *InventoryDB) CheckExpiry(w io.Writer, withinDays int) error {
+	return CheckExpiry(inv.db, w, withinDays)
+}
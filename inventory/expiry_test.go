@@ -0,0 +1,194 @@
+// expiry_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for warranty and expiry tracking
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func setupExpiry(t *testing.T) *inventory.InventoryDB {
+	inv := setupInventoryDB(t)
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "UPS", PurchaseDate: "2024-01-10",
+			WarrantyUntil: daysFromNow(15)},
+		{ID: 1002, Description: "Old UPS",
+			WarrantyUntil: daysFromNow(-100)},
+		{ID: 1003, Description: "Extinguisher",
+			ExpiryDate: daysFromNow(-2)},
+		{ID: 1004, Description: "Battery",
+			ExpiryDate: daysFromNow(90)},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+	return inv
+}
+
+func TestInventoryDB_ItemDates(t *testing.T) {
+	inv := setupExpiry(t)
+	defer inv.Close()
+
+	got, _ := inv.GetItemByID(1001)
+	if got.PurchaseDate != "2024-01-10" {
+		t.Errorf("unexpected PurchaseDate: %q", got.PurchaseDate)
+	}
+
+	// Blank dates keep the stored value on edit
+	got.Remarks = "checked"
+	got.WarrantyUntil = ""
+	if err := inv.EditItem(got); err != nil {
+		t.Fatalf("EditItem failed: %v", err)
+	}
+	again, _ := inv.GetItemByID(1001)
+	if again.WarrantyUntil == "" {
+		t.Errorf("EditItem cleared WarrantyUntil")
+	}
+
+	err := inv.AddItem(inventory.Item{ExpiryDate: "31/12/2025"})
+	if err == nil {
+		t.Errorf("expected error for invalid date")
+	}
+}
+
+func TestInventoryDB_ListExpiring(t *testing.T) {
+	inv := setupExpiry(t)
+	defer inv.Close()
+
+	alerts, err := inv.ListExpiring(30)
+	if err != nil {
+		t.Fatalf("ListExpiring failed: %v", err)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 alerts, got %+v", alerts)
+	}
+	if alerts[0].Item.ID != 1003 || alerts[0].Kind != "expiry" ||
+		!alerts[0].Expired() {
+		t.Errorf("unexpected first alert: %+v", alerts[0])
+	}
+	if alerts[1].Item.ID != 1001 || alerts[1].Kind != "warranty" ||
+		alerts[1].Expired() {
+		t.Errorf("unexpected second alert: %+v", alerts[1])
+	}
+}
+
+func TestInventoryDB_CheckExpiry(t *testing.T) {
+	inv := setupExpiry(t)
+	defer inv.Close()
+
+	var buf bytes.Buffer
+	err := inv.CheckExpiry(&buf, 30)
+	if !errors.Is(err, inventory.ErrItemsExpired) {
+		t.Errorf("expected ErrItemsExpired, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "EXPIRED") {
+		t.Errorf("unexpected report:\n%s", buf.String())
+	}
+
+	if err := inv.DeleteItem(1003); err != nil {
+		t.Fatalf("DeleteItem failed: %v", err)
+	}
+	buf.Reset()
+	if err := inv.CheckExpiry(&buf, 30); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestExportCSVFiltered(t *testing.T) {
+	inv := setupExpiry(t)
+	defer inv.Close()
+
+	tmpfile := t.TempDir() + "/expiring.csv"
+	err := inv.ExportCSVFiltered(tmpfile, inventory.ItemFilter{
+		ExpiryTo: daysFromNow(30),
+	})
+	if err != nil {
+		t.Fatalf("ExportCSVFiltered failed: %v", err)
+	}
+
+	other := setupInventoryDB(t)
+	defer other.Close()
+	if err := other.ImportCSV(tmpfile); err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+	items, _ := other.ListAll()
+	if len(items) != 1 || items[0].ExpiryDate != daysFromNow(-2) {
+		t.Errorf("unexpected items after filtered export: %+v", items)
+	}
+}
+
+func TestExportJSONFiltered(t *testing.T) {
+	inv := setupExpiry(t)
+	defer inv.Close()
+
+	tmpfile := t.TempDir() + "/warranty.json"
+	err := inv.ExportJSONFiltered(tmpfile, inventory.ItemFilter{
+		WarrantyFrom: daysFromNow(0),
+	})
+	if err != nil {
+		t.Fatalf("ExportJSONFiltered failed: %v", err)
+	}
+
+	other := setupInventoryDB(t)
+	defer other.Close()
+	if err := other.ImportJSON(tmpfile); err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	items, _ := other.ListAll()
+	if len(items) != 1 || items[0].ID != 1001 {
+		t.Errorf("unexpected items after filtered export: %+v", items)
+	}
+}
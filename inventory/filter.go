@@ -0,0 +1,280 @@
+// filter.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Item filters for Inventory CLI
+// Builds the WHERE clause used by iterators, exporters and reports.
+//
+
+package inventory
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ItemFilter selects inventory items by field values and date ranges.
+//
+// Blank fields are ignored, so the zero value selects every item.
+// Text fields must match exactly. Date ranges are inclusive and
+// use DateLayout ("2006-01-02"); items without the date never match
+// a range on it. Other date formats are refused by the functions
+// taking a filter, as they would compare wrongly.
+//
+// Usage:
+//
+//	f := ItemFilter{Location: "Store 2", ExpiryTo: "2025-12-31"}
+//	iter, err := NewFilteredItemIterator(db, f)
+type ItemFilter struct {
+	Location string `json:"location,omitempty"`
+	Status   string `json:"status,omitempty"`
+
+	PurchasedFrom string `json:"purchased_from,omitempty"`
+	PurchasedTo   string `json:"purchased_to,omitempty"`
+	WarrantyFrom  string `json:"warranty_from,omitempty"`
+	WarrantyTo    string `json:"warranty_to,omitempty"`
+	ExpiryFrom    string `json:"expiry_from,omitempty"`
+	ExpiryTo      string `json:"expiry_to,omitempty"`
+}
+
+// Where returns the filter as a WHERE clause with its arguments,
+// ready for NewItemIterator().
+//
+// Returns an empty clause when the filter is blank.
+//
+// Example:
+//
+//	ItemFilter{Status: "Active", WarrantyTo: "2025-12-31"}.Where()
+//	// "WHERE status = ? AND warranty_until <= ?",
+//	// []interface{}{"Active", "2025-12-31"}
+func (f ItemFilter) Where() (string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+	add := func(cond, value string) {
+		if value != "" {
+			conds = append(conds, cond)
+			args = append(args, value)
+		}
+	}
+
+	add("location = ?", f.Location)
+	add("status = ?", f.Status)
+	add("purchase_date >= ?", f.PurchasedFrom)
+	add("purchase_date <= ?", f.PurchasedTo)
+	add("warranty_until >= ?", f.WarrantyFrom)
+	add("warranty_until <= ?", f.WarrantyTo)
+	add("expiry_date >= ?", f.ExpiryFrom)
+	add("expiry_date <= ?", f.ExpiryTo)
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// check verifies that the date bounds of the filter are blank or
+// in DateLayout format. Its errors match ErrInvalidItem.
+func (f ItemFilter) check() error {
+	dates := []struct{ name, value string }{
+		{"purchased_from", f.PurchasedFrom},
+		{"purchased_to", f.PurchasedTo},
+		{"warranty_from", f.WarrantyFrom},
+		{"warranty_to", f.WarrantyTo},
+		{"expiry_from", f.ExpiryFrom},
+		{"expiry_to", f.ExpiryTo},
+	}
+	for _, d := range dates {
+		if d.value == "" {
+			continue
+		}
+		if _, err := time.Parse(DateLayout, d.value); err != nil {
+			return invalidf("invalid filter date %s=%q, want %s",
+				d.name, d.value, DateLayout)
+		}
+	}
+	return nil
+}
+
+// ParseItemFilter builds an ItemFilter from "key=value" terms,
+// as given to a `--filter` command line option.
+//
+// Each expression may hold several terms separated by commas.
+// Keys are the JSON names of the ItemFilter fields.
+//
+// Usage:
+//
+//	f, err := ParseItemFilter("location=Lab,status=Active")
+//	f, err := ParseItemFilter("location=Lab", "expiry_to=2025-12-31")
+//
+// Errors are returned for malformed terms and unknown keys, and
+// for dates not in DateLayout format (matching ErrInvalidItem).
+func ParseItemFilter(exprs ...string) (ItemFilter, error) {
+	var f ItemFilter
+	fields := map[string]*string{
+		"location":       &f.Location,
+		"status":         &f.Status,
+		"purchased_from": &f.PurchasedFrom,
+		"purchased_to":   &f.PurchasedTo,
+		"warranty_from":  &f.WarrantyFrom,
+		"warranty_to":    &f.WarrantyTo,
+		"expiry_from":    &f.ExpiryFrom,
+		"expiry_to":      &f.ExpiryTo,
+	}
+
+	for _, expr := range exprs {
+		for _, term := range strings.Split(expr, ",") {
+			if strings.TrimSpace(term) == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(term, "=")
+			if !ok {
+				return ItemFilter{}, fmt.Errorf(
+					"invalid filter term %q, want key=value", term)
+			}
+			key = strings.ToLower(strings.TrimSpace(key))
+			field, ok := fields[key]
+			if !ok {
+				return ItemFilter{}, fmt.Errorf(
+					"unknown filter key %q", key)
+			}
+			*field = strings.TrimSpace(value)
+		}
+	}
+	if err := f.check(); err != nil {
+		return ItemFilter{}, err
+	}
+	return f, nil
+}
+
+// NewFilteredItemIterator returns an ItemIterator over the items
+// selected by the filter.
+//
+// Usage:
+//
+//	iter, err := NewFilteredItemIterator(db, ItemFilter{Status: "Active"})
+//	if err != nil {
+//	    // handle error
+//	}
+//	defer iter.Close()
+//
+// See NewItemIterator() for the iteration loop. Dates not in
+// DateLayout format are refused with an error matching
+// ErrInvalidItem.
+func NewFilteredItemIterator(
+	db *sql.DB, f ItemFilter) (*ItemIterator, error) {
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+	where, args := f.Where()
+	return NewItemIterator(db, where, args...)
+}
+
+// queryFiltered loads the items selected by the filter,
+// sorted by ID, using any Querier.
+func queryFiltered(db Querier, f ItemFilter) ([]Item, error) {
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+	where, args := f.Where()
+	rows, err := db.Query(`SELECT `+itemFields+` FROM inventory `+
+		where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("filter query failed: %v", err)
+	}
+	return scanAllItems(rows)
+}
+
+// ListFiltered returns the items selected by the filter,
+// sorted by ID.
+//
+// Usage:
+//
+//	items, err := ListFiltered(db, ItemFilter{Location: "Lab"})
+//
+// Notes:
+// - Loads all matching items into memory
+// - Use NewFilteredItemIterator() for large results
+func ListFiltered(db *sql.DB, f ItemFilter) ([]Item, error) {
+	iter, err := NewFilteredItemIterator(db, f)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var items []Item
+	for {
+		item, ok, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return items, nil
+		}
+		items = append(items, item)
+	}
+}
+
+// NewFilteredItemIterator wraps NewFilteredItemIterator.
+//
+// Usage:
+//
+//	iter, err := inv.NewFilteredItemIterator(filter)
+func (inv *InventoryDB) NewFilteredItemIterator(
+	f ItemFilter) (*ItemIterator, error) {
+	return NewFilteredItemIterator(inv.db, f)
+}
+
+// ListFiltered wraps ListFiltered.
+//
+// Usage:
+//
+//	items, err := inv.ListFiltered(filter)
+func (inv *InventoryDB) ListFiltered(f ItemFilter) ([]Item, error) {
+	return ListFiltered(inv.db, f)
+}
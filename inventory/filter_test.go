@@ -0,0 +1,148 @@
+// filter_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for item filters
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestItemFilter_Where(t *testing.T) {
+	where, args := inventory.ItemFilter{}.Where()
+	if where != "" || len(args) != 0 {
+		t.Errorf("expected empty clause, got %q %v", where, args)
+	}
+
+	where, args = inventory.ItemFilter{
+		Status: "Active", WarrantyTo: "2025-12-31",
+	}.Where()
+	want := "WHERE status = ? AND warranty_until <= ?"
+	if where != want || len(args) != 2 {
+		t.Errorf("unexpected clause %q %v", where, args)
+	}
+}
+
+func TestInventoryDB_NewFilteredItemIterator(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Location: "Store", ExpiryDate: "2025-01-31"},
+		{ID: 1002, Location: "Store", ExpiryDate: "2025-06-30"},
+		{ID: 1003, Location: "Lab", ExpiryDate: "2025-03-31"},
+		{ID: 1004, Location: "Store"},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	iter, err := inv.NewFilteredItemIterator(inventory.ItemFilter{
+		Location: "Store", ExpiryFrom: "2025-01-01",
+		ExpiryTo: "2025-03-31",
+	})
+	if err != nil {
+		t.Fatalf("NewFilteredItemIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	var ids []int
+	for {
+		item, ok, err := iter.Next()
+		if err != nil {
+			t.Fatalf("Iterator Next failed: %v", err)
+		}
+		if !ok {
+			break
+		}
+		ids = append(ids, item.ID)
+	}
+	if len(ids) != 1 || ids[0] != 1001 {
+		t.Errorf("unexpected filtered items: %v", ids)
+	}
+
+	// 2025-1-5 sorts after 2025-06-30 as text, so it is refused
+	bad := inventory.ItemFilter{ExpiryTo: "2025-1-5"}
+	if _, err := inv.NewFilteredItemIterator(bad); !errors.Is(err,
+		inventory.ErrInvalidItem) {
+		t.Errorf("expected ErrInvalidItem for bad date, got %v", err)
+	}
+	if _, err := inv.ListFiltered(bad); err == nil {
+		t.Error("expected error listing with bad date")
+	}
+}
+
+func TestParseItemFilter(t *testing.T) {
+	f, err := inventory.ParseItemFilter(
+		"location=Lab, status=Active", "expiry_to=2025-12-31")
+	if err != nil {
+		t.Fatalf("ParseItemFilter failed: %v", err)
+	}
+	want := inventory.ItemFilter{
+		Location: "Lab", Status: "Active", ExpiryTo: "2025-12-31",
+	}
+	if f != want {
+		t.Errorf("unexpected filter: %+v", f)
+	}
+
+	if _, err := inventory.ParseItemFilter("location"); err == nil {
+		t.Error("expected error for term without value")
+	}
+	if _, err := inventory.ParseItemFilter("colour=red"); err == nil {
+		t.Error("expected error for unknown key")
+	}
+	_, err = inventory.ParseItemFilter("expiry_to=2025-1-5")
+	if !errors.Is(err, inventory.ErrInvalidItem) {
+		t.Errorf("expected ErrInvalidItem for bad date, got %v", err)
+	}
+}
@@ -62,11 +62,33 @@ type Execer interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
 }
 
+// Querier defines something that can run SQL queries.
+// Both *sql.DB and *sql.Tx implement this.
+type Querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// ExecQuerier defines something that can both Exec and Query SQL.
+// Both *sql.DB and *sql.Tx implement this.
+//
+// Used by functions that must read rows before changing them,
+// so that the read and the write share one transaction.
+type ExecQuerier interface {
+	Execer
+	Querier
+}
+
 // InventoryDB wraps *sql.DB and provides safe transaction helpers.
 //
 // Users do not need to work with *sql.DB directly.
+//
+// Every change made through InventoryDB is recorded in the audit log
+// under the current actor, see WithActor() and WithContext().
 type InventoryDB struct {
-	db *sql.DB
+	db         *sql.DB
+	actor      string
+	diffFormat DiffFormat
 }
 
 // NewInventoryDB opens or creates the database and returns InventoryDB.
@@ -84,7 +106,23 @@ type InventoryDB struct {
 // - Table creation is idempotent
 func NewInventoryDB(dbFile string) *InventoryDB {
 	db := OpenDB(dbFile)
-	return &InventoryDB{db: db}
+	return &InventoryDB{db: db, diffFormat: FormatChanges}
+}
+
+// WithDiffFormat returns a copy of inv that records the fields
+// changed by EditItem, UpdateItem, BulkUpdate and EditItems with
+// format; nil records no changes. The default is FormatChanges().
+//
+// Usage:
+//
+//	quiet := inv.WithDiffFormat(nil)
+//	err := quiet.EditItem(item)
+//
+// inv itself is not changed.
+func (inv *InventoryDB) WithDiffFormat(format DiffFormat) *InventoryDB {
+	c := *inv
+	c.diffFormat = format
+	return &c
 }
 
 // WithTransaction executes the given function inside a transaction.
@@ -108,15 +146,41 @@ func NewInventoryDB(dbFile string) *InventoryDB {
 // Notes:
 // - Use for any group of changes that must be atomic
 // - If the DB fails, returns error
+// - Changes are audited under the operation name "transaction"
 func (inv *InventoryDB) WithTransaction(
 	fn func(tx Execer) error) error {
 
+	return inv.withTx("transaction", func(tx ExecQuerier) error {
+		return fn(tx)
+	})
+}
+
+// withTx is WithTransaction for functions that also need
+// to query inside the transaction.
+//
+// The op names the operation for access control and the audit log.
+// The actor must be allowed to perform op, see authorize().
+// The audit session is opened before fn() runs and closed before
+// commit, so the audit triggers attribute every change in fn() to
+// inv.actor.
+func (inv *InventoryDB) withTx(
+	op string, fn func(tx ExecQuerier) error) error {
+
 	tx, err := inv.db.Begin()
 	if err != nil {
 		return fmt.Errorf("begin tx failed: %v", err)
 	}
 
-	err = fn(tx)
+	err = authorize(tx, inv.actor, op)
+	if err == nil {
+		err = beginAuditSession(tx, inv.Actor(), op)
+	}
+	if err == nil {
+		err = fn(tx)
+	}
+	if err == nil {
+		err = endAuditSession(tx)
+	}
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -137,6 +201,10 @@ func (inv *InventoryDB) DB() *sql.DB {
 }
 
 // Close closes the underlying database connection.
+//
+// Copies made by WithActor(), WithContext() or WithDiffFormat()
+// share the connection,
+// closing any of them closes all.
 func (inv *InventoryDB) Close() error {
 	return inv.db.Close()
 }
@@ -147,7 +215,7 @@ func (inv *InventoryDB) Close() error {
 //
 //	err := inv.AppendItem(item)
 func (inv *InventoryDB) AppendItem(item Item) error {
-	return inv.WithTransaction(func(tx Execer) error {
+	return inv.withTx("append_item", func(tx ExecQuerier) error {
 		return AppendItem(tx, item)
 	})
 }
@@ -158,19 +226,21 @@ func (inv *InventoryDB) AppendItem(item Item) error {
 //
 //	err := inv.AddItem(item)
 func (inv *InventoryDB) AddItem(item Item) error {
-	return inv.WithTransaction(func(tx Execer) error {
+	return inv.withTx("add_item", func(tx ExecQuerier) error {
 		return AddItem(tx, item)
 	})
 }
 
-// EditItem wraps EditItem with automatic transaction.
+// EditItem wraps EditItemDiff with automatic transaction, so
+// the changed fields are recorded in the remarks (see
+// WithDiffFormat()).
 //
 // Usage:
 //
 //	err := inv.EditItem(item)
 func (inv *InventoryDB) EditItem(item Item) error {
-	return inv.WithTransaction(func(tx Execer) error {
-		return EditItem(tx, item)
+	return inv.withTx("edit_item", func(tx ExecQuerier) error {
+		return EditItemDiff(tx, item, inv.diffFormat)
 	})
 }
 
@@ -180,7 +250,7 @@ func (inv *InventoryDB) EditItem(item Item) error {
 //
 //	err := inv.AppendRemarksEntry(id, "log message")
 func (inv *InventoryDB) AppendRemarksEntry(id int, message string) error {
-	return inv.WithTransaction(func(tx Execer) error {
+	return inv.withTx("append_remarks_entry", func(tx ExecQuerier) error {
 		return AppendRemarksEntry(tx, id, message)
 	})
 }
@@ -191,7 +261,7 @@ func (inv *InventoryDB) AppendRemarksEntry(id int, message string) error {
 //
 //	err := inv.DeleteItem(id)
 func (inv *InventoryDB) DeleteItem(id int) error {
-	return inv.WithTransaction(func(tx Execer) error {
+	return inv.withTx("delete_item", func(tx ExecQuerier) error {
 		return DeleteItem(tx, id)
 	})
 }
@@ -202,7 +272,7 @@ func (inv *InventoryDB) DeleteItem(id int) error {
 //
 //	err := inv.ResetSequence()
 func (inv *InventoryDB) ResetSequence() error {
-	return inv.WithTransaction(func(tx Execer) error {
+	return inv.withTx("reset_sequence", func(tx ExecQuerier) error {
 		return ResetSequence(tx)
 	})
 }
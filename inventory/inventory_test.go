@@ -47,6 +47,7 @@ package inventory_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/boseji/bvl/inventory"
@@ -149,6 +150,48 @@ func TestInventoryDB_EditItem(t *testing.T) {
 	}
 }
 
+func TestInventoryDB_EditItem_DiffFormat(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	item := inventory.Item{ID: 1001, Description: "Switch", Location: "Rack 2"}
+	if err := inv.AppendItem(item); err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+
+	item.Location = "Rack 5"
+	if err := inv.EditItem(item); err != nil {
+		t.Fatalf("EditItem failed: %v", err)
+	}
+	got, _ := inv.GetItemByID(1001)
+	if !strings.HasSuffix(got.Remarks, "] location: Rack 2 → Rack 5") {
+		t.Errorf("change not recorded: %q", got.Remarks)
+	}
+
+	// A copy without diffs leaves inv as it was
+	item.Location = "Store"
+	if err := inv.WithDiffFormat(nil).EditItem(item); err != nil {
+		t.Fatalf("EditItem failed: %v", err)
+	}
+	item.Location = "Lab"
+	err := inv.WithTransaction(func(tx inventory.Execer) error {
+		return inventory.EditItem(tx, item)
+	})
+	if err != nil {
+		t.Fatalf("EditItem in transaction failed: %v", err)
+	}
+	item.Location = "Rack 1"
+	if err := inv.EditItem(item); err != nil {
+		t.Fatalf("EditItem failed: %v", err)
+	}
+	got, _ = inv.GetItemByID(1001)
+	if strings.Contains(got.Remarks, "→ Store") ||
+		strings.Contains(got.Remarks, "→ Lab") ||
+		!strings.HasSuffix(got.Remarks, "] location: Lab → Rack 1") {
+		t.Errorf("unexpected remarks: %q", got.Remarks)
+	}
+}
+
 func TestInventoryDB_EditItem_NotFound(t *testing.T) {
 	inv := setupInventoryDB(t)
 	defer inv.Close()
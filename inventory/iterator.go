@@ -131,7 +131,7 @@ func NewItemIterator(
 ) (*ItemIterator, error) {
 
 	query := `
-        SELECT id, description, location, status, remarks
+        SELECT ` + itemFields + `
         FROM inventory `
 	if whereClause != "" {
 		query += whereClause
@@ -182,9 +182,7 @@ func NewItemIterator(
 func (it *ItemIterator) Next() (Item, bool, error) {
 	var item Item
 	if it.rows.Next() {
-		err := it.rows.Scan(
-			&item.ID, &item.Description, &item.Location,
-			&item.Status, &item.Remarks)
+		item, err := scanItem(it.rows)
 		if err != nil {
 			return item, false, fmt.Errorf("iterator scan failed: %v", err)
 		}
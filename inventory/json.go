@@ -53,12 +53,66 @@ package inventory
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+
+	"github.com/boseji/bsg/gen"
 )
 
+// JSONFormatVersion is the version of the JSON export envelope
+// written by ExportJSONEnvelope(). Importers refuse newer versions.
+const JSONFormatVersion = 1
+
+// JSONEnvelope wraps exported items with metadata:
+//
+//	{
+//	  "format_version": 1,
+//	  "exported_at": "2025-06-21 15:00",
+//	  "schema_version": 9,
+//	  "sequence": 1042,
+//	  "count": 42,
+//	  "checksum": "sha256:9f86d0...",
+//	  "items": [ ... ]
+//	}
+//
+// Fields:
+//
+//	SchemaVersion - SchemaVersion() of the exporting database
+//	Sequence      - last ID handed out by the exporting database
+//	Count         - number of items, to detect truncated files
+//	Checksum      - SHA-256 of the compact items array
+type JSONEnvelope struct {
+	FormatVersion int             `json:"format_version"`
+	ExportedAt    string          `json:"exported_at"`
+	SchemaVersion int             `json:"schema_version"`
+	Sequence      int             `json:"sequence"`
+	Count         int             `json:"count"`
+	Checksum      string          `json:"checksum"`
+	Items         json.RawMessage `json:"items"`
+}
+
+// SchemaVersion returns the version of the inventory table
+// schema: 1 for the first release, plus one for every column
+// added since (see itemColumnUpgrades).
+func SchemaVersion() int {
+	return 1 + len(itemColumnUpgrades)
+}
+
+// itemsChecksum returns the checksum of a JSON items array,
+// ignoring whitespace.
+func itemsChecksum(items json.RawMessage) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, items); err != nil {
+		return "", fmt.Errorf("invalid items: %v", err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
 // ExportJSON writes all inventory records to a JSON file.
 //
 // The output JSON is an array of Item objects:
@@ -88,7 +142,130 @@ func ExportJSON(db *sql.DB, filename string) error {
 		return fmt.Errorf("export json failed: %v", err)
 	}
 
-	data, err := json.MarshalIndent(items, "", "  ")
+	return writeJSONItems(items, filename)
+}
+
+// ExportJSONFiltered writes the inventory records selected
+// by the filter to a JSON file.
+//
+// Usage:
+//
+//	f := ItemFilter{ExpiryTo: "2025-12-31"}
+//	err := ExportJSONFiltered(db, "expiring.json", f)
+//
+// The output format is the same as ExportJSON().
+func ExportJSONFiltered(db *sql.DB, filename string, f ItemFilter) error {
+	items, err := ListFiltered(db, f)
+	if err != nil {
+		return fmt.Errorf("export json failed: %v", err)
+	}
+
+	return writeJSONItems(items, filename)
+}
+
+// ExportJSONEnvelope writes the inventory records selected by
+// the filter to a JSON file wrapped in a JSONEnvelope, so an
+// importer can check the file is complete and restore the
+// ID sequence.
+//
+// Usage:
+//
+//	err := ExportJSONEnvelope(db, "inventory.json", ItemFilter{})
+//
+// ImportJSON() reads both this and the bare array form.
+func ExportJSONEnvelope(db *sql.DB, filename string, f ItemFilter) error {
+	items, err := ListFiltered(db, f)
+	if err != nil {
+		return fmt.Errorf("export json failed: %v", err)
+	}
+	if items == nil {
+		items = []Item{}
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("marshal json failed: %v", err)
+	}
+
+	env := JSONEnvelope{
+		FormatVersion: JSONFormatVersion,
+		ExportedAt:    gen.BST().Format("2006-01-02 15:04"),
+		SchemaVersion: SchemaVersion(),
+		Count:         len(items),
+		Items:         data,
+	}
+	err = db.QueryRow(`
+        SELECT COALESCE(MAX(seq), 0) FROM sqlite_sequence
+        WHERE name = 'inventory'`).Scan(&env.Sequence)
+	if err != nil {
+		return fmt.Errorf("sequence query failed: %v", err)
+	}
+	if env.Checksum, err = itemsChecksum(data); err != nil {
+		return err
+	}
+	return writeJSONFile(env, filename)
+}
+
+// readJSONEnvelope checks an envelope and returns its items.
+func readJSONEnvelope(data []byte) (JSONEnvelope, []Item, error) {
+	var env JSONEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return env, nil, fmt.Errorf("unmarshal json failed: %v", err)
+	}
+	if env.FormatVersion < 1 || env.FormatVersion > JSONFormatVersion {
+		return env, nil, fmt.Errorf("unsupported format version %d",
+			env.FormatVersion)
+	}
+	if env.SchemaVersion > SchemaVersion() {
+		return env, nil, fmt.Errorf("schema version %d is newer than %d",
+			env.SchemaVersion, SchemaVersion())
+	}
+	sum, err := itemsChecksum(env.Items)
+	if err != nil {
+		return env, nil, err
+	}
+	if sum != env.Checksum {
+		return env, nil, fmt.Errorf("checksum mismatch")
+	}
+
+	var items []Item
+	if err := json.Unmarshal(env.Items, &items); err != nil {
+		return env, nil, fmt.Errorf("unmarshal json failed: %v", err)
+	}
+	if len(items) != env.Count {
+		return env, nil, fmt.Errorf("count mismatch: %d items, expected %d",
+			len(items), env.Count)
+	}
+	return env, items, nil
+}
+
+// decodeJSONItems parses a bare JSON array of items or a checked
+// JSONEnvelope (see readJSONEnvelope()). The sequence is the
+// envelope sequence, 0 for a bare array.
+func decodeJSONItems(data []byte) ([]Item, int, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 &&
+		trimmed[0] == '{' {
+		env, items, err := readJSONEnvelope(trimmed)
+		if err != nil {
+			return nil, 0, err
+		}
+		return items, env.Sequence, nil
+	}
+
+	var items []Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, 0, fmt.Errorf("unmarshal json failed: %v", err)
+	}
+	return items, 0, nil
+}
+
+// writeJSONItems writes items to a file as an indented JSON array.
+func writeJSONItems(items []Item, filename string) error {
+	return writeJSONFile(items, filename)
+}
+
+// writeJSONFile writes any value to a file as indented JSON.
+func writeJSONFile(v interface{}, filename string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal json failed: %v", err)
 	}
@@ -127,6 +304,25 @@ func ImportJSON(exec Execer, filename string) error {
 	return ImportJSONFromBytes(exec, data)
 }
 
+// ReadJSONItems parses a JSON file written by ExportJSON() or
+// ExportJSONEnvelope() into items, without touching the database.
+// An envelope is checked as ImportJSONFromBytes() does.
+//
+// Usage:
+//
+//	items, err := ReadJSONItems("inventory.json")
+//
+// Returns error on file error or parse error.
+func ReadJSONItems(filename string) ([]Item, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read json failed: %v", err)
+	}
+
+	items, _, err := decodeJSONItems(data)
+	return items, err
+}
+
 // ViewJSON pretty prints the content of a JSON file to stdout.
 //
 // Usage:
@@ -208,12 +404,19 @@ func ImportJSONFromString(exec Execer, jsonString string) error {
 	return ImportJSONFromBytes(exec, []byte(jsonString))
 }
 
-// ImportJSONFromBytes helper
+// ImportJSONFromBytes imports a bare JSON array of items or
+// a JSONEnvelope.
+//
+// An envelope is checked before anything is imported: the format
+// and schema versions must be supported and the count and checksum
+// must match. After the import the ID sequence is raised to the
+// envelope sequence, so new items do not reuse IDs handed out
+// by the exporting database. The sequence row is created if the
+// database has none.
 func ImportJSONFromBytes(exec Execer, data []byte) error {
-	var items []Item
-
-	if err := json.Unmarshal(data, &items); err != nil {
-		return fmt.Errorf("unmarshal json failed: %v", err)
+	items, sequence, err := decodeJSONItems(data)
+	if err != nil {
+		return err
 	}
 
 	for i, item := range items {
@@ -222,6 +425,22 @@ func ImportJSONFromBytes(exec Execer, data []byte) error {
 		}
 	}
 
+	if sequence > 0 {
+		_, err := exec.Exec(`
+            INSERT INTO sqlite_sequence (name, seq)
+            SELECT 'inventory', ?
+            WHERE NOT EXISTS (
+                SELECT 1 FROM sqlite_sequence WHERE name = 'inventory'
+            )`, sequence)
+		if err == nil {
+			_, err = exec.Exec(`
+                UPDATE sqlite_sequence SET seq = MAX(seq, ?)
+                WHERE name = 'inventory'`, sequence)
+		}
+		if err != nil {
+			return fmt.Errorf("restore sequence failed: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -281,6 +500,26 @@ func (inv *InventoryDB) ExportJSON(filename string) error {
 	return ExportJSON(inv.db, filename)
 }
 
+// InventoryDB method: ExportJSONFiltered
+//
+// Usage:
+//
+//	err := inv.ExportJSONFiltered("expiring.json", filter)
+func (inv *InventoryDB) ExportJSONFiltered(
+	filename string, f ItemFilter) error {
+	return ExportJSONFiltered(inv.db, filename, f)
+}
+
+// InventoryDB method: ExportJSONEnvelope
+//
+// Usage:
+//
+//	err := inv.ExportJSONEnvelope("inventory.json", ItemFilter{})
+func (inv *InventoryDB) ExportJSONEnvelope(
+	filename string, f ItemFilter) error {
+	return ExportJSONEnvelope(inv.db, filename, f)
+}
+
 // InventoryDB method: ImportJSON
 //
 // Usage:
@@ -289,7 +528,7 @@ func (inv *InventoryDB) ExportJSON(filename string) error {
 //
 // Runs inside transaction.
 func (inv *InventoryDB) ImportJSON(filename string) error {
-	return inv.WithTransaction(func(tx Execer) error {
+	return inv.withTx("import_json", func(tx ExecQuerier) error {
 		return ImportJSON(tx, filename)
 	})
 }
@@ -311,7 +550,7 @@ func (inv *InventoryDB) ExportJSONToString() (string, error) {
 //
 // Runs inside transaction.
 func (inv *InventoryDB) ImportJSONFromString(jsonString string) error {
-	return inv.WithTransaction(func(tx Execer) error {
+	return inv.withTx("import_json_from_string", func(tx ExecQuerier) error {
 		return ImportJSONFromString(tx, jsonString)
 	})
 }
@@ -51,8 +51,10 @@
 package inventory_test
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/boseji/bvl/inventory"
@@ -247,3 +249,109 @@ func TestExportJSON_BadPath(t *testing.T) {
 		t.Fatalf("expected error for bad path")
 	}
 }
+
+func TestJSONEnvelope(t *testing.T) {
+	inv := setupJSONTestDB(t)
+	defer inv.Close()
+
+	for _, d := range []string{"UPS", "Router", "Switch"} {
+		if err := inv.AddItem(inventory.Item{Description: d}); err != nil {
+			t.Fatalf("AddItem failed: %v", err)
+		}
+	}
+	items, _ := inv.ListAll()
+	last := items[len(items)-1].ID
+	if err := inv.DeleteItem(last); err != nil {
+		t.Fatalf("DeleteItem failed: %v", err)
+	}
+
+	tmpfile := filepath.Join(t.TempDir(), "envelope.json")
+	err := inv.ExportJSONEnvelope(tmpfile, inventory.ItemFilter{})
+	if err != nil {
+		t.Fatalf("ExportJSONEnvelope failed: %v", err)
+	}
+	data, err := os.ReadFile(tmpfile)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	var env inventory.JSONEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if env.FormatVersion != inventory.JSONFormatVersion ||
+		env.SchemaVersion != inventory.SchemaVersion() ||
+		env.Count != 2 || env.Sequence != last ||
+		!strings.HasPrefix(env.Checksum, "sha256:") {
+		t.Errorf("unexpected envelope: %+v", env)
+	}
+
+	other := setupJSONTestDB(t)
+	defer other.Close()
+	if err := other.ImportJSON(tmpfile); err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if got, _ := other.ListAll(); len(got) != 2 {
+		t.Fatalf("expected 2 items after import, got %d", len(got))
+	}
+	if err := other.AddItem(inventory.Item{Description: "PDU"}); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	got, _ := other.ListAll()
+	if id := got[len(got)-1].ID; id <= last {
+		t.Errorf("sequence not restored: new ID %d, last %d", id, last)
+	}
+
+	bad := strings.Replace(string(data), "Router", "Modem", 1)
+	if err := other.ImportJSONFromString(bad); err == nil ||
+		!strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected checksum error, got %v", err)
+	}
+	bad = strings.Replace(string(data), `"count": 2`, `"count": 3`, 1)
+	if err := other.ImportJSONFromString(bad); err == nil ||
+		!strings.Contains(err.Error(), "count") {
+		t.Errorf("expected count error, got %v", err)
+	}
+	bad = strings.Replace(string(data), `"format_version": 1`,
+		`"format_version": 9`, 1)
+	if err := other.ImportJSONFromString(bad); err == nil {
+		t.Error("expected error for unsupported format version")
+	}
+	if got, _ := other.ListAll(); len(got) != 3 {
+		t.Errorf("rejected envelopes changed the table: %d items", len(got))
+	}
+
+	// Snapshots and diffs read envelopes too
+	snap, err := inventory.ReadSnapshot(tmpfile)
+	if err != nil || len(snap) != 2 {
+		t.Errorf("ReadSnapshot of envelope failed: %d %v", len(snap), err)
+	}
+	d, err := inventory.DiffFiles(tmpfile, tmpfile)
+	if err != nil || len(d.Added)+len(d.Removed)+len(d.Modified) != 0 {
+		t.Errorf("DiffFiles of envelope failed: %+v %v", d, err)
+	}
+
+	// A database without a sequence row gets one, even when
+	// the envelope holds no items
+	empty := filepath.Join(t.TempDir(), "empty.json")
+	err = inv.ExportJSONEnvelope(empty,
+		inventory.ItemFilter{Location: "Nowhere"})
+	if err != nil {
+		t.Fatalf("ExportJSONEnvelope failed: %v", err)
+	}
+	fresh := setupJSONTestDB(t)
+	defer fresh.Close()
+	_, err = fresh.DB().Exec(`DELETE FROM sqlite_sequence`)
+	if err != nil {
+		t.Fatalf("clear sequence failed: %v", err)
+	}
+	if err := fresh.ImportJSON(empty); err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if err := fresh.AddItem(inventory.Item{Description: "PDU"}); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	got, _ = fresh.ListAll()
+	if id := got[len(got)-1].ID; id <= last {
+		t.Errorf("sequence not created: new ID %d, last %d", id, last)
+	}
+}
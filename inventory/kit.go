@@ -0,0 +1,680 @@
+// kit.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Kits and assemblies for Inventory CLI
+// An item may belong to a parent item (a kit), e.g. a server rack kit
+// holding a UPS, a PDU and cables, each with its own ID.
+//
+
+package inventory
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrKitCycle is returned when making an item a component
+// of itself, directly or through other kits.
+var ErrKitCycle = errors.New("kit cycle detected")
+
+// installKitTriggers (re)creates the triggers that reject a
+// parent_id making an item its own parent, directly or through
+// other kits.
+//
+// The guard runs on every insert and parent change, so AddItem,
+// AppendItem, Revert and all imports refuse cycles the same way
+// SetParent does. Called by OpenDB.
+func installKitTriggers(db *sql.DB) error {
+	guard := func(when string) string {
+		return `BEFORE ` + when + ` ON inventory
+        WHEN NEW.parent_id IS NOT NULL AND (NEW.parent_id = NEW.id
+            OR EXISTS (
+                WITH RECURSIVE ancestors(id) AS (
+                    SELECT NEW.parent_id
+                    UNION
+                    SELECT i.parent_id FROM inventory i
+                    JOIN ancestors a ON i.id = a.id
+                    WHERE i.parent_id IS NOT NULL
+                )
+                SELECT 1 FROM ancestors WHERE id = NEW.id))
+        BEGIN
+            SELECT RAISE(ABORT, '` + ErrKitCycle.Error() + `');
+        END;`
+	}
+	triggers := map[string]string{
+		"insert": guard("INSERT"),
+		"update": guard("UPDATE OF id, parent_id"),
+	}
+	for _, name := range []string{"insert", "update"} {
+		_, err := db.Exec("DROP TRIGGER IF EXISTS inventory_kit_" + name)
+		if err == nil {
+			_, err = db.Exec("CREATE TRIGGER inventory_kit_" + name +
+				" " + triggers[name])
+		}
+		if err != nil {
+			return fmt.Errorf("kit trigger failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// KitNode is an item together with its components.
+//
+// The JSON form is the item's own fields plus a nested
+// "components" array:
+//
+//	{
+//	  "id": 1001, "description": "Server rack kit", ...,
+//	  "components": [
+//	    { "id": 1002, "description": "UPS 3KVA", "parent_id": 1001 },
+//	    { "id": 1003, "description": "PDU", "parent_id": 1001 }
+//	  ]
+//	}
+type KitNode struct {
+	Item
+	Components []KitNode `json:"components,omitempty"`
+}
+
+// KitChange describes a move or status change applied to a kit
+// by UpdateKit().
+//
+// Fields:
+//
+//	Location         - new location, empty to keep the current one
+//	Status           - new status, empty to keep the current one
+//	Remark           - remarks entry for the kit itself
+//	Cascade          - also apply the change to all components
+//	ComponentRemarks - remarks entry per component ID
+//
+// If Remark is empty a message describing the change is logged.
+// Components without an entry in ComponentRemarks get the kit's
+// remark followed by "(kit <id>)".
+type KitChange struct {
+	Location         string
+	Status           string
+	Remark           string
+	Cascade          bool
+	ComponentRemarks map[int]string
+}
+
+// SetParent makes childID a component of the kit parentID.
+//
+// Use parentID = 0 to detach the item from its kit.
+//
+// Usage:
+//
+//	err := SetParent(tx, 1002, 1001) // UPS 1002 is part of kit 1001
+//
+// The change is logged in the remarks of the component:
+//
+//	[2025-06-21 15:00] added to kit 1001
+//
+// Notes:
+// - Both items must exist
+// - Returns ErrKitCycle if parentID is childID or one of its components
+// - Works with both *sql.DB and *sql.Tx.
+func SetParent(exec ExecQuerier, childID, parentID int) error {
+	child, err := GetItemByID(exec, childID)
+	if err != nil {
+		return fmt.Errorf("set parent failed: %v", err)
+	}
+
+	if parentID != 0 {
+		if _, err := GetItemByID(exec, parentID); err != nil {
+			return fmt.Errorf("set parent failed: %v", err)
+		}
+
+		// Walk up from the new parent, a cycle exists
+		// if the child is found among its ancestors.
+		var count int
+		err = exec.QueryRow(`
+            WITH RECURSIVE ancestors(id) AS (
+                SELECT ?
+                UNION
+                SELECT i.parent_id FROM inventory i
+                JOIN ancestors a ON i.id = a.id
+                WHERE i.parent_id IS NOT NULL
+            )
+            SELECT COUNT(*) FROM ancestors WHERE id = ?`,
+			parentID, childID).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("kit cycle check failed: %v", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("set parent %d on %d failed: %w",
+				parentID, childID, ErrKitCycle)
+		}
+	}
+
+	if child.ParentID == parentID {
+		return nil
+	}
+
+	_, err = exec.Exec(`
+        UPDATE inventory
+        SET parent_id = NULLIF(?, 0)
+        WHERE id = ?`, parentID, childID)
+	if err != nil {
+		return fmt.Errorf("set parent failed: %v", err)
+	}
+
+	var msg string
+	switch {
+	case child.ParentID == 0:
+		msg = fmt.Sprintf("added to kit %d", parentID)
+	case parentID == 0:
+		msg = fmt.Sprintf("removed from kit %d", child.ParentID)
+	default:
+		msg = fmt.Sprintf("moved from kit %d to kit %d",
+			child.ParentID, parentID)
+	}
+	return AppendRemarksEntry(exec, childID, msg)
+}
+
+// ListComponents returns the direct components of a kit,
+// sorted by ID.
+//
+// Usage:
+//
+//	parts, err := ListComponents(db, 1001)
+//
+// Notes:
+// - Returns an empty slice if the item has no components
+// - Use GetKitTree() to include components of components
+// - Works with both *sql.DB and *sql.Tx.
+func ListComponents(db Querier, parentID int) ([]Item, error) {
+	rows, err := db.Query(`
+        SELECT `+itemFields+`
+        FROM inventory
+        WHERE parent_id = ?
+        ORDER BY id`, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("components query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var items []Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetKitTree returns the item with the given ID and all of its
+// components, recursively.
+//
+// Usage:
+//
+//	kit, err := GetKitTree(db, 1001)
+//	if err != nil {
+//	    // handle error
+//	}
+//	fmt.Print(kit.Tree())
+//
+// Notes:
+// - An item without components is returned as a single node
+// - Works with both *sql.DB and *sql.Tx.
+func GetKitTree(db Querier, id int) (KitNode, error) {
+	rows, err := db.Query(`
+        WITH RECURSIVE kit(id) AS (
+            SELECT ?
+            UNION
+            SELECT i.id FROM inventory i
+            JOIN kit k ON i.parent_id = k.id
+        )
+        SELECT `+itemFields+`
+        FROM inventory
+        WHERE id IN (SELECT id FROM kit)
+        ORDER BY id`, id)
+	if err != nil {
+		return KitNode{}, fmt.Errorf("kit query failed: %v", err)
+	}
+	items, err := scanAllItems(rows)
+	if err != nil {
+		return KitNode{}, err
+	}
+
+	for _, item := range items {
+		if item.ID == id {
+			return buildKitNode(item, groupByParent(items),
+				map[int]bool{}), nil
+		}
+	}
+	return KitNode{}, fmt.Errorf("item %d not found", id)
+}
+
+// ListKits returns the whole inventory as a forest of kits.
+//
+// Every item without a parent (or whose parent no longer exists)
+// is a root; components are nested below their kits. Items caught
+// in a parent cycle (left by a database written before cycles were
+// rejected) are listed as roots too, starting from the lowest ID.
+//
+// Usage:
+//
+//	kits, err := ListKits(db)
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListKits(db Querier) ([]KitNode, error) {
+	rows, err := db.Query(`
+        SELECT ` + itemFields + `
+        FROM inventory ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %v", err)
+	}
+	items, err := scanAllItems(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	exists := make(map[int]bool, len(items))
+	for _, item := range items {
+		exists[item.ID] = true
+	}
+
+	children := groupByParent(items)
+	visited := map[int]bool{}
+	var kits []KitNode
+	for _, item := range items {
+		if item.ParentID == 0 || !exists[item.ParentID] {
+			kits = append(kits, buildKitNode(item, children, visited))
+		}
+	}
+	// Items in a parent cycle have no root; list each cycle
+	// from its lowest ID so none of them is lost.
+	for _, item := range items {
+		if !visited[item.ID] {
+			kits = append(kits, buildKitNode(item, children, visited))
+		}
+	}
+	return kits, nil
+}
+
+// UpdateKit moves a kit or changes its status, optionally
+// cascading the change to all of its components.
+//
+// Usage:
+//
+//	err := UpdateKit(tx, 1001, KitChange{
+//	    Location: "Rack 5",
+//	    Remark:   "moved to new rack",
+//	    Cascade:  true,
+//	    ComponentRemarks: map[int]string{
+//	        1004: "cables replaced during move",
+//	    },
+//	})
+//
+// Every updated item gets a remarks entry, see KitChange.
+//
+// Notes:
+// - At least one of Location or Status must be set
+// - Works with both *sql.DB and *sql.Tx.
+func UpdateKit(exec ExecQuerier, id int, change KitChange) error {
+	if change.Location == "" && change.Status == "" {
+		return fmt.Errorf("kit change has no location or status")
+	}
+
+	ids := []int{id}
+	if change.Cascade {
+		kit, err := GetKitTree(exec, id)
+		if err != nil {
+			return fmt.Errorf("update kit failed: %v", err)
+		}
+		ids = kit.componentIDs(ids[:0])
+	} else if _, err := GetItemByID(exec, id); err != nil {
+		return fmt.Errorf("update kit failed: %v", err)
+	}
+
+	remark := change.Remark
+	if remark == "" {
+		remark = change.describe()
+	}
+
+	for _, cid := range ids {
+		_, err := exec.Exec(`
+            UPDATE inventory
+            SET location = COALESCE(NULLIF(?, ''), location),
+                status = COALESCE(NULLIF(?, ''), status)
+            WHERE id = ?`,
+			change.Location, change.Status, cid)
+		if err != nil {
+			return fmt.Errorf("update kit item %d failed: %v", cid, err)
+		}
+
+		msg := remark
+		if cid != id {
+			msg = change.ComponentRemarks[cid]
+			if msg == "" {
+				msg = fmt.Sprintf("%s (kit %d)", remark, id)
+			}
+		}
+		if err := AppendRemarksEntry(exec, cid, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// describe returns the default remarks entry for the change.
+func (change KitChange) describe() string {
+	var parts []string
+	if change.Location != "" {
+		parts = append(parts, "moved to "+change.Location)
+	}
+	if change.Status != "" {
+		parts = append(parts, "status set to "+change.Status)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// componentIDs appends the ID of this node and of all
+// its components (depth first) to ids.
+func (n KitNode) componentIDs(ids []int) []int {
+	ids = append(ids, n.ID)
+	for _, c := range n.Components {
+		ids = c.componentIDs(ids)
+	}
+	return ids
+}
+
+// Tree returns a text tree view of the kit.
+//
+// Example output:
+//
+//	1001 Server rack kit [Rack 1] (Operational)
+//	├── 1002 UPS 3KVA [Rack 1] (Operational)
+//	│   └── 1005 Battery pack [Rack 1] (Operational)
+//	└── 1003 PDU [Rack 1] (Operational)
+func (n KitNode) Tree() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s [%s] (%s)\n",
+		n.ID, n.Description, n.Location, n.Status)
+	n.writeComponents(&b, "")
+	return b.String()
+}
+
+func (n KitNode) writeComponents(b *strings.Builder, indent string) {
+	for i, c := range n.Components {
+		branch, next := "├── ", "│   "
+		if i == len(n.Components)-1 {
+			branch, next = "└── ", "    "
+		}
+		fmt.Fprintf(b, "%s%s%d %s [%s] (%s)\n", indent, branch,
+			c.ID, c.Description, c.Location, c.Status)
+		c.writeComponents(b, indent+next)
+	}
+}
+
+// scanAllItems reads all rows selected with itemFields
+// and closes them.
+func scanAllItems(rows *sql.Rows) ([]Item, error) {
+	defer rows.Close()
+
+	var items []Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// groupByParent maps each parent ID to its components.
+func groupByParent(items []Item) map[int][]Item {
+	children := map[int][]Item{}
+	for _, item := range items {
+		if item.ParentID != 0 {
+			children[item.ParentID] = append(
+				children[item.ParentID], item)
+		}
+	}
+	return children
+}
+
+// buildKitNode nests the components below item.
+// The visited set stops runaway recursion on corrupt data.
+func buildKitNode(
+	item Item, children map[int][]Item, visited map[int]bool,
+) KitNode {
+	node := KitNode{Item: item}
+	visited[item.ID] = true
+	for _, c := range children[item.ID] {
+		if visited[c.ID] {
+			continue
+		}
+		node.Components = append(node.Components,
+			buildKitNode(c, children, visited))
+	}
+	return node
+}
+
+// ExportKitsJSON writes the inventory to a JSON file as nested kits.
+//
+// The output JSON is an array of KitNode objects, see ListKits().
+//
+// Usage:
+//
+//	err := ExportKitsJSON(db, "kits.json")
+//
+// Errors:
+//   - returns error if database query fails
+//   - returns error if JSON marshal fails
+//   - returns error if file cannot be written (permission, path)
+func ExportKitsJSON(db *sql.DB, filename string) error {
+	kits, err := ListKits(db)
+	if err != nil {
+		return fmt.Errorf("export kits json failed: %v", err)
+	}
+
+	data, err := json.MarshalIndent(kits, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal json failed: %v", err)
+	}
+
+	err = os.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("write json failed: %v", err)
+	}
+
+	return nil
+}
+
+// ImportKitsJSON reads nested kits from a JSON file and imports them.
+//
+// Existing records with matching IDs will be replaced.
+//
+// Usage:
+//
+//	err := ImportKitsJSON(exec, "kits.json")
+//
+// Errors:
+//   - returns error if file read fails
+//   - see ImportKitsJSONFromBytes()
+func ImportKitsJSON(exec Execer, filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("read json failed: %v", err)
+	}
+
+	return ImportKitsJSONFromBytes(exec, data)
+}
+
+// ImportKitsJSONFromBytes imports nested kits from JSON data.
+//
+// The parent of each component is taken from its position
+// in the tree; any "parent_id" in the data is ignored,
+// except on the top-level entries.
+//
+// Errors:
+//   - returns error if JSON unmarshal fails
+//   - returns error if an entry has no ID or an ID repeats
+//   - returns error if individual Insert/Replace fails
+func ImportKitsJSONFromBytes(exec Execer, data []byte) error {
+	var kits []KitNode
+
+	if err := json.Unmarshal(data, &kits); err != nil {
+		return fmt.Errorf("unmarshal json failed: %v", err)
+	}
+
+	var items []Item
+	for _, kit := range kits {
+		items = kit.flatten(items, kit.ParentID)
+	}
+
+	seen := make(map[int]bool, len(items))
+	for _, item := range items {
+		if item.ID == 0 {
+			return fmt.Errorf("kit item %q has no id", item.Description)
+		}
+		if seen[item.ID] {
+			return fmt.Errorf("duplicate id %d in kit json", item.ID)
+		}
+		seen[item.ID] = true
+	}
+
+	for i, item := range items {
+		if err := AppendItem(exec, item); err != nil {
+			return fmt.Errorf("import item %d failed: %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+// flatten appends the node and its components to items,
+// setting each parent ID from the tree structure.
+func (n KitNode) flatten(items []Item, parentID int) []Item {
+	item := n.Item
+	item.ParentID = parentID
+	items = append(items, item)
+	for _, c := range n.Components {
+		items = c.flatten(items, n.ID)
+	}
+	return items
+}
+
+// SetParent wraps SetParent with automatic transaction.
+//
+// Usage:
+//
+//	err := inv.SetParent(childID, parentID)
+func (inv *InventoryDB) SetParent(childID, parentID int) error {
+	return inv.withTx("set_parent", func(tx ExecQuerier) error {
+		return SetParent(tx, childID, parentID)
+	})
+}
+
+// ListComponents wraps ListComponents.
+//
+// Usage:
+//
+//	parts, err := inv.ListComponents(kitID)
+func (inv *InventoryDB) ListComponents(parentID int) ([]Item, error) {
+	return ListComponents(inv.db, parentID)
+}
+
+// GetKitTree wraps GetKitTree.
+//
+// Usage:
+//
+//	kit, err := inv.GetKitTree(kitID)
+func (inv *InventoryDB) GetKitTree(id int) (KitNode, error) {
+	return GetKitTree(inv.db, id)
+}
+
+// ListKits wraps ListKits.
+//
+// Usage:
+//
+//	kits, err := inv.ListKits()
+func (inv *InventoryDB) ListKits() ([]KitNode, error) {
+	return ListKits(inv.db)
+}
+
+// UpdateKit wraps UpdateKit with automatic transaction.
+//
+// Either the kit and all its components are updated, or none.
+//
+// Usage:
+//
+//	err := inv.UpdateKit(kitID, change)
+func (inv *InventoryDB) UpdateKit(id int, change KitChange) error {
+	return inv.withTx("update_kit", func(tx ExecQuerier) error {
+		return UpdateKit(tx, id, change)
+	})
+}
+
+// InventoryDB method: ExportKitsJSON
+//
+// Usage:
+//
+//	err := inv.ExportKitsJSON("kits.json")
+func (inv *InventoryDB) ExportKitsJSON(filename string) error {
+	return ExportKitsJSON(inv.db, filename)
+}
+
+// InventoryDB method: ImportKitsJSON
+//
+// Usage:
+//
+//	err := inv.ImportKitsJSON("kits.json")
+//
+// Runs inside transaction.
+func (inv *InventoryDB) ImportKitsJSON(filename string) error {
+	return inv.withTx("import_kits_json", func(tx ExecQuerier) error {
+		return ImportKitsJSON(tx, filename)
+	})
+}
@@ -0,0 +1,363 @@
+// kit_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for kits and assemblies
+// Uses in-memory SQLite DB and temp JSON files
+//
+
+package inventory_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+// setupKit creates kit 1001 with components 1002 and 1003,
+// and 1004 as a component of 1002.
+func setupKit(t *testing.T) *inventory.InventoryDB {
+	inv := setupInventoryDB(t)
+
+	items := []inventory.Item{
+		{ID: 1001, Description: "Rack kit", Location: "Store",
+			Status: "New", Remarks: "received"},
+		{ID: 1002, Description: "UPS", Location: "Store",
+			Status: "New", Remarks: "received"},
+		{ID: 1003, Description: "PDU", Location: "Store",
+			Status: "New", Remarks: "received"},
+		{ID: 1004, Description: "Battery", Location: "Store",
+			Status: "New", Remarks: "received"},
+	}
+	for _, item := range items {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	for child, parent := range map[int]int{
+		1002: 1001, 1003: 1001, 1004: 1002,
+	} {
+		if err := inv.SetParent(child, parent); err != nil {
+			t.Fatalf("SetParent failed: %v", err)
+		}
+	}
+	return inv
+}
+
+func TestInventoryDB_SetParent(t *testing.T) {
+	inv := setupKit(t)
+	defer inv.Close()
+
+	got, _ := inv.GetItemByID(1002)
+	if got.ParentID != 1001 {
+		t.Errorf("unexpected ParentID: %d", got.ParentID)
+	}
+	if !strings.Contains(got.Remarks, "added to kit 1001") {
+		t.Errorf("missing kit remark: %q", got.Remarks)
+	}
+
+	parts, err := inv.ListComponents(1001)
+	if err != nil || len(parts) != 2 {
+		t.Fatalf("ListComponents failed: %v %v", parts, err)
+	}
+
+	// Detach
+	if err := inv.SetParent(1003, 0); err != nil {
+		t.Fatalf("SetParent detach failed: %v", err)
+	}
+	got, _ = inv.GetItemByID(1003)
+	if got.ParentID != 0 {
+		t.Errorf("expected detached item, got parent %d", got.ParentID)
+	}
+}
+
+func TestInventoryDB_SetParent_Cycle(t *testing.T) {
+	inv := setupKit(t)
+	defer inv.Close()
+
+	err := inv.SetParent(1001, 1004)
+	if !errors.Is(err, inventory.ErrKitCycle) {
+		t.Errorf("expected ErrKitCycle, got %v", err)
+	}
+
+	err = inv.SetParent(1001, 1001)
+	if !errors.Is(err, inventory.ErrKitCycle) {
+		t.Errorf("expected ErrKitCycle for self, got %v", err)
+	}
+
+	err = inv.SetParent(1002, 9999)
+	if err == nil {
+		t.Errorf("expected error for missing parent")
+	}
+}
+
+func TestInventoryDB_GetKitTree(t *testing.T) {
+	inv := setupKit(t)
+	defer inv.Close()
+
+	kit, err := inv.GetKitTree(1001)
+	if err != nil {
+		t.Fatalf("GetKitTree failed: %v", err)
+	}
+	if len(kit.Components) != 2 ||
+		len(kit.Components[0].Components) != 1 {
+		t.Fatalf("unexpected tree: %+v", kit)
+	}
+
+	tree := kit.Tree()
+	for _, want := range []string{
+		"1001 Rack kit", "├── 1002 UPS", "│   └── 1004 Battery",
+		"└── 1003 PDU",
+	} {
+		if !strings.Contains(tree, want) {
+			t.Errorf("tree missing %q:\n%s", want, tree)
+		}
+	}
+
+	_, err = inv.GetKitTree(9999)
+	if err == nil {
+		t.Errorf("expected error for missing kit")
+	}
+}
+
+func TestInventoryDB_UpdateKit_Cascade(t *testing.T) {
+	inv := setupKit(t)
+	defer inv.Close()
+
+	err := inv.UpdateKit(1001, inventory.KitChange{
+		Location: "Rack 5",
+		Remark:   "installed",
+		Cascade:  true,
+		ComponentRemarks: map[int]string{
+			1004: "battery charged",
+		},
+	})
+	if err != nil {
+		t.Fatalf("UpdateKit failed: %v", err)
+	}
+
+	for id, remark := range map[int]string{
+		1001: "installed", 1002: "installed (kit 1001)",
+		1003: "installed (kit 1001)", 1004: "battery charged",
+	} {
+		got, _ := inv.GetItemByID(id)
+		if got.Location != "Rack 5" {
+			t.Errorf("item %d not moved: %s", id, got.Location)
+		}
+		if got.Status != "New" {
+			t.Errorf("item %d status changed: %s", id, got.Status)
+		}
+		if !strings.HasSuffix(got.Remarks, remark) {
+			t.Errorf("item %d unexpected remarks: %q", id, got.Remarks)
+		}
+	}
+}
+
+func TestInventoryDB_UpdateKit_NoCascade(t *testing.T) {
+	inv := setupKit(t)
+	defer inv.Close()
+
+	err := inv.UpdateKit(1001, inventory.KitChange{Status: "Retired"})
+	if err != nil {
+		t.Fatalf("UpdateKit failed: %v", err)
+	}
+
+	kit, _ := inv.GetItemByID(1001)
+	part, _ := inv.GetItemByID(1002)
+	if kit.Status != "Retired" || part.Status != "New" {
+		t.Errorf("unexpected status: kit %s, part %s",
+			kit.Status, part.Status)
+	}
+	if !strings.HasSuffix(kit.Remarks, "status set to Retired") {
+		t.Errorf("missing default remark: %q", kit.Remarks)
+	}
+
+	err = inv.UpdateKit(1001, inventory.KitChange{})
+	if err == nil {
+		t.Errorf("expected error for empty change")
+	}
+}
+
+func TestInventoryDB_DeleteKit_DetachesComponents(t *testing.T) {
+	inv := setupKit(t)
+	defer inv.Close()
+
+	if err := inv.DeleteItem(1001); err != nil {
+		t.Fatalf("DeleteItem failed: %v", err)
+	}
+
+	got, _ := inv.GetItemByID(1002)
+	if got.ParentID != 0 {
+		t.Errorf("expected detached component, got parent %d",
+			got.ParentID)
+	}
+}
+
+func TestExportImportKitsJSON(t *testing.T) {
+	inv := setupKit(t)
+	defer inv.Close()
+
+	tmpfile := filepath.Join(t.TempDir(), "kits.json")
+	if err := inv.ExportKitsJSON(tmpfile); err != nil {
+		t.Fatalf("ExportKitsJSON failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(tmpfile)
+	if !strings.Contains(string(data), `"components"`) {
+		t.Errorf("expected nested components in export")
+	}
+
+	other := setupInventoryDB(t)
+	defer other.Close()
+
+	if err := other.ImportKitsJSON(tmpfile); err != nil {
+		t.Fatalf("ImportKitsJSON failed: %v", err)
+	}
+
+	kits, err := other.ListKits()
+	if err != nil || len(kits) != 1 {
+		t.Fatalf("ListKits failed: %v %v", kits, err)
+	}
+	got, _ := other.GetItemByID(1004)
+	if got.ParentID != 1002 {
+		t.Errorf("unexpected ParentID after import: %d", got.ParentID)
+	}
+}
+
+func TestImportKitsJSON_Invalid(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	err := inventory.ImportKitsJSONFromBytes(inv.DB(),
+		[]byte(`[{"description":"no id"}]`))
+	if err == nil {
+		t.Errorf("expected error for missing id")
+	}
+
+	err = inventory.ImportKitsJSONFromBytes(inv.DB(), []byte(`[
+        {"id": 1, "components": [{"id": 1}]}
+    ]`))
+	if err == nil {
+		t.Errorf("expected error for duplicate id")
+	}
+}
+
+func TestImportJSON_KitCycle(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	err := inv.ImportJSONFromString(`[
+        {"id": 1001, "description": "A", "parent_id": 1002},
+        {"id": 1002, "description": "B", "parent_id": 1001}
+    ]`)
+	if err == nil || !strings.Contains(err.Error(), "kit cycle") {
+		t.Errorf("expected kit cycle error, got %v", err)
+	}
+
+	err = inv.AppendItem(inventory.Item{ID: 1003, Description: "C",
+		ParentID: 1003})
+	if !errors.Is(err, inventory.ErrKitCycle) {
+		t.Errorf("expected ErrKitCycle for self parent, got %v", err)
+	}
+
+	err = inventory.ImportKitsJSONFromBytes(inv.DB(),
+		[]byte(`[{"id": 1004, "description": "D", "parent_id": 1004}]`))
+	if err == nil {
+		t.Errorf("expected error for self parent in kit json")
+	}
+
+	kits, err := inv.ListKits()
+	if err != nil || len(kits) != 0 {
+		t.Errorf("expected no items after refused imports: %v %v",
+			kits, err)
+	}
+
+	inv = setupKit(t)
+	defer inv.Close()
+	err = inv.AddItem(inventory.Item{Description: "Fan", ParentID: 1004})
+	if err != nil {
+		t.Errorf("AddItem below a kit failed: %v", err)
+	}
+	err = inv.AppendItem(inventory.Item{ID: 1001,
+		Description: "Rack kit", ParentID: 1004})
+	if !errors.Is(err, inventory.ErrKitCycle) {
+		t.Errorf("expected ErrKitCycle for replaced kit, got %v", err)
+	}
+}
+
+func TestListKits_Cycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// A cycle left by a database written before the kit triggers
+	for _, q := range []string{
+		`DROP TRIGGER inventory_kit_insert`,
+		`INSERT INTO inventory
+            (id, description, location, status, remarks, parent_id)
+            VALUES (1001, 'A', '', '', '', 1002),
+                   (1002, 'B', '', '', '', 1001),
+                   (1003, 'C', '', '', '', 1003)`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	kits, err := inventory.ListKits(db)
+	if err != nil {
+		t.Fatalf("ListKits failed: %v", err)
+	}
+	if len(kits) != 2 || kits[0].ID != 1001 || kits[1].ID != 1003 {
+		t.Fatalf("expected cycles listed from 1001 and 1003, got %v", kits)
+	}
+	if len(kits[0].Components) != 1 || kits[0].Components[0].ID != 1002 {
+		t.Errorf("expected 1002 below 1001, got %v", kits[0].Components)
+	}
+}
@@ -0,0 +1,523 @@
+// label.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Printable item labels for Inventory CLI
+// Renders a Code128 barcode, a QR code, the description and the
+// location of items as SVG or PNG, singly or on A4 label sheets.
+//
+
+package inventory
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Label output formats.
+const (
+	LabelSVG = "svg"
+	LabelPNG = "png"
+)
+
+// LabelDPI is the default resolution of PNG labels.
+const LabelDPI = 300
+
+// LabelSheet describes a printable sheet of labels.
+// All sizes are in millimetres.
+//
+// Fields:
+//
+//	Name          - stock name, e.g. "L7160"
+//	PageWidth     - sheet width
+//	PageHeight    - sheet height
+//	Columns, Rows - labels across and down the sheet
+//	LabelWidth    - width of one label
+//	LabelHeight   - height of one label
+//	MarginLeft    - distance of the first column from the left edge
+//	MarginTop     - distance of the first row from the top edge
+//	PitchX        - distance between the left edges of two columns
+//	PitchY        - distance between the top edges of two rows
+type LabelSheet struct {
+	Name        string
+	PageWidth   float64
+	PageHeight  float64
+	Columns     int
+	Rows        int
+	LabelWidth  float64
+	LabelHeight float64
+	MarginLeft  float64
+	MarginTop   float64
+	PitchX      float64
+	PitchY      float64
+}
+
+// Common A4 label stocks.
+var (
+	// SheetL7160 is 21 labels of 63.5 x 38.1 mm (3 x 7).
+	SheetL7160 = LabelSheet{
+		Name: "L7160", PageWidth: 210, PageHeight: 297,
+		Columns: 3, Rows: 7, LabelWidth: 63.5, LabelHeight: 38.1,
+		MarginLeft: 7.25, MarginTop: 15.15, PitchX: 66.04, PitchY: 38.1,
+	}
+
+	// SheetL7163 is 14 labels of 99.1 x 38.1 mm (2 x 7).
+	SheetL7163 = LabelSheet{
+		Name: "L7163", PageWidth: 210, PageHeight: 297,
+		Columns: 2, Rows: 7, LabelWidth: 99.1, LabelHeight: 38.1,
+		MarginLeft: 4.65, MarginTop: 15.15, PitchX: 101.6, PitchY: 38.1,
+	}
+
+	// SheetA4x24 is 24 borderless labels of 70 x 37 mm (3 x 8).
+	SheetA4x24 = LabelSheet{
+		Name: "A4-24", PageWidth: 210, PageHeight: 297,
+		Columns: 3, Rows: 8, LabelWidth: 70, LabelHeight: 37,
+		MarginLeft: 0, MarginTop: 0.5, PitchX: 70, PitchY: 37,
+	}
+)
+
+// LabelSheets lists the built-in label stocks by name,
+// for selection from the command line.
+var LabelSheets = map[string]LabelSheet{
+	SheetL7160.Name: SheetL7160,
+	SheetL7163.Name: SheetL7163,
+	SheetA4x24.Name: SheetA4x24,
+}
+
+// PerPage returns the number of labels on one sheet.
+func (s LabelSheet) PerPage() int {
+	return s.Columns * s.Rows
+}
+
+// labelCanvas is a drawing surface in millimetres.
+// Text is placed by its baseline and sized by its height.
+type labelCanvas interface {
+	rect(x, y, w, h float64)
+	text(x, y, size float64, s string)
+}
+
+// drawLabel lays out one item label of w x h mm at (x, y):
+// the QR code on the left, then the ID, the Code128 barcode,
+// the description and the location on the right.
+func drawLabel(c labelCanvas, item Item, x, y, w, h float64) error {
+	id := strconv.Itoa(item.ID)
+	bar, err := code128.Encode(id)
+	if err != nil {
+		return fmt.Errorf("barcode for item %d failed: %v", item.ID, err)
+	}
+	code, err := qr.Encode(id, qr.M, qr.Auto)
+	if err != nil {
+		return fmt.Errorf("QR code for item %d failed: %v", item.ID, err)
+	}
+
+	pad := math.Min(2, h/10)
+	side := math.Min(h-2*pad, w/2-pad)
+	drawModules(c, code, x+pad, y+pad, side, side, 2)
+
+	tx := x + 2*pad + side
+	tw := w - side - 3*pad
+	idSize := h * 0.14
+	small := h * 0.09
+	barH := h * 0.35
+
+	ty := y + pad + idSize
+	c.text(tx, ty, idSize, id)
+	ty += pad / 2
+	drawModules(c, bar, tx, ty, tw, barH, 5)
+	ty += barH + pad/2 + small
+	c.text(tx, ty, small, fitText(item.Description, tw, small))
+	ty += small + pad/2
+	c.text(tx, ty, small, fitText(item.Location, tw, small))
+	return nil
+}
+
+// drawModules draws the dark modules of a barcode scaled into the
+// box, keeping quiet modules free on each side. One-dimensional
+// codes are stretched to the box height, two-dimensional codes
+// keep square modules.
+func drawModules(c labelCanvas, code barcode.Barcode,
+	x, y, w, h float64, quiet int) {
+	b := code.Bounds()
+	cols, rows := b.Dx(), b.Dy()
+	mw := w / float64(cols+2*quiet)
+	mh := h
+	if rows > 1 {
+		mw = math.Min(w, h) / float64(cols+2*quiet)
+		mh = mw
+		x += float64(quiet) * mw
+		y += float64(quiet) * mh
+	} else {
+		x += float64(quiet) * mw
+	}
+
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; {
+			if !isDark(code.At(b.Min.X+col, b.Min.Y+row)) {
+				col++
+				continue
+			}
+			start := col
+			for col < cols && isDark(code.At(b.Min.X+col, b.Min.Y+row)) {
+				col++
+			}
+			c.rect(x+float64(start)*mw, y+float64(row)*mh,
+				float64(col-start)*mw, mh)
+		}
+	}
+}
+
+// isDark reports whether a barcode module is printed.
+func isDark(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r+g+b < 3*0x8000
+}
+
+// fitText shortens s with "..." to fit a width in mm,
+// assuming monospace glyphs 0.6 times as wide as they are high.
+func fitText(s string, width, size float64) string {
+	n := int(width / (0.6 * size))
+	r := []rune(s)
+	if n < 1 {
+		return ""
+	}
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(r[:n])
+	}
+	return string(r[:n-3]) + "..."
+}
+
+// mm formats a length for SVG output.
+func mm(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// svgCanvas writes SVG elements in millimetre user units.
+type svgCanvas struct {
+	w *bufio.Writer
+}
+
+func (c svgCanvas) rect(x, y, w, h float64) {
+	fmt.Fprintf(c.w, `<rect x="%s" y="%s" width="%s" height="%s"/>`+"\n",
+		mm(x), mm(y), mm(w), mm(h))
+}
+
+func (c svgCanvas) text(x, y, size float64, s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(c.w, `<text x="%s" y="%s" font-size="%s">`,
+		mm(x), mm(y), mm(size))
+	xml.EscapeText(c.w, []byte(s))
+	c.w.WriteString("</text>\n")
+}
+
+// writeSVG wraps the drawing of fn in an SVG document of
+// w x h mm and writes it out.
+func writeSVG(out io.Writer, w, h float64,
+	fn func(c labelCanvas) error) error {
+	bw := bufio.NewWriter(out)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" `+
+		`width="%smm" height="%smm" viewBox="0 0 %s %s">`+"\n",
+		mm(w), mm(h), mm(w), mm(h))
+	fmt.Fprintf(bw, `<rect width="%s" height="%s" fill="white"/>`+"\n",
+		mm(w), mm(h))
+	bw.WriteString(`<g fill="black" font-family="monospace">` + "\n")
+	if err := fn(svgCanvas{bw}); err != nil {
+		return err
+	}
+	bw.WriteString("</g>\n</svg>\n")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write SVG failed: %v", err)
+	}
+	return nil
+}
+
+// pngCanvas draws on an image at a fixed pixels-per-mm scale.
+// Text uses the built-in 7x13 bitmap font scaled up by whole
+// pixels, so characters outside Latin-1 are left out.
+type pngCanvas struct {
+	img   *image.Gray
+	scale float64
+}
+
+func (c pngCanvas) px(v float64) int {
+	return int(math.Round(v * c.scale))
+}
+
+func (c pngCanvas) rect(x, y, w, h float64) {
+	r := image.Rect(c.px(x), c.px(y), c.px(x+w), c.px(y+h))
+	draw.Draw(c.img, r, image.Black, image.Point{}, draw.Src)
+}
+
+func (c pngCanvas) text(x, y, size float64, s string) {
+	if s == "" {
+		return
+	}
+	face := basicfont.Face7x13
+	glyphs := image.NewGray(image.Rect(0, 0,
+		face.Advance*len([]rune(s)), face.Height))
+	d := font.Drawer{
+		Dst: glyphs, Src: image.White, Face: face,
+		Dot: fixed.P(0, face.Ascent),
+	}
+	d.DrawString(s)
+
+	k := int(math.Max(1, math.Round(size*c.scale/float64(face.Height))))
+	left, top := c.px(x), c.px(y)-face.Ascent*k
+	b := glyphs.Bounds()
+	for gy := 0; gy < b.Dy(); gy++ {
+		for gx := 0; gx < b.Dx(); gx++ {
+			if glyphs.GrayAt(gx, gy).Y < 0x80 {
+				continue
+			}
+			r := image.Rect(left+gx*k, top+gy*k,
+				left+(gx+1)*k, top+(gy+1)*k)
+			draw.Draw(c.img, r, image.Black, image.Point{}, draw.Src)
+		}
+	}
+}
+
+// writePNG wraps the drawing of fn in a white image of
+// w x h mm at the given DPI and encodes it as PNG.
+func writePNG(out io.Writer, w, h float64, dpi int,
+	fn func(c labelCanvas) error) error {
+	if dpi <= 0 {
+		dpi = LabelDPI
+	}
+	c := pngCanvas{scale: float64(dpi) / 25.4}
+	c.img = image.NewGray(image.Rect(0, 0, c.px(w), c.px(h)))
+	draw.Draw(c.img, c.img.Bounds(), image.White, image.Point{}, draw.Src)
+	if err := fn(c); err != nil {
+		return err
+	}
+	if err := png.Encode(out, c.img); err != nil {
+		return fmt.Errorf("write PNG failed: %v", err)
+	}
+	return nil
+}
+
+// WriteLabelSVG writes a single label of w x h mm for the item
+// as an SVG document.
+//
+// Usage:
+//
+//	err := WriteLabelSVG(os.Stdout, item, 63.5, 38.1)
+func WriteLabelSVG(out io.Writer, item Item, w, h float64) error {
+	return writeSVG(out, w, h, func(c labelCanvas) error {
+		return drawLabel(c, item, 0, 0, w, h)
+	})
+}
+
+// WriteLabelPNG writes a single label of w x h mm for the item
+// as a PNG image. A dpi of 0 selects LabelDPI.
+//
+// Usage:
+//
+//	err := WriteLabelPNG(f, item, 63.5, 38.1, 300)
+func WriteLabelPNG(out io.Writer, item Item,
+	w, h float64, dpi int) error {
+	return writePNG(out, w, h, dpi, func(c labelCanvas) error {
+		return drawLabel(c, item, 0, 0, w, h)
+	})
+}
+
+// drawSheet places the items on the sheet, starting after
+// skip positions that were already used.
+func drawSheet(c labelCanvas, s LabelSheet, items []Item, skip int) error {
+	if skip < 0 || skip+len(items) > s.PerPage() {
+		return fmt.Errorf("%d labels from position %d do not fit "+
+			"on a %d-up sheet", len(items), skip+1, s.PerPage())
+	}
+	for i, item := range items {
+		pos := skip + i
+		x := s.MarginLeft + float64(pos%s.Columns)*s.PitchX
+		y := s.MarginTop + float64(pos/s.Columns)*s.PitchY
+		err := drawLabel(c, item, x, y, s.LabelWidth, s.LabelHeight)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteLabelSheetSVG writes one sheet of labels as an SVG page,
+// filling positions left to right, top to bottom.
+//
+// Skip leaves that many positions blank, so a partly used sheet
+// can be fed again.
+//
+// Usage:
+//
+//	err := WriteLabelSheetSVG(f, SheetL7160, items, 0)
+//
+// Errors are returned if the items do not fit on the sheet.
+func WriteLabelSheetSVG(out io.Writer, s LabelSheet,
+	items []Item, skip int) error {
+	return writeSVG(out, s.PageWidth, s.PageHeight,
+		func(c labelCanvas) error {
+			return drawSheet(c, s, items, skip)
+		})
+}
+
+// WriteLabelSheetPNG writes one sheet of labels as a PNG page.
+// A dpi of 0 selects LabelDPI.
+//
+// See WriteLabelSheetSVG() for the layout.
+func WriteLabelSheetPNG(out io.Writer, s LabelSheet,
+	items []Item, skip, dpi int) error {
+	return writePNG(out, s.PageWidth, s.PageHeight, dpi,
+		func(c labelCanvas) error {
+			return drawSheet(c, s, items, skip)
+		})
+}
+
+// GenerateLabels renders labels for the items selected by the
+// filter onto as many sheets as needed, and saves the pages in
+// dir as labels-001.svg, labels-002.svg, ... (or .png).
+//
+// This is the backend of `bvl labels --filter ...`.
+//
+// Usage:
+//
+//	f, _ := ParseItemFilter("location=Lab")
+//	files, err := GenerateLabels(db, f, SheetL7160, "out", LabelSVG)
+//
+// Returns the paths of the written pages, none if nothing matched.
+//
+// Works with both *sql.DB and *sql.Tx.
+func GenerateLabels(db Querier, f ItemFilter, s LabelSheet,
+	dir, format string) ([]string, error) {
+	if format != LabelSVG && format != LabelPNG {
+		return nil, fmt.Errorf("unknown label format %q", format)
+	}
+	if s.PerPage() <= 0 {
+		return nil, fmt.Errorf("label sheet %q has no labels", s.Name)
+	}
+
+	items, err := queryFiltered(db, f)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for page := 0; page*s.PerPage() < len(items); page++ {
+		end := (page + 1) * s.PerPage()
+		if end > len(items) {
+			end = len(items)
+		}
+		name := filepath.Join(dir,
+			fmt.Sprintf("labels-%03d.%s", page+1, format))
+		err := writeLabelFile(name, s, items[page*s.PerPage():end], format)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+// writeLabelFile saves one sheet of labels to a file.
+func writeLabelFile(name string, s LabelSheet,
+	items []Item, format string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create label file: %v", err)
+	}
+	if format == LabelPNG {
+		err = WriteLabelSheetPNG(f, s, items, 0, LabelDPI)
+	} else {
+		err = WriteLabelSheetSVG(f, s, items, 0)
+	}
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to close label file: %v", cerr)
+	}
+	return err
+}
+
+// LabelSheetByName looks up a built-in label stock,
+// ignoring case.
+//
+// Usage:
+//
+//	s, err := LabelSheetByName("l7160")
+func LabelSheetByName(name string) (LabelSheet, error) {
+	for key, s := range LabelSheets {
+		if strings.EqualFold(key, name) {
+			return s, nil
+		}
+	}
+	return LabelSheet{}, fmt.Errorf("unknown label sheet %q", name)
+}
+
+// GenerateLabels wraps GenerateLabels.
+//
+// Usage:
+//
+//	files, err := inv.GenerateLabels(filter, SheetL7160, dir, LabelPNG)
+func (inv *InventoryDB) GenerateLabels(f ItemFilter, s LabelSheet,
+	dir, format string) ([]string, error) {
+	return GenerateLabels(inv.db, f, s, dir, format)
+}
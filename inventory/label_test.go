@@ -0,0 +1,165 @@
+// label_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for printable item labels
+// Uses in-memory SQLite DB and temp files
+//
+
+package inventory_test
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestWriteLabelSVG(t *testing.T) {
+	var buf bytes.Buffer
+	item := inventory.Item{
+		ID: 1001, Description: "UPS <APC>", Location: "Rack 1",
+	}
+	if err := inventory.WriteLabelSVG(&buf, item, 63.5, 38.1); err != nil {
+		t.Fatalf("WriteLabelSVG failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		`width="63.5mm"`, ">1001</text>", "UPS &lt;APC&gt;",
+		">Rack 1</text>", "<rect x=",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG missing %q", want)
+		}
+	}
+}
+
+func TestWriteLabelPNG(t *testing.T) {
+	var buf bytes.Buffer
+	item := inventory.Item{ID: 1001, Description: "UPS", Location: "Lab"}
+	if err := inventory.WriteLabelPNG(&buf, item, 63.5, 38.1, 300); err != nil {
+		t.Fatalf("WriteLabelPNG failed: %v", err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("PNG decode failed: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 750 || b.Dy() != 450 {
+		t.Errorf("unexpected PNG size %v", b)
+	}
+}
+
+func TestWriteLabelSheetSVG_Overflow(t *testing.T) {
+	items := make([]inventory.Item, 20)
+	for i := range items {
+		items[i].ID = 1001 + i
+	}
+
+	var buf bytes.Buffer
+	err := inventory.WriteLabelSheetSVG(&buf, inventory.SheetL7160, items, 0)
+	if err != nil {
+		t.Fatalf("WriteLabelSheetSVG failed: %v", err)
+	}
+	err = inventory.WriteLabelSheetSVG(&buf, inventory.SheetL7160, items, 2)
+	if err == nil {
+		t.Error("expected error for labels beyond the sheet")
+	}
+}
+
+func TestInventoryDB_GenerateLabels(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	for i := 0; i < 16; i++ {
+		loc := "Lab"
+		if i%2 == 1 {
+			loc = "Store"
+		}
+		err := inv.AddItem(inventory.Item{Description: "Cable", Location: loc})
+		if err != nil {
+			t.Fatalf("AddItem failed: %v", err)
+		}
+	}
+
+	sheet, err := inventory.LabelSheetByName("l7163")
+	if err != nil {
+		t.Fatalf("LabelSheetByName failed: %v", err)
+	}
+	f, _ := inventory.ParseItemFilter("location=Lab")
+	dir := t.TempDir()
+
+	files, err := inv.GenerateLabels(f, sheet, dir, inventory.LabelPNG)
+	if err != nil {
+		t.Fatalf("GenerateLabels failed: %v", err)
+	}
+	if len(files) != 1 || !strings.HasSuffix(files[0], "labels-001.png") {
+		t.Fatalf("unexpected files: %v", files)
+	}
+
+	files, err = inv.GenerateLabels(
+		inventory.ItemFilter{}, sheet, dir, inventory.LabelSVG)
+	if err != nil {
+		t.Fatalf("GenerateLabels failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 pages, got %v", files)
+	}
+	if _, err := os.Stat(files[1]); err != nil {
+		t.Errorf("page not written: %v", err)
+	}
+
+	_, err = inv.GenerateLabels(f, sheet, dir, "pdf")
+	if err == nil {
+		t.Error("expected error for unknown format")
+	}
+	if _, err := inventory.LabelSheetByName("L9999"); err == nil {
+		t.Error("expected error for unknown sheet")
+	}
+}
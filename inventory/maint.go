@@ -0,0 +1,469 @@
+// maint.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Preventive maintenance schedules for Inventory CLI
+// Each item may have plans such as "replace battery" every 365 days,
+// with due-date reports for upcoming and overdue work.
+//
+
+package inventory
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maintenancePlansTable is the DDL of the maintenance_plans table.
+//
+// Dates are stored as TEXT in DateLayout format, so they sort
+// and compare correctly as strings.
+const maintenancePlansTable = `
+    CREATE TABLE IF NOT EXISTS maintenance_plans (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        item_id INTEGER NOT NULL,
+        task TEXT,
+        interval_days INTEGER NOT NULL,
+        last_done TEXT,
+        next_due TEXT NOT NULL
+    );`
+
+// MaintenancePlan is a periodic service task for an item.
+//
+// Fields:
+//
+//	ID           - auto-increment primary key
+//	ItemID       - inventory item the plan belongs to
+//	Task         - what to do, e.g. "replace battery"
+//	IntervalDays - days between services
+//	LastDone     - date of the last service, empty if never
+//	NextDue      - date the next service is due
+//
+// Dates use DateLayout ("2006-01-02").
+type MaintenancePlan struct {
+	ID           int    `json:"id"`
+	ItemID       int    `json:"item_id"`
+	Task         string `json:"task"`
+	IntervalDays int    `json:"interval_days"`
+	LastDone     string `json:"last_done,omitempty"`
+	NextDue      string `json:"next_due"`
+}
+
+// MaintenanceDue is a plan listed in a due-date report,
+// together with the item it belongs to.
+//
+// DaysLeft is negative when the plan is overdue.
+type MaintenanceDue struct {
+	MaintenancePlan
+	Description string `json:"description"`
+	Location    string `json:"location"`
+	DaysLeft    int    `json:"days_left"`
+}
+
+// Overdue reports whether the due date has already passed.
+func (d MaintenanceDue) Overdue() bool {
+	return d.DaysLeft < 0
+}
+
+// ParseDays parses a day count such as the value of a
+// "--within 30d" flag.
+//
+// Accepted forms:
+//
+//	"30" or "30d" - days
+//	"4w"          - weeks (7 days)
+//	"6m"          - months (30 days)
+//	"1y"          - years (365 days)
+//
+// Returns error for negative or malformed values.
+func ParseDays(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	mult := 1
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'd':
+			s = s[:len(s)-1]
+		case 'w':
+			mult, s = 7, s[:len(s)-1]
+		case 'm':
+			mult, s = 30, s[:len(s)-1]
+		case 'y':
+			mult, s = 365, s[:len(s)-1]
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid day count %q", s)
+	}
+	return n * mult, nil
+}
+
+// AddMaintenancePlan creates a maintenance plan for an item
+// and returns its ID.
+//
+// If NextDue is empty it is computed as LastDone + IntervalDays,
+// or today when the item was never serviced.
+//
+// Usage:
+//
+//	id, err := AddMaintenancePlan(tx, MaintenancePlan{
+//	    ItemID:       1002,
+//	    Task:         "replace battery",
+//	    IntervalDays: 365,
+//	    LastDone:     "2025-06-21",
+//	})
+//
+// Notes:
+// - The item must exist
+// - IntervalDays must be positive
+// - Works with both *sql.DB and *sql.Tx.
+func AddMaintenancePlan(
+	exec ExecQuerier, plan MaintenancePlan) (int, error) {
+
+	if plan.IntervalDays <= 0 {
+		return 0, fmt.Errorf("maintenance interval must be positive")
+	}
+	if _, err := GetItemByID(exec, plan.ItemID); err != nil {
+		return 0, fmt.Errorf("add maintenance plan failed: %v", err)
+	}
+
+	if plan.NextDue == "" {
+		plan.NextDue = today()
+		if plan.LastDone != "" {
+			next, err := addDays(plan.LastDone, plan.IntervalDays)
+			if err != nil {
+				return 0, fmt.Errorf("invalid last done date: %v", err)
+			}
+			plan.NextDue = next
+		}
+	} else if _, err := time.Parse(DateLayout, plan.NextDue); err != nil {
+		return 0, fmt.Errorf("invalid next due date %q", plan.NextDue)
+	}
+
+	res, err := exec.Exec(`
+        INSERT INTO maintenance_plans
+        (item_id, task, interval_days, last_done, next_due)
+        VALUES (?, ?, ?, NULLIF(?, ''), ?)`,
+		plan.ItemID, plan.Task, plan.IntervalDays,
+		plan.LastDone, plan.NextDue)
+	if err != nil {
+		return 0, fmt.Errorf("insert maintenance plan failed: %v", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("maintenance plan id failed: %v", err)
+	}
+	return int(id), nil
+}
+
+// maintenancePlanFields is the column list scanned by
+// scanMaintenancePlan().
+const maintenancePlanFields = `p.id, p.item_id, COALESCE(p.task, ''),
+        p.interval_days, COALESCE(p.last_done, ''), p.next_due`
+
+func scanMaintenancePlan(row rowScanner, extra ...interface{}) (
+	MaintenancePlan, error) {
+
+	var p MaintenancePlan
+	dest := append([]interface{}{
+		&p.ID, &p.ItemID, &p.Task,
+		&p.IntervalDays, &p.LastDone, &p.NextDue,
+	}, extra...)
+	err := row.Scan(dest...)
+	return p, err
+}
+
+// GetMaintenancePlan returns the maintenance plan with the given ID.
+//
+// Works with both *sql.DB and *sql.Tx.
+func GetMaintenancePlan(db Querier, id int) (MaintenancePlan, error) {
+	p, err := scanMaintenancePlan(db.QueryRow(`
+        SELECT `+maintenancePlanFields+`
+        FROM maintenance_plans p WHERE p.id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return p, fmt.Errorf("maintenance plan %d not found", id)
+		}
+		return p, fmt.Errorf("query failed: %v", err)
+	}
+	return p, nil
+}
+
+// ListMaintenancePlans returns the maintenance plans of an item,
+// or of all items when itemID is 0, sorted by next due date.
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListMaintenancePlans(
+	db Querier, itemID int) ([]MaintenancePlan, error) {
+
+	rows, err := db.Query(`
+        SELECT `+maintenancePlanFields+`
+        FROM maintenance_plans p
+        WHERE ? = 0 OR p.item_id = ?
+        ORDER BY p.next_due, p.id`, itemID, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("maintenance query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var plans []MaintenancePlan
+	for rows.Next() {
+		p, err := scanMaintenancePlan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
+
+// RecordMaintenance marks a plan as done and advances its
+// next due date by the plan interval.
+//
+// doneOn is the service date in DateLayout format; use ""
+// for today. The service is logged in the item remarks:
+//
+//	[2025-06-21 15:00] maintenance: replace battery - new Exide unit
+//
+// Usage:
+//
+//	err := RecordMaintenance(tx, planID, "", "new Exide unit")
+//
+// Notes:
+// - The note is optional
+// - Works with both *sql.DB and *sql.Tx.
+func RecordMaintenance(
+	exec ExecQuerier, planID int, doneOn string, note string) error {
+
+	plan, err := GetMaintenancePlan(exec, planID)
+	if err != nil {
+		return fmt.Errorf("record maintenance failed: %v", err)
+	}
+
+	if doneOn == "" {
+		doneOn = today()
+	}
+	next, err := addDays(doneOn, plan.IntervalDays)
+	if err != nil {
+		return fmt.Errorf("record maintenance failed: %v", err)
+	}
+
+	_, err = exec.Exec(`
+        UPDATE maintenance_plans
+        SET last_done = ?, next_due = ?
+        WHERE id = ?`, doneOn, next, planID)
+	if err != nil {
+		return fmt.Errorf("update maintenance plan failed: %v", err)
+	}
+
+	msg := "maintenance: " + plan.Task
+	if doneOn != today() {
+		msg += " (done " + doneOn + ")"
+	}
+	if note != "" {
+		msg += " - " + note
+	}
+	return AppendRemarksEntry(exec, plan.ItemID, msg)
+}
+
+// DeleteMaintenancePlan removes a maintenance plan.
+//
+// If the plan does not exist, the operation is a no-op.
+//
+// Works with both *sql.DB and *sql.Tx.
+func DeleteMaintenancePlan(exec Execer, id int) error {
+	_, err := exec.Exec(`
+        DELETE FROM maintenance_plans
+        WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete maintenance plan failed: %v", err)
+	}
+	return nil
+}
+
+// ListMaintenanceDue returns all plans that are overdue or due
+// within the given number of days, earliest first.
+//
+// Usage:
+//
+//	days, _ := ParseDays("30d")
+//	due, err := ListMaintenanceDue(db, days)
+//	for _, d := range due {
+//	    fmt.Println(d.NextDue, d.Description, d.Task, d.Overdue())
+//	}
+//
+// Use withinDays = 0 to list only what is due today or overdue.
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListMaintenanceDue(
+	db Querier, withinDays int) ([]MaintenanceDue, error) {
+
+	now := today()
+	until, err := addDays(now, withinDays)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+        SELECT `+maintenancePlanFields+`,
+            i.description, i.location
+        FROM maintenance_plans p
+        JOIN inventory i ON i.id = p.item_id
+        WHERE p.next_due <= ?
+        ORDER BY p.next_due, p.id`, until)
+	if err != nil {
+		return nil, fmt.Errorf("maintenance due query failed: %v", err)
+	}
+	defer rows.Close()
+
+	start, _ := time.Parse(DateLayout, now)
+	var due []MaintenanceDue
+	for rows.Next() {
+		var d MaintenanceDue
+		d.MaintenancePlan, err = scanMaintenancePlan(
+			rows, &d.Description, &d.Location)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		t, err := time.Parse(DateLayout, d.NextDue)
+		if err != nil {
+			return nil, fmt.Errorf("plan %d has invalid due date %q",
+				d.ID, d.NextDue)
+		}
+		d.DaysLeft = int(t.Sub(start).Hours() / 24)
+		due = append(due, d)
+	}
+	return due, nil
+}
+
+// WriteMaintenanceReport prints a due-date report as text columns:
+//
+//	OVERDUE  2025-06-01  1002   UPS 3KVA      Rack 1   replace battery
+//	due      2025-07-10  1007   Extinguisher  Hall     refill
+//
+// Errors are returned if writing fails.
+func WriteMaintenanceReport(w io.Writer, due []MaintenanceDue) error {
+	for _, d := range due {
+		state := "due"
+		if d.Overdue() {
+			state = "OVERDUE"
+		}
+		_, err := fmt.Fprintf(w, "%-8s %-10s %-6d %-20s %-15s %s\n",
+			state, d.NextDue, d.ItemID, d.Description,
+			d.Location, d.Task)
+		if err != nil {
+			return fmt.Errorf("write report failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// AddMaintenancePlan wraps AddMaintenancePlan with automatic
+// transaction.
+//
+// Usage:
+//
+//	id, err := inv.AddMaintenancePlan(plan)
+func (inv *InventoryDB) AddMaintenancePlan(
+	plan MaintenancePlan) (int, error) {
+
+	var id int
+	err := inv.withTx("add_maintenance_plan", func(tx ExecQuerier) error {
+		var err error
+		id, err = AddMaintenancePlan(tx, plan)
+		return err
+	})
+	return id, err
+}
+
+// ListMaintenancePlans wraps ListMaintenancePlans.
+//
+// Usage:
+//
+//	plans, err := inv.ListMaintenancePlans(itemID)
+func (inv *InventoryDB) ListMaintenancePlans(
+	itemID int) ([]MaintenancePlan, error) {
+	return ListMaintenancePlans(inv.db, itemID)
+}
+
+// RecordMaintenance wraps RecordMaintenance with automatic
+// transaction.
+//
+// Usage:
+//
+//	err := inv.RecordMaintenance(planID, "", "filter cleaned")
+func (inv *InventoryDB) RecordMaintenance(
+	planID int, doneOn string, note string) error {
+	return inv.withTx("record_maintenance", func(tx ExecQuerier) error {
+		return RecordMaintenance(tx, planID, doneOn, note)
+	})
+}
+
+// DeleteMaintenancePlan wraps DeleteMaintenancePlan with automatic
+// transaction.
+//
+// Usage:
+//
+//	err := inv.DeleteMaintenancePlan(planID)
+func (inv *InventoryDB) DeleteMaintenancePlan(id int) error {
+	return inv.withTx("delete_maintenance_plan", func(tx ExecQuerier) error {
+		return DeleteMaintenancePlan(tx, id)
+	})
+}
+
+// ListMaintenanceDue wraps ListMaintenanceDue.
+//
+// Usage:
+//
+//	due, err := inv.ListMaintenanceDue(30)
+func (inv *InventoryDB) ListMaintenanceDue(
+	withinDays int) ([]MaintenanceDue, error) {
+	return ListMaintenanceDue(inv.db, withinDays)
+}
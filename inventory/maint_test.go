@@ -0,0 +1,223 @@
+// maint_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for preventive maintenance schedules
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func daysFromNow(n int) string {
+	return time.Now().AddDate(0, 0, n).Format(inventory.DateLayout)
+}
+
+func setupMaintenance(t *testing.T) *inventory.InventoryDB {
+	inv := setupInventoryDB(t)
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "UPS", Location: "Rack 1"},
+		{ID: 1002, Description: "Extinguisher", Location: "Hall"},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+	return inv
+}
+
+func TestParseDays(t *testing.T) {
+	for in, want := range map[string]int{
+		"30": 30, "30d": 30, "2w": 14, "6m": 180, "1y": 365, " 7D ": 7,
+	} {
+		got, err := inventory.ParseDays(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDays(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "d", "-3d", "abc"} {
+		if _, err := inventory.ParseDays(in); err == nil {
+			t.Errorf("ParseDays(%q) expected error", in)
+		}
+	}
+}
+
+func TestInventoryDB_AddMaintenancePlan(t *testing.T) {
+	inv := setupMaintenance(t)
+	defer inv.Close()
+
+	id, err := inv.AddMaintenancePlan(inventory.MaintenancePlan{
+		ItemID: 1001, Task: "replace battery",
+		IntervalDays: 365, LastDone: "2025-01-10",
+	})
+	if err != nil {
+		t.Fatalf("AddMaintenancePlan failed: %v", err)
+	}
+
+	plans, err := inv.ListMaintenancePlans(1001)
+	if err != nil || len(plans) != 1 {
+		t.Fatalf("ListMaintenancePlans failed: %v %v", plans, err)
+	}
+	if plans[0].ID != id || plans[0].NextDue != "2026-01-10" {
+		t.Errorf("unexpected plan: %+v", plans[0])
+	}
+
+	_, err = inv.AddMaintenancePlan(inventory.MaintenancePlan{
+		ItemID: 9999, Task: "ghost", IntervalDays: 30,
+	})
+	if err == nil {
+		t.Errorf("expected error for missing item")
+	}
+
+	_, err = inv.AddMaintenancePlan(inventory.MaintenancePlan{
+		ItemID: 1001, Task: "never", IntervalDays: 0,
+	})
+	if err == nil {
+		t.Errorf("expected error for zero interval")
+	}
+}
+
+func TestInventoryDB_RecordMaintenance(t *testing.T) {
+	inv := setupMaintenance(t)
+	defer inv.Close()
+
+	id, err := inv.AddMaintenancePlan(inventory.MaintenancePlan{
+		ItemID: 1002, Task: "refill", IntervalDays: 180,
+	})
+	if err != nil {
+		t.Fatalf("AddMaintenancePlan failed: %v", err)
+	}
+
+	err = inv.RecordMaintenance(id, "2025-03-01", "pressure ok")
+	if err != nil {
+		t.Fatalf("RecordMaintenance failed: %v", err)
+	}
+
+	plans, _ := inv.ListMaintenancePlans(1002)
+	if plans[0].LastDone != "2025-03-01" ||
+		plans[0].NextDue != "2025-08-28" {
+		t.Errorf("unexpected plan after service: %+v", plans[0])
+	}
+
+	item, _ := inv.GetItemByID(1002)
+	want := "maintenance: refill (done 2025-03-01) - pressure ok"
+	if !strings.HasSuffix(item.Remarks, want) {
+		t.Errorf("unexpected remarks: %q", item.Remarks)
+	}
+
+	if err := inv.RecordMaintenance(9999, "", ""); err == nil {
+		t.Errorf("expected error for missing plan")
+	}
+}
+
+func TestInventoryDB_ListMaintenanceDue(t *testing.T) {
+	inv := setupMaintenance(t)
+	defer inv.Close()
+
+	for _, plan := range []inventory.MaintenancePlan{
+		{ItemID: 1001, Task: "overdue", IntervalDays: 30,
+			NextDue: daysFromNow(-5)},
+		{ItemID: 1001, Task: "soon", IntervalDays: 30,
+			NextDue: daysFromNow(10)},
+		{ItemID: 1002, Task: "later", IntervalDays: 30,
+			NextDue: daysFromNow(60)},
+	} {
+		if _, err := inv.AddMaintenancePlan(plan); err != nil {
+			t.Fatalf("AddMaintenancePlan failed: %v", err)
+		}
+	}
+
+	due, err := inv.ListMaintenanceDue(30)
+	if err != nil {
+		t.Fatalf("ListMaintenanceDue failed: %v", err)
+	}
+	if len(due) != 2 {
+		t.Fatalf("expected 2 due plans, got %d", len(due))
+	}
+	if due[0].Task != "overdue" || !due[0].Overdue() {
+		t.Errorf("expected overdue plan first: %+v", due[0])
+	}
+	if due[1].Task != "soon" || due[1].Overdue() ||
+		due[1].Description != "UPS" {
+		t.Errorf("unexpected upcoming plan: %+v", due[1])
+	}
+
+	var buf bytes.Buffer
+	if err := inventory.WriteMaintenanceReport(&buf, due); err != nil {
+		t.Fatalf("WriteMaintenanceReport failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "OVERDUE") {
+		t.Errorf("unexpected report:\n%s", buf.String())
+	}
+}
+
+func TestInventoryDB_DeleteItem_RemovesMaintenancePlans(t *testing.T) {
+	inv := setupMaintenance(t)
+	defer inv.Close()
+
+	_, err := inv.AddMaintenancePlan(inventory.MaintenancePlan{
+		ItemID: 1001, Task: "clean", IntervalDays: 90,
+	})
+	if err != nil {
+		t.Fatalf("AddMaintenancePlan failed: %v", err)
+	}
+
+	if err := inv.DeleteItem(1001); err != nil {
+		t.Fatalf("DeleteItem failed: %v", err)
+	}
+
+	plans, _ := inv.ListMaintenancePlans(0)
+	if len(plans) != 0 {
+		t.Errorf("expected plans removed, got %d", len(plans))
+	}
+}
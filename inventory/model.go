@@ -50,9 +50,12 @@
 package inventory
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/boseji/bsg/gen"
 )
@@ -66,6 +69,23 @@ import (
 //	Location    - free text
 //	Status      - free text
 //	Remarks     - audit log, may contain timestamped entries
+//	ParentID    - ID of the kit this item belongs to, 0 if none
+//
+// Stock levels, for consumables:
+//
+//	Quantity     - units on hand
+//	ReorderLevel - reorder when Quantity falls to this level
+//	ReorderQty   - units to order, 0 if not reordered
+//
+// Cost, for valuation and depreciation:
+//
+//	UnitCost - cost of one unit, used when no purchases are recorded
+//
+// Optional dates, in DateLayout format ("2006-01-02"):
+//
+//	PurchaseDate  - date of purchase
+//	WarrantyUntil - last day covered by warranty
+//	ExpiryDate    - end of shelf life, for consumables
 //
 // The Remarks field is typically maintained using FormatRemarks()
 // to ensure consistent timestamp format.
@@ -81,6 +101,135 @@ type Item struct {
 	Location    string `json:"location"`
 	Status      string `json:"status"`
 	Remarks     string `json:"remarks"`
+	ParentID    int    `json:"parent_id,omitempty"`
+
+	PurchaseDate  string `json:"purchase_date,omitempty"`
+	WarrantyUntil string `json:"warranty_until,omitempty"`
+	ExpiryDate    string `json:"expiry_date,omitempty"`
+
+	Quantity     int `json:"quantity,omitempty"`
+	ReorderLevel int `json:"reorder_level,omitempty"`
+	ReorderQty   int `json:"reorder_qty,omitempty"`
+
+	UnitCost float64 `json:"unit_cost,omitempty"`
+}
+
+// ErrInvalidItem is matched by the errors returned for item fields
+// that fail validation, use errors.Is(err, ErrInvalidItem).
+var ErrInvalidItem = errors.New("invalid item")
+
+// invalidItemError is a validation error matching ErrInvalidItem.
+type invalidItemError struct {
+	msg string
+}
+
+func (e *invalidItemError) Error() string { return e.msg }
+
+func (e *invalidItemError) Is(target error) bool {
+	return target == ErrInvalidItem
+}
+
+// invalidf returns a validation error, see ErrInvalidItem.
+func invalidf(format string, args ...interface{}) error {
+	return &invalidItemError{msg: fmt.Sprintf(format, args...)}
+}
+
+// check verifies that the item dates are blank or in
+// DateLayout format, and that stock levels and cost are
+// not negative. Its errors match ErrInvalidItem.
+func (item *Item) check() error {
+	if item.UnitCost < 0 {
+		return invalidf("negative unit cost %v", item.UnitCost)
+	}
+	levels := []struct {
+		name  string
+		value int
+	}{
+		{"quantity", item.Quantity},
+		{"reorder level", item.ReorderLevel},
+		{"reorder quantity", item.ReorderQty},
+	}
+	for _, l := range levels {
+		if l.value < 0 {
+			return invalidf("negative %s %d", l.name, l.value)
+		}
+	}
+
+	dates := []struct{ name, value string }{
+		{"purchase date", item.PurchaseDate},
+		{"warranty date", item.WarrantyUntil},
+		{"expiry date", item.ExpiryDate},
+	}
+	for _, d := range dates {
+		if d.value == "" {
+			continue
+		}
+		if _, err := time.Parse(DateLayout, d.value); err != nil {
+			return invalidf("invalid %s %q", d.name, d.value)
+		}
+	}
+	return nil
+}
+
+// DateLayout is the format of all calendar dates stored by
+// the inventory, e.g. "2025-06-21".
+const DateLayout = "2006-01-02"
+
+// today returns the current date in DateLayout format.
+func today() string {
+	return gen.BST().Format(DateLayout)
+}
+
+// addDays returns the date n days after the given date.
+// The date must be in DateLayout format.
+func addDays(date string, n int) (string, error) {
+	t, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", date)
+	}
+	return t.AddDate(0, 0, n).Format(DateLayout), nil
+}
+
+// FieldChange is a single field difference between two versions
+// of an item. Values are rendered as text.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// String returns the change as "field: before → after".
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s: %s → %s", c.Field, c.Before, c.After)
+}
+
+// DiffItems returns the fields that differ between two versions
+// of an item, in struct order. Field names are the JSON names.
+//
+// Usage:
+//
+//	for _, c := range DiffItems(old, new) {
+//	    fmt.Println(c) // location: Rack 1 → Rack 5
+//	}
+func DiffItems(before, after Item) []FieldChange {
+	var changes []FieldChange
+	b := reflect.ValueOf(before)
+	a := reflect.ValueOf(after)
+	t := b.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		bv := fmt.Sprint(b.Field(i).Interface())
+		av := fmt.Sprint(a.Field(i).Interface())
+		if bv != av {
+			changes = append(changes, FieldChange{
+				Field: name, Before: bv, After: av,
+			})
+		}
+	}
+	return changes
 }
 
 var reLogPrefix = regexp.MustCompile(`^\[\d{4}-\d{2}-\d{2} \d{2}:\d{2}\]`)
@@ -0,0 +1,231 @@
+// ndjson.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// JSON Lines (NDJSON) support for Inventory CLI
+// Streams one item per line on export and import, for large
+// inventories and `jq -c` pipelines.
+//
+
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// LineError is an NDJSON line that could not be imported.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// NDJSONResult counts what ImportNDJSON() did.
+//
+// Errors lists the skipped lines when continuing on error.
+type NDJSONResult struct {
+	Imported int          `json:"imported"`
+	Errors   []*LineError `json:"-"`
+}
+
+// WriteNDJSON streams the items selected by the filter to w,
+// one JSON object per line, using an ItemIterator:
+//
+//	{"id":1001,"description":"UPS","location":"Rack 1",...}
+//	{"id":1002,"description":"Battery","location":"Store",...}
+//
+// Usage:
+//
+//	err := WriteNDJSON(os.Stdout, db, ItemFilter{Location: "Lab"})
+//
+// Errors are returned if the query or writing fails.
+func WriteNDJSON(w io.Writer, db *sql.DB, f ItemFilter) error {
+	iter, err := NewFilteredItemIterator(db, f)
+	if err != nil {
+		return fmt.Errorf("query inventory failed: %v", err)
+	}
+	defer iter.Close()
+
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	for {
+		item, ok, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("scan failed: %v", err)
+		}
+		if !ok {
+			break
+		}
+		if err := enc.Encode(item); err != nil {
+			return fmt.Errorf("write ndjson failed: %v", err)
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("write ndjson failed: %v", err)
+	}
+	return nil
+}
+
+// ExportNDJSON writes all inventory records to an NDJSON file.
+//
+// Usage:
+//
+//	err := ExportNDJSON(db, "inventory.ndjson")
+//
+// Existing file will be overwritten.
+func ExportNDJSON(db *sql.DB, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("create ndjson failed: %v", err)
+	}
+	defer file.Close()
+	return WriteNDJSON(file, db, ItemFilter{})
+}
+
+// ImportNDJSON reads items from r, one JSON object per line, and
+// imports each with AppendItem() as soon as it is read. Blank
+// lines are skipped.
+//
+// A line that cannot be parsed or imported stops the import
+// with a *LineError, unless continueOnError is set; then the
+// line is skipped and listed in the result.
+//
+// Usage:
+//
+//	res, err := ImportNDJSON(tx, os.Stdin, true)
+//	for _, e := range res.Errors {
+//	    fmt.Println(e) // line 12: invalid expiry date ...
+//	}
+//
+// Existing records with matching IDs will be replaced.
+func ImportNDJSON(exec Execer, r io.Reader,
+	continueOnError bool) (NDJSONResult, error) {
+	var res NDJSONResult
+	reader := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return res, fmt.Errorf("read ndjson failed: %v", err)
+		}
+		eof := err == io.EOF
+
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			var item Item
+			err := json.Unmarshal(line, &item)
+			if err == nil {
+				err = AppendItem(exec, item)
+			}
+			if err == nil {
+				res.Imported++
+			} else {
+				le := &LineError{Line: n, Err: err}
+				if !continueOnError {
+					return res, le
+				}
+				res.Errors = append(res.Errors, le)
+			}
+		}
+		if eof {
+			return res, nil
+		}
+	}
+}
+
+// ImportNDJSONFile imports an NDJSON file, see ImportNDJSON().
+func ImportNDJSONFile(exec Execer, filename string,
+	continueOnError bool) (NDJSONResult, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return NDJSONResult{}, fmt.Errorf("open ndjson failed: %v", err)
+	}
+	defer file.Close()
+	return ImportNDJSON(exec, file, continueOnError)
+}
+
+// ExportNDJSON writes all records to NDJSON using InventoryDB.
+//
+// Usage:
+//
+//	err := inv.ExportNDJSON("inventory.ndjson")
+func (inv *InventoryDB) ExportNDJSON(filename string) error {
+	return ExportNDJSON(inv.db, filename)
+}
+
+// ImportNDJSON imports an NDJSON file using InventoryDB.
+//
+// Usage:
+//
+//	res, err := inv.ImportNDJSON("inventory.ndjson", false)
+//
+// The import runs inside a transaction; without continueOnError
+// a bad line rolls back the whole file.
+func (inv *InventoryDB) ImportNDJSON(filename string,
+	continueOnError bool) (NDJSONResult, error) {
+	var res NDJSONResult
+	err := inv.withTx("import_ndjson", func(tx ExecQuerier) error {
+		var err error
+		res, err = ImportNDJSONFile(tx, filename, continueOnError)
+		return err
+	})
+	if err != nil {
+		return NDJSONResult{}, err
+	}
+	return res, nil
+}
@@ -0,0 +1,133 @@
+// ndjson_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for JSON Lines (NDJSON) import and export
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestNDJSON_RoundTrip(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "UPS", Location: "Rack 1"},
+		{ID: 1002, Description: "Battery", Location: "Store"},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	var out strings.Builder
+	err := inventory.WriteNDJSON(&out, inv.DB(),
+		inventory.ItemFilter{Location: "Store"})
+	if err != nil {
+		t.Fatalf("WriteNDJSON failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), `{"id":1002,`) ||
+		strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("unexpected ndjson:\n%s", out.String())
+	}
+
+	file := filepath.Join(t.TempDir(), "inventory.ndjson")
+	if err := inv.ExportNDJSON(file); err != nil {
+		t.Fatalf("ExportNDJSON failed: %v", err)
+	}
+	other := setupInventoryDB(t)
+	defer other.Close()
+	res, err := other.ImportNDJSON(file, false)
+	if err != nil || res.Imported != 2 {
+		t.Fatalf("ImportNDJSON failed: %+v %v", res, err)
+	}
+	if got, _ := other.GetItemByID(1001); got.Description != "UPS" {
+		t.Errorf("unexpected item: %+v", got)
+	}
+}
+
+func TestImportNDJSON_Errors(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	file := filepath.Join(t.TempDir(), "bad.ndjson")
+	data := `{"id": 1001, "description": "UPS"}
+
+{"id": 1002, "description": "Battery", "expiry_date": "soon"}
+not json
+{"id": 1003, "description": "Cable"}`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+
+	_, err := inv.ImportNDJSON(file, false)
+	var le *inventory.LineError
+	if !errors.As(err, &le) || le.Line != 3 {
+		t.Fatalf("expected error on line 3, got %v", err)
+	}
+	if items, _ := inv.ListAll(); len(items) != 0 {
+		t.Errorf("failed import not rolled back: %+v", items)
+	}
+
+	res, err := inv.ImportNDJSON(file, true)
+	if err != nil {
+		t.Fatalf("ImportNDJSON failed: %v", err)
+	}
+	if res.Imported != 2 || len(res.Errors) != 2 ||
+		res.Errors[1].Line != 4 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
@@ -0,0 +1,400 @@
+// patch.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Partial item updates for Inventory CLI
+// Changes only the fields that are set, and serves
+// JSON Merge Patch (RFC 7396) over HTTP.
+//
+
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ItemPatch lists the item fields to change. Nil fields keep
+// their stored value, so a script can change one field without
+// reading the item first:
+//
+//	status := "Installed"
+//	item, err := inv.UpdateItem(1004, ItemPatch{Status: &status})
+//
+// Remarks is appended as a new log entry, not replaced.
+// The ID and parent are not patched, use SetParent() for kits.
+type ItemPatch struct {
+	Description   *string  `json:"description,omitempty"`
+	Location      *string  `json:"location,omitempty"`
+	Status        *string  `json:"status,omitempty"`
+	Remarks       *string  `json:"remarks,omitempty"`
+	PurchaseDate  *string  `json:"purchase_date,omitempty"`
+	WarrantyUntil *string  `json:"warranty_until,omitempty"`
+	ExpiryDate    *string  `json:"expiry_date,omitempty"`
+	Quantity      *int     `json:"quantity,omitempty"`
+	ReorderLevel  *int     `json:"reorder_level,omitempty"`
+	ReorderQty    *int     `json:"reorder_qty,omitempty"`
+	UnitCost      *float64 `json:"unit_cost,omitempty"`
+}
+
+// Apply returns the item with the patch applied.
+// Remarks are left unchanged.
+func (p ItemPatch) Apply(item Item) Item {
+	for _, f := range []struct {
+		src *string
+		dst *string
+	}{
+		{p.Description, &item.Description},
+		{p.Location, &item.Location},
+		{p.Status, &item.Status},
+		{p.PurchaseDate, &item.PurchaseDate},
+		{p.WarrantyUntil, &item.WarrantyUntil},
+		{p.ExpiryDate, &item.ExpiryDate},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	for _, f := range []struct {
+		src *int
+		dst *int
+	}{
+		{p.Quantity, &item.Quantity},
+		{p.ReorderLevel, &item.ReorderLevel},
+		{p.ReorderQty, &item.ReorderQty},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	if p.UnitCost != nil {
+		item.UnitCost = *p.UnitCost
+	}
+	return item
+}
+
+// columns returns the inventory columns set by the patch and
+// their values taken from the patched item, in Item order.
+// Blank dates are stored as NULL.
+func (p ItemPatch) columns(item Item) ([]string, []interface{}) {
+	date := func(v string) interface{} {
+		if v == "" {
+			return nil
+		}
+		return v
+	}
+	var names []string
+	var values []interface{}
+	for _, c := range []struct {
+		set   bool
+		name  string
+		value interface{}
+	}{
+		{p.Description != nil, "description", item.Description},
+		{p.Location != nil, "location", item.Location},
+		{p.Status != nil, "status", item.Status},
+		{p.PurchaseDate != nil, "purchase_date", date(item.PurchaseDate)},
+		{p.WarrantyUntil != nil, "warranty_until",
+			date(item.WarrantyUntil)},
+		{p.ExpiryDate != nil, "expiry_date", date(item.ExpiryDate)},
+		{p.Quantity != nil, "quantity", item.Quantity},
+		{p.ReorderLevel != nil, "reorder_level", item.ReorderLevel},
+		{p.ReorderQty != nil, "reorder_qty", item.ReorderQty},
+		{p.UnitCost != nil, "unit_cost", item.UnitCost},
+	} {
+		if c.set {
+			names = append(names, c.name+" = ?")
+			values = append(values, c.value)
+		}
+	}
+	return names, values
+}
+
+// IsEmpty reports whether the patch changes nothing.
+func (p ItemPatch) IsEmpty() bool {
+	return p == ItemPatch{}
+}
+
+// UpdateItem changes only the fields set in the patch and
+// returns the updated item.
+//
+// The stored row is read, patched and validated, then written
+// back with a single UPDATE of the set columns. Run it inside a
+// transaction (see InventoryDB.UpdateItem) so no other change can
+// slip in between.
+//
+// Like EditItemDiff(), the changed fields are recorded in the
+// remarks using FormatChanges(), after the patch Remarks entry
+// if any. InventoryDB.UpdateItem uses its own DiffFormat, see
+// WithDiffFormat().
+//
+// Usage:
+//
+//	loc, note := "Rack 5", "moved for rewiring"
+//	item, err := UpdateItem(tx, 1004,
+//	    ItemPatch{Location: &loc, Remarks: &note})
+//
+// Returns an error matching ErrItemNotFound for an unknown ID.
+//
+// Works with both *sql.DB and *sql.Tx.
+func UpdateItem(exec ExecQuerier, id int, p ItemPatch) (Item, error) {
+	return updateItem(exec, id, p, FormatChanges)
+}
+
+// updateItem is UpdateItem() with the DiffFormat to record the
+// changed fields with.
+func updateItem(exec ExecQuerier, id int, p ItemPatch,
+	format DiffFormat) (Item, error) {
+	stored, err := GetItemByID(exec, id)
+	if err != nil {
+		return Item{}, err
+	}
+	item := p.Apply(stored)
+	if err := item.check(); err != nil {
+		return Item{}, err
+	}
+
+	sets, args := p.columns(item)
+
+	var entries []string
+	if p.Remarks != nil && strings.TrimSpace(*p.Remarks) != "" {
+		note := Item{Remarks: *p.Remarks}
+		entries = append(entries, note.FormatRemarks())
+	}
+	if diff := diffEntry(format, stored, item); diff != "" {
+		entries = append(entries, diff)
+	}
+	if len(entries) > 0 {
+		sets = append(sets,
+			"remarks = COALESCE(remarks, '') || char(10) || ?")
+		args = append(args, strings.Join(entries, "\n"))
+	}
+	if len(sets) == 0 {
+		return stored, nil
+	}
+
+	args = append(args, id)
+	_, err = exec.Exec(`
+        UPDATE inventory SET `+strings.Join(sets, ", ")+`
+        WHERE id = ?`, args...)
+	if err != nil {
+		return Item{}, fmt.Errorf("update failed: %w", triggerError(err))
+	}
+	return GetItemByID(exec, id)
+}
+
+// MaxPatchBytes is the largest request body MergePatchHandler()
+// reads.
+const MaxPatchBytes = 64 << 10
+
+// ParseMergePatch reads a JSON Merge Patch (RFC 7396) for an item:
+//
+//	{"status": "Installed", "warranty_until": null}
+//
+// Members present in the document are set; null clears the
+// field (blank text, no date, zero number). Unknown members,
+// "id" and "parent_id" are rejected.
+func ParseMergePatch(data []byte) (ItemPatch, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return ItemPatch{}, fmt.Errorf("invalid merge patch: %v", err)
+	}
+
+	var p ItemPatch
+	strs := map[string]**string{
+		"description":    &p.Description,
+		"location":       &p.Location,
+		"status":         &p.Status,
+		"remarks":        &p.Remarks,
+		"purchase_date":  &p.PurchaseDate,
+		"warranty_until": &p.WarrantyUntil,
+		"expiry_date":    &p.ExpiryDate,
+	}
+	ints := map[string]**int{
+		"quantity":      &p.Quantity,
+		"reorder_level": &p.ReorderLevel,
+		"reorder_qty":   &p.ReorderQty,
+	}
+
+	for name, raw := range doc {
+		null := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+		var err error
+		switch {
+		case strs[name] != nil:
+			*strs[name] = new(string)
+			if !null {
+				err = json.Unmarshal(raw, *strs[name])
+			}
+		case ints[name] != nil:
+			*ints[name] = new(int)
+			if !null {
+				err = json.Unmarshal(raw, *ints[name])
+			}
+		case name == "unit_cost":
+			p.UnitCost = new(float64)
+			if !null {
+				err = json.Unmarshal(raw, p.UnitCost)
+			}
+		default:
+			return ItemPatch{}, fmt.Errorf(
+				"field %q cannot be patched", name)
+		}
+		if err != nil {
+			return ItemPatch{}, fmt.Errorf("invalid %s: %v", name, err)
+		}
+	}
+	return p, nil
+}
+
+// MergePatchHandler serves JSON Merge Patch requests on items:
+//
+//	PATCH /items/1004
+//	Content-Type: application/merge-patch+json
+//
+//	{"location": "Rack 5", "remarks": "moved for rewiring"}
+//
+// The item ID is the {id} path value, or else the last path
+// element. The updated item is returned as JSON. Wrap it in
+// TokenAuth() so changes are made as the calling user:
+//
+//	mux.Handle("PATCH /items/{id}",
+//	    TokenAuth(inv, MergePatchHandler(inv)))
+//
+// Errors map to 400 Bad Request (malformed patch or invalid
+// field values), 403 Forbidden, 404 Not Found, 405 Method Not
+// Allowed, 409 Conflict (quantity of an item kept by stock
+// balances, see ErrStockMismatch), 413 Request Entity Too Large
+// (body over MaxPatchBytes) and 415 Unsupported Media Type. Any
+// other failure, such as a database error, is a 500 Internal
+// Server Error.
+func MergePatchHandler(inv *InventoryDB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			w.Header().Set("Allow", http.MethodPatch)
+			http.Error(w, "method not allowed",
+				http.StatusMethodNotAllowed)
+			return
+		}
+		ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if ct != "application/merge-patch+json" &&
+			ct != "application/json" {
+			http.Error(w, "use application/merge-patch+json",
+				http.StatusUnsupportedMediaType)
+			return
+		}
+
+		name := r.PathValue("id")
+		if name == "" {
+			name = path.Base(r.URL.Path)
+		}
+		id, err := strconv.Atoi(name)
+		if err != nil {
+			http.Error(w, "invalid item id", http.StatusBadRequest)
+			return
+		}
+
+		var body bytes.Buffer
+		r.Body = http.MaxBytesReader(w, r.Body, MaxPatchBytes)
+		if _, err := body.ReadFrom(r.Body); err != nil {
+			var tooBig *http.MaxBytesError
+			if errors.As(err, &tooBig) {
+				http.Error(w, "patch too large",
+					http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "read body failed", http.StatusBadRequest)
+			return
+		}
+		p, err := ParseMergePatch(body.Bytes())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		item, err := inv.WithContext(r.Context()).UpdateItem(id, p)
+		switch {
+		case errors.Is(err, ErrItemNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, ErrPermissionDenied):
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		case errors.Is(err, ErrInvalidItem):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, ErrStockMismatch):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			http.Error(w, "update failed", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(item)
+	})
+}
+
+// UpdateItem wraps UpdateItem with automatic transaction.
+//
+// Usage:
+//
+//	item, err := inv.UpdateItem(1004, ItemPatch{Status: &status})
+func (inv *InventoryDB) UpdateItem(id int, p ItemPatch) (Item, error) {
+	var item Item
+	err := inv.withTx("update_item", func(tx ExecQuerier) error {
+		var err error
+		item, err = updateItem(tx, id, p, inv.diffFormat)
+		return err
+	})
+	return item, err
+}
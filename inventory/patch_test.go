@@ -0,0 +1,202 @@
+// patch_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for partial item updates and JSON Merge Patch
+// Uses in-memory SQLite DB and httptest
+//
+
+package inventory_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestInventoryDB_UpdateItem(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	err := inv.AppendItem(inventory.Item{
+		ID: 1001, Description: "Switch", Location: "Rack 1",
+		Status: "Installed", WarrantyUntil: "2026-01-31", Quantity: 2,
+	})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+
+	loc, note, qty := "Rack 5", "moved for rewiring", 3
+	item, err := inv.UpdateItem(1001, inventory.ItemPatch{
+		Location: &loc, Remarks: &note, Quantity: &qty,
+	})
+	if err != nil {
+		t.Fatalf("UpdateItem failed: %v", err)
+	}
+	if item.Location != "Rack 5" || item.Quantity != 3 ||
+		item.Status != "Installed" || item.Description != "Switch" ||
+		item.WarrantyUntil != "2026-01-31" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if !strings.Contains(item.Remarks, "] moved for rewiring\n[") ||
+		!strings.HasSuffix(item.Remarks,
+			"] location: Rack 1 → Rack 5; quantity: 2 → 3") {
+		t.Errorf("unexpected remarks: %q", item.Remarks)
+	}
+
+	blank := ""
+	item, err = inv.UpdateItem(1001,
+		inventory.ItemPatch{WarrantyUntil: &blank})
+	if err != nil || item.WarrantyUntil != "" {
+		t.Errorf("date not cleared: %+v %v", item, err)
+	}
+
+	bad := "31/01/2026"
+	_, err = inv.UpdateItem(1001, inventory.ItemPatch{ExpiryDate: &bad})
+	if err == nil {
+		t.Error("expected error for invalid date")
+	}
+	_, err = inv.UpdateItem(9999, inventory.ItemPatch{Status: &loc})
+	if !errors.Is(err, inventory.ErrItemNotFound) {
+		t.Errorf("expected ErrItemNotFound, got %v", err)
+	}
+}
+
+func TestParseMergePatch(t *testing.T) {
+	p, err := inventory.ParseMergePatch([]byte(
+		`{"status": "Spare", "warranty_until": null, "unit_cost": 120.5}`))
+	if err != nil {
+		t.Fatalf("ParseMergePatch failed: %v", err)
+	}
+	if p.Status == nil || *p.Status != "Spare" ||
+		p.WarrantyUntil == nil || *p.WarrantyUntil != "" ||
+		p.UnitCost == nil || *p.UnitCost != 120.5 ||
+		p.Location != nil {
+		t.Errorf("unexpected patch: %+v", p)
+	}
+
+	for _, doc := range []string{
+		`{"id": 1002}`,
+		`{"colour": "red"}`,
+		`{"quantity": "ten"}`,
+		`[1, 2]`,
+	} {
+		if _, err := inventory.ParseMergePatch([]byte(doc)); err == nil {
+			t.Errorf("expected error for %s", doc)
+		}
+	}
+}
+
+func TestMergePatchHandler(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	err := inv.AppendItem(inventory.Item{
+		ID: 1001, Description: "Switch", Location: "Rack 1",
+	})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/items/{id}", inventory.MergePatchHandler(inv))
+	send := func(method, url, ct, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", ct)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	const ct = "application/merge-patch+json"
+
+	rec := send("PATCH", "/items/1001", ct, `{"location": "Rack 5"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body)
+	}
+	var item inventory.Item
+	if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil ||
+		item.Location != "Rack 5" {
+		t.Errorf("unexpected response: %s", rec.Body)
+	}
+	if err := inv.ReceiveStock(1001, "Rack 5", 4, ""); err != nil {
+		t.Fatalf("ReceiveStock failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		method, url, ct, body string
+		code                  int
+	}{
+		{"PUT", "/items/1001", ct, `{}`, http.StatusMethodNotAllowed},
+		{"PATCH", "/items/1001", "text/plain", `{}`,
+			http.StatusUnsupportedMediaType},
+		{"PATCH", "/items/abc", ct, `{}`, http.StatusBadRequest},
+		{"PATCH", "/items/1001", ct, `{"id": 7}`, http.StatusBadRequest},
+		{"PATCH", "/items/9999", ct, `{}`, http.StatusNotFound},
+		{"PATCH", "/items/1001", ct, `{"expiry_date": "31/12/2025"}`,
+			http.StatusBadRequest},
+		{"PATCH", "/items/1001", ct, `{"quantity": 3}`, http.StatusConflict},
+		{"PATCH", "/items/1001", ct,
+			`{"remarks": "` + strings.Repeat("x", inventory.MaxPatchBytes) +
+				`"}`, http.StatusRequestEntityTooLarge},
+	} {
+		rec := send(tc.method, tc.url, tc.ct, tc.body)
+		if rec.Code != tc.code {
+			t.Errorf("%s %s: expected %d, got %d",
+				tc.method, tc.url, tc.code, rec.Code)
+		}
+	}
+
+	// Database failures are server errors
+	inv.Close()
+	rec = send("PATCH", "/items/1001", ct, `{"location": "Lab"}`)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for closed database, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,554 @@
+// rbac.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Role-based access control for Inventory CLI
+// Users with roles are stored in the database, permissions are
+// checked for every change made through InventoryDB, and API tokens
+// authenticate users of the HTTP frontends.
+//
+
+package inventory
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/boseji/bsg/gen"
+)
+
+// Roles, from least to most privileged.
+//
+//	viewer  - read only
+//	clerk   - add and edit items, remarks, kits, maintenance, attachments
+//	manager - also replace, delete and import items
+//	admin   - everything, including ResetSequence and user management
+const (
+	RoleViewer  = "viewer"
+	RoleClerk   = "clerk"
+	RoleManager = "manager"
+	RoleAdmin   = "admin"
+)
+
+// roleLevels orders the roles by privilege.
+var roleLevels = map[string]int{
+	RoleViewer:  1,
+	RoleClerk:   2,
+	RoleManager: 3,
+	RoleAdmin:   4,
+}
+
+// operationRoles lists the least role needed for each InventoryDB
+// operation. Operations not listed require RoleAdmin.
+var operationRoles = map[string]string{
+	"add_item":                RoleClerk,
+	"edit_item":               RoleClerk,
+	"update_item":             RoleClerk,
+	"append_remarks_entry":    RoleClerk,
+	"set_parent":              RoleClerk,
+	"update_kit":              RoleClerk,
+	"add_maintenance_plan":    RoleClerk,
+	"record_maintenance":      RoleClerk,
+	"attach_file":             RoleClerk,
+	"attach_bytes":            RoleClerk,
+	"scan":                    RoleClerk,
+	"start_stocktake":         RoleClerk,
+	"mark_stocktake":          RoleClerk,
+	"adjust_quantity":         RoleClerk,
+	"receive_stock":           RoleClerk,
+	"issue_stock":             RoleClerk,
+	"transfer":                RoleClerk,
+	"add_supplier":            RoleClerk,
+	"update_supplier":         RoleClerk,
+	"record_purchase":         RoleClerk,
+	"append_item":             RoleManager,
+	"delete_item":             RoleManager,
+	"import_csv":              RoleManager,
+	"import_xlsx":             RoleManager,
+	"import_json":             RoleManager,
+	"import_json_from_string": RoleManager,
+	"import_kits_json":        RoleManager,
+	"delete_maintenance_plan": RoleManager,
+	"delete_attachment":       RoleManager,
+	"reconcile_stocktake":     RoleManager,
+	"delete_supplier":         RoleManager,
+	"bulk_update":             RoleManager,
+	"edit_items":              RoleManager,
+	"revert":                  RoleManager,
+	"sync":                    RoleManager,
+	"import_delta":            RoleManager,
+	"import_ndjson":           RoleManager,
+}
+
+// ErrPermissionDenied is matched by every *PermissionError,
+// use errors.Is(err, ErrPermissionDenied).
+var ErrPermissionDenied = errors.New("permission denied")
+
+// PermissionError is returned when the actor may not perform
+// an operation.
+//
+// Role is empty when the actor is not a known user.
+type PermissionError struct {
+	Actor     string
+	Role      string
+	Operation string
+	Required  string
+}
+
+func (e *PermissionError) Error() string {
+	who := e.Actor
+	if who == "" {
+		who = AnonymousActor
+	}
+	if e.Role == "" {
+		return fmt.Sprintf("permission denied: unknown user %q", who)
+	}
+	return fmt.Sprintf("permission denied: %s (%s) cannot %s, requires %s",
+		who, e.Role, e.Operation, e.Required)
+}
+
+// Is makes errors.Is(err, ErrPermissionDenied) true.
+func (e *PermissionError) Is(target error) bool {
+	return target == ErrPermissionDenied
+}
+
+// usersTable is the DDL of the users table.
+const usersTable = `
+    CREATE TABLE IF NOT EXISTS users (
+        name TEXT PRIMARY KEY,
+        role TEXT NOT NULL,
+        created_at TEXT
+    );`
+
+// apiTokensTable is the DDL of the api_tokens table.
+//
+// Only the SHA-256 of each token is stored.
+const apiTokensTable = `
+    CREATE TABLE IF NOT EXISTS api_tokens (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        user_name TEXT NOT NULL,
+        token_hash TEXT NOT NULL UNIQUE,
+        label TEXT,
+        created_at TEXT
+    );`
+
+// User is a person or service allowed to change the inventory.
+type User struct {
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+}
+
+// APIToken describes an API token. The token itself is only
+// known when created, see CreateAPIToken().
+type APIToken struct {
+	ID        int    `json:"id"`
+	User      string `json:"user"`
+	Label     string `json:"label"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	_, ok := roleLevels[role]
+	return ok
+}
+
+// RequiredRole returns the least role needed for an operation.
+func RequiredRole(op string) string {
+	if role, ok := operationRoles[op]; ok {
+		return role
+	}
+	return RoleAdmin
+}
+
+// authorize checks that the actor may perform op.
+//
+// Access control is enabled once the first user is created.
+// Until then everyone may do everything, which also lets the
+// first user (the admin) be created.
+func authorize(db Querier, actor, op string) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("users query failed: %v", err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	required := RequiredRole(op)
+	user, err := GetUser(db, actor)
+	if err != nil {
+		return &PermissionError{
+			Actor: actor, Operation: op, Required: required,
+		}
+	}
+	if roleLevels[user.Role] < roleLevels[required] {
+		return &PermissionError{
+			Actor: actor, Role: user.Role,
+			Operation: op, Required: required,
+		}
+	}
+	return nil
+}
+
+// GetUser returns the user with the given name.
+//
+// Works with both *sql.DB and *sql.Tx.
+func GetUser(db Querier, name string) (User, error) {
+	var u User
+	err := db.QueryRow(`
+        SELECT name, role, COALESCE(created_at, '')
+        FROM users WHERE name = ?`, name).Scan(
+		&u.Name, &u.Role, &u.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return u, fmt.Errorf("user %q not found", name)
+		}
+		return u, fmt.Errorf("query failed: %v", err)
+	}
+	return u, nil
+}
+
+// ListUsers returns all users sorted by name.
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListUsers(db Querier) ([]User, error) {
+	rows, err := db.Query(`
+        SELECT name, role, COALESCE(created_at, '')
+        FROM users ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("users query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.Name, &u.Role, &u.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// AddUser creates a user with a role, or changes the role
+// of an existing user.
+//
+// Usage:
+//
+//	err := AddUser(tx, "alice", RoleClerk)
+//
+// Works with both *sql.DB and *sql.Tx.
+func AddUser(exec Execer, name, role string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("user name is empty")
+	}
+	if !ValidRole(role) {
+		return fmt.Errorf("invalid role %q", role)
+	}
+
+	_, err := exec.Exec(`
+        INSERT INTO users (name, role, created_at)
+        VALUES (?, ?, ?)
+        ON CONFLICT (name) DO UPDATE SET role = excluded.role`,
+		name, role, gen.BST().Format("2006-01-02 15:04"))
+	if err != nil {
+		return fmt.Errorf("add user failed: %v", err)
+	}
+	return nil
+}
+
+// DeleteUser removes a user and all of the user's API tokens.
+//
+// Works with both *sql.DB and *sql.Tx.
+func DeleteUser(exec Execer, name string) error {
+	_, err := exec.Exec(`DELETE FROM api_tokens WHERE user_name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("delete tokens failed: %v", err)
+	}
+	_, err = exec.Exec(`DELETE FROM users WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("delete user failed: %v", err)
+	}
+	return nil
+}
+
+// hashToken returns the stored form of an API token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIToken creates a new API token for a user and returns it.
+//
+// The token is shown only once, the database keeps its SHA-256.
+//
+// Usage:
+//
+//	token, err := CreateAPIToken(tx, "alice", "label printer")
+//	// token = "bvl_3f9c..."
+//
+// Works with both *sql.DB and *sql.Tx.
+func CreateAPIToken(exec ExecQuerier, user, label string) (string, error) {
+	if _, err := GetUser(exec, user); err != nil {
+		return "", fmt.Errorf("create token failed: %v", err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token failed: %v", err)
+	}
+	token := "bvl_" + hex.EncodeToString(buf)
+
+	_, err := exec.Exec(`
+        INSERT INTO api_tokens (user_name, token_hash, label, created_at)
+        VALUES (?, ?, ?, ?)`,
+		user, hashToken(token), label,
+		gen.BST().Format("2006-01-02 15:04"))
+	if err != nil {
+		return "", fmt.Errorf("insert token failed: %v", err)
+	}
+	return token, nil
+}
+
+// ListAPITokens returns the tokens of a user, or of all users
+// when user is empty.
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListAPITokens(db Querier, user string) ([]APIToken, error) {
+	rows, err := db.Query(`
+        SELECT id, user_name, COALESCE(label, ''),
+            COALESCE(created_at, '')
+        FROM api_tokens
+        WHERE ? = '' OR user_name = ?
+        ORDER BY id`, user, user)
+	if err != nil {
+		return nil, fmt.Errorf("tokens query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var tokens []APIToken
+	for rows.Next() {
+		var t APIToken
+		err := rows.Scan(&t.ID, &t.User, &t.Label, &t.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, nil
+}
+
+// RevokeAPIToken deletes an API token.
+//
+// Works with both *sql.DB and *sql.Tx.
+func RevokeAPIToken(exec Execer, id int) error {
+	_, err := exec.Exec(`DELETE FROM api_tokens WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("revoke token failed: %v", err)
+	}
+	return nil
+}
+
+// AuthenticateToken returns the user owning an API token.
+//
+// Returns ErrPermissionDenied for unknown tokens.
+//
+// Works with both *sql.DB and *sql.Tx.
+func AuthenticateToken(db Querier, token string) (User, error) {
+	var name string
+	err := db.QueryRow(`
+        SELECT user_name FROM api_tokens WHERE token_hash = ?`,
+		hashToken(token)).Scan(&name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return User{}, fmt.Errorf("invalid token: %w",
+				ErrPermissionDenied)
+		}
+		return User{}, fmt.Errorf("query failed: %v", err)
+	}
+	return GetUser(db, name)
+}
+
+// TokenAuth is HTTP middleware that authenticates requests with
+// an API token:
+//
+//	Authorization: Bearer bvl_3f9c...
+//
+// The user name is stored in the request context, so handlers
+// act as that user:
+//
+//	http.Handle("/api/", TokenAuth(inv, apiHandler))
+//	...
+//	err := inv.WithContext(r.Context()).EditItem(item)
+//
+// Requests without a valid token get 401 Unauthorized.
+func TokenAuth(inv *InventoryDB, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(
+			r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "missing token", http.StatusUnauthorized)
+			return
+		}
+		user, err := AuthenticateToken(inv.db, strings.TrimSpace(token))
+		if err != nil {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		ctx := NewActorContext(r.Context(), user.Name)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetUser wraps GetUser.
+//
+// Usage:
+//
+//	user, err := inv.GetUser("alice")
+func (inv *InventoryDB) GetUser(name string) (User, error) {
+	return GetUser(inv.db, name)
+}
+
+// ListUsers wraps ListUsers.
+//
+// Usage:
+//
+//	users, err := inv.ListUsers()
+func (inv *InventoryDB) ListUsers() ([]User, error) {
+	return ListUsers(inv.db)
+}
+
+// AddUser wraps AddUser with automatic transaction.
+//
+// Requires RoleAdmin, except for the very first user,
+// who must be given RoleAdmin.
+//
+// Usage:
+//
+//	err := inv.AddUser("alice", RoleClerk)
+func (inv *InventoryDB) AddUser(name, role string) error {
+	return inv.withTx("add_user", func(tx ExecQuerier) error {
+		users, err := ListUsers(tx)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 && role != RoleAdmin {
+			return fmt.Errorf("first user must have role %s", RoleAdmin)
+		}
+		return AddUser(tx, name, role)
+	})
+}
+
+// DeleteUser wraps DeleteUser with automatic transaction.
+//
+// Requires RoleAdmin.
+//
+// Usage:
+//
+//	err := inv.DeleteUser("alice")
+func (inv *InventoryDB) DeleteUser(name string) error {
+	return inv.withTx("delete_user", func(tx ExecQuerier) error {
+		return DeleteUser(tx, name)
+	})
+}
+
+// CreateAPIToken wraps CreateAPIToken with automatic transaction.
+//
+// Requires RoleAdmin.
+//
+// Usage:
+//
+//	token, err := inv.CreateAPIToken("alice", "web ui")
+func (inv *InventoryDB) CreateAPIToken(user, label string) (string, error) {
+	var token string
+	err := inv.withTx("create_api_token", func(tx ExecQuerier) error {
+		var err error
+		token, err = CreateAPIToken(tx, user, label)
+		return err
+	})
+	return token, err
+}
+
+// ListAPITokens wraps ListAPITokens.
+//
+// Usage:
+//
+//	tokens, err := inv.ListAPITokens("alice")
+func (inv *InventoryDB) ListAPITokens(user string) ([]APIToken, error) {
+	return ListAPITokens(inv.db, user)
+}
+
+// RevokeAPIToken wraps RevokeAPIToken with automatic transaction.
+//
+// Requires RoleAdmin.
+//
+// Usage:
+//
+//	err := inv.RevokeAPIToken(id)
+func (inv *InventoryDB) RevokeAPIToken(id int) error {
+	return inv.withTx("revoke_api_token", func(tx ExecQuerier) error {
+		return RevokeAPIToken(tx, id)
+	})
+}
+
+// AuthenticateToken wraps AuthenticateToken.
+//
+// Usage:
+//
+//	user, err := inv.AuthenticateToken(token)
+func (inv *InventoryDB) AuthenticateToken(token string) (User, error) {
+	return AuthenticateToken(inv.db, token)
+}
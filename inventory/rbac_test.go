@@ -0,0 +1,218 @@
+// rbac_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for role-based access control and API tokens
+// Uses in-memory SQLite DB and httptest
+//
+
+package inventory_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func setupRBAC(t *testing.T) *inventory.InventoryDB {
+	inv := setupInventoryDB(t)
+
+	if err := inv.AddUser("carol", inventory.RoleViewer); err == nil {
+		t.Fatalf("expected error for non-admin first user")
+	}
+	if err := inv.AddUser("root", inventory.RoleAdmin); err != nil {
+		t.Fatalf("AddUser failed: %v", err)
+	}
+
+	admin := inv.WithActor("root")
+	for name, role := range map[string]string{
+		"vera": inventory.RoleViewer,
+		"cleo": inventory.RoleClerk,
+		"mani": inventory.RoleManager,
+	} {
+		if err := admin.AddUser(name, role); err != nil {
+			t.Fatalf("AddUser failed: %v", err)
+		}
+	}
+	err := admin.AppendItem(inventory.Item{ID: 1001, Description: "UPS"})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+	return inv
+}
+
+func TestInventoryDB_RBAC_Permissions(t *testing.T) {
+	inv := setupRBAC(t)
+	defer inv.Close()
+
+	item := inventory.Item{ID: 1001, Description: "UPS", Remarks: "x"}
+	tests := []struct {
+		actor string
+		op    func(inv *inventory.InventoryDB) error
+		ok    bool
+	}{
+		{"vera", func(i *inventory.InventoryDB) error {
+			return i.EditItem(item)
+		}, false},
+		{"cleo", func(i *inventory.InventoryDB) error {
+			return i.EditItem(item)
+		}, true},
+		{"cleo", func(i *inventory.InventoryDB) error {
+			return i.DeleteItem(1001)
+		}, false},
+		{"cleo", func(i *inventory.InventoryDB) error {
+			return i.ImportJSONFromString(`[{"id": 1001}]`)
+		}, false},
+		{"mani", func(i *inventory.InventoryDB) error {
+			return i.ResetSequence()
+		}, false},
+		{"mani", func(i *inventory.InventoryDB) error {
+			return i.AddUser("eve", inventory.RoleAdmin)
+		}, false},
+		{"stranger", func(i *inventory.InventoryDB) error {
+			return i.AddItem(item)
+		}, false},
+		{"", func(i *inventory.InventoryDB) error {
+			return i.AddItem(item)
+		}, false},
+		{"mani", func(i *inventory.InventoryDB) error {
+			return i.DeleteItem(1001)
+		}, true},
+		{"root", func(i *inventory.InventoryDB) error {
+			return i.ResetSequence()
+		}, true},
+	}
+
+	for i, tc := range tests {
+		err := tc.op(inv.WithActor(tc.actor))
+		if tc.ok && err != nil {
+			t.Errorf("case %d (%s): unexpected error %v", i, tc.actor, err)
+		}
+		if !tc.ok {
+			var perr *inventory.PermissionError
+			if !errors.Is(err, inventory.ErrPermissionDenied) ||
+				!errors.As(err, &perr) {
+				t.Errorf("case %d (%s): expected PermissionError, got %v",
+					i, tc.actor, err)
+			}
+		}
+	}
+}
+
+func TestInventoryDB_APIToken(t *testing.T) {
+	inv := setupRBAC(t)
+	defer inv.Close()
+
+	_, err := inv.WithActor("mani").CreateAPIToken("cleo", "scanner")
+	if !errors.Is(err, inventory.ErrPermissionDenied) {
+		t.Errorf("expected permission error, got %v", err)
+	}
+
+	token, err := inv.WithActor("root").CreateAPIToken("cleo", "scanner")
+	if err != nil {
+		t.Fatalf("CreateAPIToken failed: %v", err)
+	}
+
+	user, err := inv.AuthenticateToken(token)
+	if err != nil || user.Name != "cleo" {
+		t.Fatalf("AuthenticateToken failed: %v %v", user, err)
+	}
+	if _, err := inv.AuthenticateToken("bvl_wrong"); err == nil {
+		t.Errorf("expected error for unknown token")
+	}
+
+	tokens, _ := inv.ListAPITokens("cleo")
+	if len(tokens) != 1 || tokens[0].Label != "scanner" {
+		t.Fatalf("unexpected tokens: %+v", tokens)
+	}
+	if err := inv.WithActor("root").RevokeAPIToken(tokens[0].ID); err != nil {
+		t.Fatalf("RevokeAPIToken failed: %v", err)
+	}
+	if _, err := inv.AuthenticateToken(token); err == nil {
+		t.Errorf("expected error for revoked token")
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	inv := setupRBAC(t)
+	defer inv.Close()
+
+	token, err := inv.WithActor("root").CreateAPIToken("cleo", "web")
+	if err != nil {
+		t.Fatalf("CreateAPIToken failed: %v", err)
+	}
+
+	handler := inventory.TokenAuth(inv, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			err := inv.WithContext(r.Context()).AppendRemarksEntry(
+				1001, "checked via api")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+			}
+		}))
+
+	req := httptest.NewRequest("POST", "/api/items/1001", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", rec.Code)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 with token, got %d: %s",
+			rec.Code, rec.Body.String())
+	}
+
+	entries, _ := inv.ListAudit(inventory.AuditQuery{Actor: "cleo"})
+	if len(entries) != 1 {
+		t.Errorf("expected audit entry by cleo, got %+v", entries)
+	}
+}
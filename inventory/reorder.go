@@ -0,0 +1,291 @@
+// reorder.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Reorder points and low-stock reporting for Inventory CLI
+// Lists consumables at or below their reorder level and drafts
+// a purchase list that can be exported as CSV.
+//
+
+package inventory
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// PurchaseLine is one line of a draft purchase list.
+//
+// Fields:
+//
+//	ItemID       - inventory item to reorder
+//	Description  - item description
+//	Location     - where the stock is kept
+//	OnHand       - current quantity
+//	ReorderLevel - reorder level of the item
+//	OrderQty     - units to order
+type PurchaseLine struct {
+	ItemID       int    `json:"item_id"`
+	Description  string `json:"description"`
+	Location     string `json:"location"`
+	OnHand       int    `json:"on_hand"`
+	ReorderLevel int    `json:"reorder_level"`
+	OrderQty     int    `json:"order_qty"`
+}
+
+// ListLowStock returns the items at or below their reorder level,
+// sorted by ID.
+//
+// Only items with a reorder quantity are considered, so assets
+// and consumables that are not reordered never show up.
+//
+// Usage:
+//
+//	items, err := ListLowStock(db)
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListLowStock(db Querier) ([]Item, error) {
+	rows, err := db.Query(`
+        SELECT ` + itemFields + `
+        FROM inventory
+        WHERE reorder_qty > 0 AND quantity <= reorder_level
+        ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("low stock query failed: %v", err)
+	}
+	return scanAllItems(rows)
+}
+
+// WriteLowStockReport prints low-stock items as text columns:
+//
+//	1004   UPS battery 12V      Store 2         0 / 2
+//
+// Each line ends with the quantity on hand and the reorder level.
+// Errors are returned if writing fails.
+func WriteLowStockReport(w io.Writer, items []Item) error {
+	for _, item := range items {
+		_, err := fmt.Fprintf(w, "%-6d %-20s %-15s %d / %d\n",
+			item.ID, item.Description, item.Location,
+			item.Quantity, item.ReorderLevel)
+		if err != nil {
+			return fmt.Errorf("write report failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// DraftPurchaseList proposes a purchase for every low-stock item.
+//
+// The order quantity is the reorder quantity, raised when that
+// would still leave the stock at or below the reorder level.
+//
+// Usage:
+//
+//	lines, err := DraftPurchaseList(db)
+//	err = WritePurchaseListCSV(os.Stdout, lines)
+//
+// Works with both *sql.DB and *sql.Tx.
+func DraftPurchaseList(db Querier) ([]PurchaseLine, error) {
+	items, err := ListLowStock(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []PurchaseLine
+	for _, item := range items {
+		qty := item.ReorderQty
+		if short := item.ReorderLevel - item.Quantity + 1; qty < short {
+			qty = short
+		}
+		lines = append(lines, PurchaseLine{
+			ItemID:       item.ID,
+			Description:  item.Description,
+			Location:     item.Location,
+			OnHand:       item.Quantity,
+			ReorderLevel: item.ReorderLevel,
+			OrderQty:     qty,
+		})
+	}
+	return lines, nil
+}
+
+// purchaseHeader lists the CSV columns of a purchase list.
+var purchaseHeader = []string{
+	"item_id", "description", "location",
+	"on_hand", "reorder_level", "order_qty",
+}
+
+// WritePurchaseListCSV writes a purchase list as CSV with a
+// header row.
+//
+// Errors are returned if writing fails.
+func WritePurchaseListCSV(w io.Writer, lines []PurchaseLine) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(purchaseHeader); err != nil {
+		return fmt.Errorf("write csv header failed: %v", err)
+	}
+	for _, l := range lines {
+		err := writer.Write([]string{
+			strconv.Itoa(l.ItemID),
+			l.Description,
+			l.Location,
+			strconv.Itoa(l.OnHand),
+			strconv.Itoa(l.ReorderLevel),
+			strconv.Itoa(l.OrderQty),
+		})
+		if err != nil {
+			return fmt.Errorf("write csv row failed: %v", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write csv failed: %v", err)
+	}
+	return nil
+}
+
+// ExportPurchaseListCSV drafts the purchase list and writes it
+// to a CSV file. Existing file will be overwritten.
+//
+// Usage:
+//
+//	err := ExportPurchaseListCSV(db, "reorder.csv")
+//
+// Works with both *sql.DB and *sql.Tx.
+func ExportPurchaseListCSV(db Querier, filename string) error {
+	lines, err := DraftPurchaseList(db)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create csv: %v", err)
+	}
+	defer file.Close()
+
+	return WritePurchaseListCSV(file, lines)
+}
+
+// AdjustQuantity changes the quantity on hand of an item by delta
+// and logs the change in its remarks.
+//
+// Usage:
+//
+//	err := AdjustQuantity(tx, 1004, -1, "issued to Rack 3")
+//
+// Resulting remarks entry:
+//
+//	[2025-06-20 16:55] quantity 3 → 2: issued to Rack 3
+//
+// Errors are returned if the item does not exist, the
+// quantity would become negative, or the item has stock
+// balances (use ReceiveStock() or IssueStock() then).
+//
+// Works with both *sql.DB and *sql.Tx.
+func AdjustQuantity(exec ExecQuerier, id, delta int, note string) error {
+	item, err := GetItemByID(exec, id)
+	if err != nil {
+		return err
+	}
+	qty := item.Quantity + delta
+	if qty < 0 {
+		return fmt.Errorf("item %d has only %d on hand", id, item.Quantity)
+	}
+
+	_, err = exec.Exec(`
+        UPDATE inventory SET quantity = ? WHERE id = ?`, qty, id)
+	if err != nil {
+		return fmt.Errorf("adjust quantity failed: %w",
+			triggerError(err))
+	}
+
+	msg := fmt.Sprintf("quantity %d → %d", item.Quantity, qty)
+	if note != "" {
+		msg += ": " + note
+	}
+	return AppendRemarksEntry(exec, id, msg)
+}
+
+// ListLowStock wraps ListLowStock.
+//
+// Usage:
+//
+//	items, err := inv.ListLowStock()
+func (inv *InventoryDB) ListLowStock() ([]Item, error) {
+	return ListLowStock(inv.db)
+}
+
+// DraftPurchaseList wraps DraftPurchaseList.
+//
+// Usage:
+//
+//	lines, err := inv.DraftPurchaseList()
+func (inv *InventoryDB) DraftPurchaseList() ([]PurchaseLine, error) {
+	return DraftPurchaseList(inv.db)
+}
+
+// ExportPurchaseListCSV wraps ExportPurchaseListCSV.
+//
+// Usage:
+//
+//	err := inv.ExportPurchaseListCSV("reorder.csv")
+func (inv *InventoryDB) ExportPurchaseListCSV(filename string) error {
+	return ExportPurchaseListCSV(inv.db, filename)
+}
+
+// AdjustQuantity wraps AdjustQuantity with automatic transaction.
+//
+// Usage:
+//
+//	err := inv.AdjustQuantity(1004, 10, "received PO 118")
+func (inv *InventoryDB) AdjustQuantity(id, delta int, note string) error {
+	return inv.withTx("adjust_quantity", func(tx ExecQuerier) error {
+		return AdjustQuantity(tx, id, delta, note)
+	})
+}
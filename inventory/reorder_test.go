@@ -0,0 +1,159 @@
+// reorder_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for reorder points and low-stock reporting
+// Uses in-memory SQLite DB and temp files
+//
+
+package inventory_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func setupReorder(t *testing.T) *inventory.InventoryDB {
+	inv := setupInventoryDB(t)
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "UPS", Quantity: 1},
+		{ID: 1002, Description: "Battery 12V", Location: "Store",
+			Quantity: 1, ReorderLevel: 2, ReorderQty: 6},
+		{ID: 1003, Description: "Fuse 5A", Location: "Store",
+			Quantity: 0, ReorderLevel: 10, ReorderQty: 5},
+		{ID: 1004, Description: "Cable tie", Location: "Store",
+			Quantity: 50, ReorderLevel: 20, ReorderQty: 100},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+	return inv
+}
+
+func TestInventoryDB_ListLowStock(t *testing.T) {
+	inv := setupReorder(t)
+	defer inv.Close()
+
+	items, err := inv.ListLowStock()
+	if err != nil {
+		t.Fatalf("ListLowStock failed: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != 1002 || items[1].ID != 1003 {
+		t.Fatalf("unexpected low stock: %+v", items)
+	}
+	if items[0].Quantity != 1 || items[0].ReorderQty != 6 {
+		t.Errorf("stock levels not stored: %+v", items[0])
+	}
+
+	var buf bytes.Buffer
+	if err := inventory.WriteLowStockReport(&buf, items); err != nil {
+		t.Fatalf("WriteLowStockReport failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "0 / 10") {
+		t.Errorf("unexpected report:\n%s", buf.String())
+	}
+}
+
+func TestInventoryDB_ExportPurchaseListCSV(t *testing.T) {
+	inv := setupReorder(t)
+	defer inv.Close()
+
+	lines, err := inv.DraftPurchaseList()
+	if err != nil {
+		t.Fatalf("DraftPurchaseList failed: %v", err)
+	}
+	// Fuse: 5 would leave 5 <= 10 in stock, so 11 are ordered
+	if len(lines) != 2 || lines[0].OrderQty != 6 || lines[1].OrderQty != 11 {
+		t.Errorf("unexpected purchase list: %+v", lines)
+	}
+
+	path := filepath.Join(t.TempDir(), "reorder.csv")
+	if err := inv.ExportPurchaseListCSV(path); err != nil {
+		t.Fatalf("ExportPurchaseListCSV failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read csv failed: %v", err)
+	}
+	want := "item_id,description,location,on_hand,reorder_level,order_qty\n" +
+		"1002,Battery 12V,Store,1,2,6\n" +
+		"1003,Fuse 5A,Store,0,10,11\n"
+	if string(data) != want {
+		t.Errorf("unexpected csv:\n%s", data)
+	}
+}
+
+func TestInventoryDB_AdjustQuantity(t *testing.T) {
+	inv := setupReorder(t)
+	defer inv.Close()
+
+	if err := inv.AdjustQuantity(1004, -30, "issued to Lab"); err != nil {
+		t.Fatalf("AdjustQuantity failed: %v", err)
+	}
+	item, _ := inv.GetItemByID(1004)
+	if item.Quantity != 20 ||
+		!strings.Contains(item.Remarks, "quantity 50 → 20: issued to Lab") {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	if err := inv.AdjustQuantity(1004, -21, ""); err == nil {
+		t.Error("expected error for negative quantity")
+	}
+	err := inv.AppendItem(inventory.Item{ID: 1005, Quantity: -1})
+	if err == nil {
+		t.Error("expected error for negative stored quantity")
+	}
+
+	items, _ := inv.ListLowStock()
+	if len(items) != 3 {
+		t.Errorf("expected item at reorder level in report: %+v", items)
+	}
+}
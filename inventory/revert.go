@@ -0,0 +1,380 @@
+// revert.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Changesets and revert for Inventory CLI
+// Groups the audit log by InventoryDB operation and restores
+// the item rows an operation changed.
+//
+
+package inventory
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// changesetsTable is the DDL of the changesets table.
+//
+// Every InventoryDB operation opens a changeset (see withTx),
+// and the audit_log rows it writes carry its ID. reverted_by is
+// the changeset that reverted it, NULL if none.
+const changesetsTable = `
+    CREATE TABLE IF NOT EXISTS changesets (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        ts TEXT NOT NULL,
+        actor TEXT,
+        operation TEXT,
+        reverted_by INTEGER
+    );`
+
+// auditLogChangesetIndex finds the rows of a changeset.
+// OpenDB creates it once audit_log has the changeset_id column.
+const auditLogChangesetIndex = `
+    CREATE INDEX IF NOT EXISTS audit_log_changeset
+    ON audit_log (changeset_id);`
+
+// ErrRevertConflict is matched by the error Revert() returns when
+// an item of the changeset was changed again later, use
+// errors.Is(err, ErrRevertConflict).
+var ErrRevertConflict = errors.New("revert conflict")
+
+// Changeset is one InventoryDB operation.
+//
+// Fields:
+//
+//	ID         - changeset number, increases with every operation
+//	Time       - when, in AuditTimeLayout
+//	Actor      - who
+//	Operation  - InventoryDB method, e.g. "bulk_update"
+//	Changes    - number of item rows changed
+//	RevertedBy - changeset that reverted this one, 0 if none
+type Changeset struct {
+	ID         int    `json:"id"`
+	Time       string `json:"time"`
+	Actor      string `json:"actor"`
+	Operation  string `json:"operation"`
+	Changes    int    `json:"changes"`
+	RevertedBy int    `json:"reverted_by,omitempty"`
+}
+
+// changesetQuery selects changesets with their change count.
+const changesetQuery = `
+    SELECT c.id, c.ts, COALESCE(c.actor, ''), COALESCE(c.operation, ''),
+        (SELECT COUNT(*) FROM audit_log a
+         WHERE a.changeset_id = c.id AND a.item_id IS NOT NULL),
+        COALESCE(c.reverted_by, 0)
+    FROM changesets c`
+
+// scanChangesets reads rows of changesetQuery.
+func scanChangesets(rows *sql.Rows) ([]Changeset, error) {
+	defer rows.Close()
+	var list []Changeset
+	for rows.Next() {
+		var c Changeset
+		err := rows.Scan(&c.ID, &c.Time, &c.Actor, &c.Operation,
+			&c.Changes, &c.RevertedBy)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// ListChangesets returns the latest changesets that changed
+// items, newest first. A limit of 0 returns all.
+//
+// Usage:
+//
+//	list, err := ListChangesets(db, 10)
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListChangesets(db Querier, limit int) ([]Changeset, error) {
+	query := changesetQuery + `
+    WHERE EXISTS (SELECT 1 FROM audit_log a
+        WHERE a.changeset_id = c.id AND a.item_id IS NOT NULL)
+    ORDER BY c.id DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("changeset query failed: %v", err)
+	}
+	return scanChangesets(rows)
+}
+
+// GetChangeset returns a changeset by ID.
+//
+// Works with both *sql.DB and *sql.Tx.
+func GetChangeset(db Querier, id int) (Changeset, error) {
+	rows, err := db.Query(changesetQuery+` WHERE c.id = ?`, id)
+	if err != nil {
+		return Changeset{}, fmt.Errorf("changeset query failed: %v", err)
+	}
+	list, err := scanChangesets(rows)
+	if err != nil {
+		return Changeset{}, err
+	}
+	if len(list) == 0 {
+		return Changeset{}, fmt.Errorf("changeset %d not found", id)
+	}
+	return list[0], nil
+}
+
+// restoreItem writes an item row back exactly as it was,
+// with its original ID, parent and remarks.
+func restoreItem(exec Execer, item Item) error {
+	parent := sql.NullInt64{
+		Int64: int64(item.ParentID), Valid: item.ParentID != 0,
+	}
+	args := []interface{}{
+		item.Description, item.Location, item.Status, item.Remarks,
+		parent, item.PurchaseDate, item.WarrantyUntil, item.ExpiryDate,
+		item.Quantity, item.ReorderLevel, item.ReorderQty,
+		item.UnitCost, item.ID,
+	}
+	res, err := exec.Exec(`
+        UPDATE inventory
+        SET description = ?, location = ?, status = ?, remarks = ?,
+            parent_id = ?, purchase_date = NULLIF(?, ''),
+            warranty_until = NULLIF(?, ''), expiry_date = NULLIF(?, ''),
+            quantity = ?, reorder_level = ?, reorder_qty = ?,
+            unit_cost = ?
+        WHERE id = ?`, args...)
+	if err != nil {
+		return fmt.Errorf("restore item %d failed: %w",
+			item.ID, triggerError(err))
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+
+	_, err = exec.Exec(`
+        INSERT INTO inventory
+        (description, location, status, remarks, parent_id,
+         purchase_date, warranty_until, expiry_date,
+         quantity, reorder_level, reorder_qty, unit_cost, id)
+        VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''),
+            NULLIF(?, ''), ?, ?, ?, ?, ?)`, args...)
+	if err != nil {
+		return fmt.Errorf("restore item %d failed: %w",
+			item.ID, triggerError(err))
+	}
+	return nil
+}
+
+// Revert restores the item rows changed by a changeset to their
+// state before it: inserted items are removed, and updated,
+// replaced or deleted items are written back with their original
+// IDs and remarks.
+//
+// Revert refuses, with an error matching ErrRevertConflict, when
+// any of the items is no longer as the changeset left it, and
+// refuses a changeset that was already reverted. Run it inside a
+// transaction (see InventoryDB.Revert); the revert is then a
+// changeset of its own and can be reverted in turn.
+//
+// Usage:
+//
+//	err := Revert(tx, 42)
+//
+// Notes:
+//   - Item rows are restored as recorded in the audit log
+//   - Stock balances removed by DeleteItem() are restored from
+//     the movement log, and removed again with a reverted insert
+//   - Attachments and maintenance plans removed by DeleteItem()
+//     are not restored
+//   - ResetSequence() is not reverted
+//   - Works with both *sql.DB and *sql.Tx.
+func Revert(exec ExecQuerier, id int) error {
+	cs, err := GetChangeset(exec, id)
+	if err != nil {
+		return err
+	}
+	if cs.RevertedBy != 0 {
+		return fmt.Errorf("changeset %d was reverted by %d",
+			id, cs.RevertedBy)
+	}
+	entries, err := ListAudit(exec, AuditQuery{Changeset: id})
+	if err != nil {
+		return err
+	}
+
+	// The last entry of each item holds its state after the
+	// changeset; the item must still be in that state.
+	last := make(map[int]*Item)
+	for _, e := range entries {
+		if e.ItemID != 0 {
+			last[e.ItemID] = e.After
+		}
+	}
+	if len(last) == 0 {
+		return fmt.Errorf("changeset %d has no item changes", id)
+	}
+
+	var conflicts []int
+	for itemID, after := range last {
+		item, err := GetItemByID(exec, itemID)
+		switch {
+		case errors.Is(err, ErrItemNotFound):
+			if after != nil {
+				conflicts = append(conflicts, itemID)
+			}
+		case err != nil:
+			return err
+		case after == nil || item != *after:
+			conflicts = append(conflicts, itemID)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Ints(conflicts)
+		return fmt.Errorf("%w: items %v changed after changeset %d",
+			ErrRevertConflict, conflicts, id)
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		switch {
+		case e.ItemID == 0:
+			continue
+		case e.Before == nil:
+			_, err = exec.Exec(`DELETE FROM inventory WHERE id = ?`,
+				e.ItemID)
+			if err != nil {
+				err = fmt.Errorf("remove item %d failed: %v", e.ItemID, err)
+			} else {
+				err = removeBalances(exec, e.ItemID)
+			}
+		case e.After == nil:
+			err = restoreBalances(exec, e.ItemID)
+			if err == nil {
+				err = restoreItem(exec, *e.Before)
+			}
+		default:
+			err = restoreItem(exec, *e.Before)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = exec.Exec(`
+        UPDATE changesets
+        SET reverted_by = (SELECT changeset_id FROM audit_session)
+        WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("update changeset failed: %v", err)
+	}
+	return nil
+}
+
+// lastChangeset returns the latest changeset of the actor that
+// changed items, is not a revert and was not reverted.
+func lastChangeset(db Querier, actor string) (int, error) {
+	var id int
+	err := db.QueryRow(`
+        SELECT c.id FROM changesets c
+        WHERE c.actor = ? AND c.operation != 'revert'
+            AND c.reverted_by IS NULL
+            AND EXISTS (SELECT 1 FROM audit_log a
+                WHERE a.changeset_id = c.id AND a.item_id IS NOT NULL)
+        ORDER BY c.id DESC LIMIT 1`, actor).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("nothing to undo for %s", actor)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("changeset query failed: %v", err)
+	}
+	return id, nil
+}
+
+// ListChangesets wraps ListChangesets.
+//
+// Usage:
+//
+//	list, err := inv.ListChangesets(10)
+func (inv *InventoryDB) ListChangesets(limit int) ([]Changeset, error) {
+	return ListChangesets(inv.db, limit)
+}
+
+// Revert wraps Revert with automatic transaction.
+//
+// Usage:
+//
+//	err := inv.Revert(42)
+func (inv *InventoryDB) Revert(id int) error {
+	return inv.withTx("revert", func(tx ExecQuerier) error {
+		return Revert(tx, id)
+	})
+}
+
+// Undo reverts the latest operation of the actor that changed
+// items and returns its changeset ID, like `bvl undo`.
+//
+// Reverts and reverted changesets are skipped, so repeated calls
+// walk back through the actor's history. To redo, revert the
+// revert changeset.
+//
+// Usage:
+//
+//	id, err := inv.WithActor("alice").Undo()
+func (inv *InventoryDB) Undo() (int, error) {
+	var id int
+	err := inv.withTx("revert", func(tx ExecQuerier) error {
+		var err error
+		if id, err = lastChangeset(tx, inv.Actor()); err != nil {
+			return err
+		}
+		return Revert(tx, id)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
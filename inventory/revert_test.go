@@ -0,0 +1,224 @@
+// revert_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for changesets, revert and undo
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestInventoryDB_Revert(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+	alice := inv.WithActor("alice")
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "Switch", Location: "Rack 4",
+			Status: "Installed", Remarks: "racked"},
+		{ID: 1002, Description: "Router", Location: "Rack 4",
+			Status: "Installed"},
+	} {
+		if err := alice.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+	original, _ := inv.GetItemByID(1001)
+
+	status := "Decommissioned"
+	_, err := alice.BulkUpdate(inventory.ItemFilter{Location: "Rack 4"},
+		inventory.ItemPatch{Status: &status}, "rack retired")
+	if err != nil {
+		t.Fatalf("BulkUpdate failed: %v", err)
+	}
+	if err := alice.DeleteItem(1001); err != nil {
+		t.Fatalf("DeleteItem failed: %v", err)
+	}
+
+	list, err := inv.ListChangesets(0)
+	if err != nil {
+		t.Fatalf("ListChangesets failed: %v", err)
+	}
+	if len(list) != 4 || list[0].Operation != "delete_item" ||
+		list[1].Operation != "bulk_update" || list[1].Changes != 2 {
+		t.Fatalf("unexpected changesets: %+v", list)
+	}
+	bulk := list[1].ID
+
+	// The bulk update conflicts with the later delete of 1001
+	err = inv.Revert(bulk)
+	if !errors.Is(err, inventory.ErrRevertConflict) {
+		t.Errorf("expected ErrRevertConflict, got %v", err)
+	}
+
+	id, err := alice.Undo()
+	if err != nil || id != list[0].ID {
+		t.Fatalf("Undo failed: %d %v", id, err)
+	}
+	got, err := inv.GetItemByID(1001)
+	if err != nil || got.Status != "Decommissioned" {
+		t.Fatalf("deleted item not restored: %+v %v", got, err)
+	}
+
+	id, err = alice.Undo()
+	if err != nil || id != bulk {
+		t.Fatalf("second Undo failed: %d %v", id, err)
+	}
+	got, _ = inv.GetItemByID(1001)
+	if got != original {
+		t.Errorf("item not reverted:\n%+v\n%+v", got, original)
+	}
+
+	if err := inv.Revert(bulk); err == nil {
+		t.Error("expected error reverting twice")
+	}
+	if err := inv.Revert(9999); err == nil {
+		t.Error("expected error for unknown changeset")
+	}
+
+	// Undo the two appends, then nothing is left
+	for i := 0; i < 2; i++ {
+		if _, err := alice.Undo(); err != nil {
+			t.Fatalf("Undo append failed: %v", err)
+		}
+	}
+	items, _ := inv.ListAll()
+	if len(items) != 0 {
+		t.Errorf("appended items not removed: %+v", items)
+	}
+	if _, err := alice.Undo(); err == nil {
+		t.Error("expected error with nothing to undo")
+	}
+
+	entries, _ := inv.ListAudit(inventory.AuditQuery{Changeset: bulk})
+	if len(entries) != 2 || entries[0].Changeset != bulk {
+		t.Errorf("unexpected changeset entries: %+v", entries)
+	}
+}
+
+func TestInventoryDB_Revert_StockBalances(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	err := inv.AppendItem(inventory.Item{ID: 1001, Description: "Cable",
+		Location: "Store 1", Status: "Stock"})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+	if err := inv.ReceiveStock(1001, "Store 1", 8, ""); err != nil {
+		t.Fatalf("ReceiveStock failed: %v", err)
+	}
+	if err := inv.Transfer(1001, "Store 1", "Store 2", 3, ""); err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	want, _ := inv.ItemBalances(1001)
+
+	if err := inv.DeleteItem(1001); err != nil {
+		t.Fatalf("DeleteItem failed: %v", err)
+	}
+	if got, _ := inv.ItemBalances(1001); len(got) != 0 {
+		t.Fatalf("balances left after delete: %+v", got)
+	}
+
+	id, err := inv.Undo()
+	if err != nil {
+		t.Fatalf("Undo delete failed: %v", err)
+	}
+	got, _ := inv.ItemBalances(1001)
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("balances not restored:\n%+v\n%+v", got, want)
+	}
+	if item, _ := inv.GetItemByID(1001); item.Quantity != 8 {
+		t.Errorf("quantity not restored: %+v", item)
+	}
+
+	// Reverting the revert deletes the item and its balances again
+	list, _ := inv.ListChangesets(1)
+	if err := inv.Revert(list[0].ID); err != nil {
+		t.Fatalf("Revert of revert %d failed: %v", id, err)
+	}
+	if got, _ := inv.ItemBalances(1001); len(got) != 0 {
+		t.Errorf("balances left after redo: %+v", got)
+	}
+}
+
+func TestInventoryDB_Undo_UnitCost(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	third := 1.0 / 3
+	err := inv.AddItem(inventory.Item{Description: "Resistor",
+		Status: "Stock", UnitCost: third})
+	if err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	items, _ := inv.ListAll()
+	if len(items) != 1 {
+		t.Fatalf("expected one item, got %+v", items)
+	}
+	id := items[0].ID
+
+	status := "Reserved"
+	if _, err := inv.UpdateItem(id, inventory.ItemPatch{
+		Status: &status,
+	}); err != nil {
+		t.Fatalf("UpdateItem failed: %v", err)
+	}
+
+	if _, err := inv.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	item, _ := inv.GetItemByID(id)
+	if item.Status != "Stock" || item.UnitCost != third {
+		t.Errorf("item not restored: %+v", item)
+	}
+}
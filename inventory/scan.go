@@ -0,0 +1,274 @@
+// scan.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Barcode scanner quick-entry mode for Inventory CLI
+// Reads scanned item IDs line by line and applies a preset
+// action (move, set status, check in/out, remark) to each.
+//
+
+package inventory
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Kinds of ScanAction.
+const (
+	ScanLookup      = "lookup"
+	ScanSetLocation = "location"
+	ScanSetStatus   = "status"
+	ScanCheckOut    = "checkout"
+	ScanCheckIn     = "checkin"
+	ScanRemark      = "remark"
+)
+
+// Statuses set by ScanCheckOut and ScanCheckIn.
+const (
+	StatusCheckedOut = "Checked Out"
+	StatusAvailable  = "Available"
+)
+
+// ScanAction is applied to every scanned item.
+//
+// Value depends on the kind:
+//
+//	lookup   - unused, the item is only shown
+//	location - the new location
+//	status   - the new status
+//	checkout - who or where the item goes to (optional)
+//	checkin  - the location it is returned to (optional)
+//	remark   - the remarks entry to append
+type ScanAction struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value,omitempty"`
+}
+
+// ParseScanAction parses an action given as "kind" or
+// "kind=value", e.g. "location=Rack 5" or "checkin".
+//
+// Errors are returned for unknown kinds and missing values.
+func ParseScanAction(s string) (ScanAction, error) {
+	kind, value, _ := strings.Cut(s, "=")
+	a := ScanAction{
+		Kind:  strings.ToLower(strings.TrimSpace(kind)),
+		Value: strings.TrimSpace(value),
+	}
+	switch a.Kind {
+	case ScanLookup, ScanCheckOut, ScanCheckIn:
+	case ScanSetLocation, ScanSetStatus, ScanRemark:
+		if a.Value == "" {
+			return a, fmt.Errorf("scan action %q needs a value", a.Kind)
+		}
+	default:
+		return a, fmt.Errorf("unknown scan action %q", a.Kind)
+	}
+	return a, nil
+}
+
+// ApplyScanAction applies the action to one item and logs the
+// change in its remarks.
+//
+// Usage:
+//
+//	a := ScanAction{Kind: ScanSetLocation, Value: "Rack 5"}
+//	item, err := ApplyScanAction(tx, 1002, a)
+//
+// Resulting remarks entry:
+//
+//	[2025-06-20 16:55] scan: location Rack 1 → Rack 5
+//
+// Returns the item as stored after the change. A lookup only
+// reads the item.
+//
+// Works with both *sql.DB and *sql.Tx.
+func ApplyScanAction(exec ExecQuerier, id int, a ScanAction) (Item, error) {
+	item, err := GetItemByID(exec, id)
+	if err != nil || a.Kind == ScanLookup {
+		return item, err
+	}
+
+	location, status := item.Location, item.Status
+	var note string
+	switch a.Kind {
+	case ScanSetLocation:
+		location = a.Value
+		note = fmt.Sprintf("location %s → %s", item.Location, location)
+	case ScanSetStatus:
+		status = a.Value
+		note = fmt.Sprintf("status %s → %s", item.Status, status)
+	case ScanCheckOut:
+		status = StatusCheckedOut
+		note = "checked out"
+		if a.Value != "" {
+			note += " to " + a.Value
+		}
+	case ScanCheckIn:
+		status = StatusAvailable
+		note = "checked in"
+		if a.Value != "" {
+			location = a.Value
+			note += " at " + a.Value
+		}
+	case ScanRemark:
+		note = a.Value
+	default:
+		return item, fmt.Errorf("unknown scan action %q", a.Kind)
+	}
+
+	_, err = exec.Exec(`
+        UPDATE inventory SET location = ?, status = ?
+        WHERE id = ?`, location, status, id)
+	if err != nil {
+		return item, fmt.Errorf("scan update failed: %v", err)
+	}
+	if err := AppendRemarksEntry(exec, id, "scan: "+note); err != nil {
+		return item, err
+	}
+	return GetItemByID(exec, id)
+}
+
+// ScanSummary counts the outcome of a scan session.
+type ScanSummary struct {
+	Scanned  int `json:"scanned"`
+	Applied  int `json:"applied"`
+	NotFound int `json:"not_found"`
+	Invalid  int `json:"invalid"`
+	Rejected int `json:"rejected"`
+}
+
+// Scan runs the quick-entry loop of `bvl scan`.
+//
+// Every non-blank line read from r is taken as an item ID,
+// as typed by a USB barcode scanner followed by Enter.
+// The item is looked up, the action applied in its own
+// transaction, and one result line written to w:
+//
+//	1002   UPS 3KVA             Rack 5       Operational
+//	1009   not found
+//	abc    not an item ID
+//
+// Unknown IDs and bad input are reported and skipped, so one
+// bad scan does not stop the session. Reading stops at EOF.
+//
+// Usage:
+//
+//	a, _ := ParseScanAction("location=Rack 5")
+//	sum, err := inv.Scan(os.Stdin, os.Stdout, a)
+//
+// Errors are returned for database failures, denied
+// permissions and read or write errors.
+func (inv *InventoryDB) Scan(r io.Reader, w io.Writer,
+	a ScanAction) (ScanSummary, error) {
+	return scanLines(r, w, func(id int) (Item, bool, error) {
+		if a.Kind == ScanLookup {
+			item, err := GetItemByID(inv.db, id)
+			return item, false, err
+		}
+		var item Item
+		err := inv.withTx("scan", func(tx ExecQuerier) error {
+			var err error
+			item, err = ApplyScanAction(tx, id, a)
+			return err
+		})
+		return item, err == nil, err
+	})
+}
+
+// scanLines runs a scan loop over the lines of r, calling fn for
+// every item ID and writing one result line per scan to w.
+//
+// fn reports whether it changed anything, for ScanSummary.Applied.
+// An ErrItemNotFound or ErrNotExpected from fn is reported and
+// skipped, any other error stops the loop.
+func scanLines(r io.Reader, w io.Writer,
+	fn func(id int) (Item, bool, error)) (ScanSummary, error) {
+	var sum ScanSummary
+	in := bufio.NewScanner(r)
+	for in.Scan() {
+		line := strings.TrimSpace(in.Text())
+		if line == "" {
+			continue
+		}
+		sum.Scanned++
+
+		var msg string
+		id, err := strconv.Atoi(line)
+		if err != nil {
+			sum.Invalid++
+			msg = fmt.Sprintf("%-6s not an item ID\n", line)
+		} else {
+			item, applied, err := fn(id)
+			switch {
+			case errors.Is(err, ErrItemNotFound):
+				sum.NotFound++
+				msg = fmt.Sprintf("%-6d not found\n", id)
+			case errors.Is(err, ErrNotExpected):
+				sum.Rejected++
+				msg = fmt.Sprintf("%-6d %v\n", id, err)
+			case err != nil:
+				return sum, err
+			default:
+				if applied {
+					sum.Applied++
+				}
+				msg = fmt.Sprintf("%-6d %-20s %-12s %s\n", item.ID,
+					item.Description, item.Location, item.Status)
+			}
+		}
+		if _, err := io.WriteString(w, msg); err != nil {
+			return sum, fmt.Errorf("write failed: %v", err)
+		}
+	}
+	if err := in.Err(); err != nil {
+		return sum, fmt.Errorf("read scan input failed: %v", err)
+	}
+	return sum, nil
+}
@@ -0,0 +1,154 @@
+// scan_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for the barcode scanner quick-entry mode
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestParseScanAction(t *testing.T) {
+	a, err := inventory.ParseScanAction("Location = Rack 5")
+	if err != nil {
+		t.Fatalf("ParseScanAction failed: %v", err)
+	}
+	if a.Kind != inventory.ScanSetLocation || a.Value != "Rack 5" {
+		t.Errorf("unexpected action: %+v", a)
+	}
+
+	if _, err := inventory.ParseScanAction("checkin"); err != nil {
+		t.Errorf("checkin without value failed: %v", err)
+	}
+	if _, err := inventory.ParseScanAction("status"); err == nil {
+		t.Error("expected error for status without value")
+	}
+	if _, err := inventory.ParseScanAction("explode"); err == nil {
+		t.Error("expected error for unknown action")
+	}
+}
+
+func TestInventoryDB_Scan(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "UPS", Location: "Rack 1"},
+		{ID: 1002, Description: "PDU", Location: "Rack 1"},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	in := strings.NewReader("1001\n\n1009\nabc\n1002\r\n")
+	a := inventory.ScanAction{Kind: inventory.ScanSetLocation, Value: "Rack 5"}
+	sum, err := inv.Scan(in, &out, a)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	want := inventory.ScanSummary{
+		Scanned: 4, Applied: 2, NotFound: 1, Invalid: 1,
+	}
+	if sum != want {
+		t.Errorf("unexpected summary: %+v", sum)
+	}
+	if !strings.Contains(out.String(), "1009   not found") ||
+		!strings.Contains(out.String(), "abc    not an item ID") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	item, _ := inv.GetItemByID(1002)
+	if item.Location != "Rack 5" ||
+		!strings.Contains(item.Remarks, "scan: location Rack 1 → Rack 5") {
+		t.Errorf("scan not applied: %+v", item)
+	}
+}
+
+func TestInventoryDB_Scan_CheckOutIn(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	err := inv.AppendItem(inventory.Item{ID: 1001, Location: "Store"})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	a := inventory.ScanAction{Kind: inventory.ScanCheckOut, Value: "Ravi"}
+	if _, err := inv.Scan(strings.NewReader("1001\n"), &out, a); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	item, _ := inv.GetItemByID(1001)
+	if item.Status != inventory.StatusCheckedOut ||
+		!strings.Contains(item.Remarks, "checked out to Ravi") {
+		t.Errorf("check out not applied: %+v", item)
+	}
+
+	a = inventory.ScanAction{Kind: inventory.ScanCheckIn, Value: "Lab"}
+	if _, err := inv.Scan(strings.NewReader("1001\n"), &out, a); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	item, _ = inv.GetItemByID(1001)
+	if item.Status != inventory.StatusAvailable || item.Location != "Lab" {
+		t.Errorf("check in not applied: %+v", item)
+	}
+
+	_, err = inv.GetItemByID(1005)
+	if !errors.Is(err, inventory.ErrItemNotFound) {
+		t.Errorf("expected ErrItemNotFound, got %v", err)
+	}
+}
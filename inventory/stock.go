@@ -0,0 +1,562 @@
+// stock.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Per-location stock balances for Inventory CLI
+// Tracks on-hand quantity per (item, location) with a movement
+// log, and transfers stock between locations atomically.
+//
+
+package inventory
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/boseji/bsg/gen"
+)
+
+// Kinds of StockMovement.
+const (
+	MoveReceive     = "receive"
+	MoveIssue       = "issue"
+	MoveTransferOut = "transfer_out"
+	MoveTransferIn  = "transfer_in"
+	MoveDelete      = "delete"
+	MoveRestore     = "restore"
+)
+
+// stockBalancesTable is the DDL of the stock_balances table.
+const stockBalancesTable = `
+    CREATE TABLE IF NOT EXISTS stock_balances (
+        item_id INTEGER NOT NULL,
+        location TEXT NOT NULL,
+        quantity INTEGER NOT NULL,
+        PRIMARY KEY (item_id, location)
+    );`
+
+// stockMovementsTable is the DDL of the stock_movements table.
+//
+// Quantities are signed: positive into the location, negative
+// out of it. Both halves of a transfer share the same ref.
+const stockMovementsTable = `
+    CREATE TABLE IF NOT EXISTS stock_movements (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        item_id INTEGER NOT NULL,
+        location TEXT NOT NULL,
+        quantity INTEGER NOT NULL,
+        kind TEXT NOT NULL,
+        ref TEXT,
+        note TEXT,
+        ts TEXT
+    );`
+
+// StockBalance is the quantity of an item at one location.
+type StockBalance struct {
+	ItemID      int    `json:"item_id"`
+	Description string `json:"description"`
+	Location    string `json:"location"`
+	Quantity    int    `json:"quantity"`
+}
+
+// StockMovement is one entry of the movement log.
+//
+// Fields:
+//
+//	ID       - auto-increment primary key
+//	ItemID   - inventory item
+//	Location - location whose balance changed
+//	Quantity - signed change of the balance
+//	Kind     - MoveReceive, MoveIssue, MoveTransferOut, MoveTransferIn,
+//	           MoveDelete or MoveRestore
+//	Ref      - links the two halves of a transfer
+//	Note     - free text
+//	Time     - "YYYY-MM-DD HH:MM"
+type StockMovement struct {
+	ID       int    `json:"id"`
+	ItemID   int    `json:"item_id"`
+	Location string `json:"location"`
+	Quantity int    `json:"quantity"`
+	Kind     string `json:"kind"`
+	Ref      string `json:"ref,omitempty"`
+	Note     string `json:"note,omitempty"`
+	Time     string `json:"time"`
+}
+
+// ErrStockMismatch is returned when a write would make the
+// quantity of an item differ from the total of its stock balances.
+var ErrStockMismatch = errors.New("quantity is kept by stock balances, " +
+	"use ReceiveStock, IssueStock or Transfer")
+
+// installStockTriggers (re)creates the triggers that keep the
+// quantity of an item with stock balances equal to their total.
+//
+// Once an item has balances, its quantity can only change through
+// ReceiveStock(), IssueStock() and Transfer(); any other write of
+// a different quantity (EditItem, UpdateItem, AdjustQuantity,
+// Revert, imports) is aborted. Called by OpenDB.
+func installStockTriggers(db *sql.DB) error {
+	guard := func(when string) string {
+		return `BEFORE ` + when + ` ON inventory
+        WHEN EXISTS (SELECT 1 FROM stock_balances
+                WHERE item_id = NEW.id)
+            AND NEW.quantity IS NOT (SELECT SUM(quantity)
+                FROM stock_balances WHERE item_id = NEW.id)
+        BEGIN
+            SELECT RAISE(ABORT, '` + ErrStockMismatch.Error() + `');
+        END;`
+	}
+	triggers := map[string]string{
+		"insert": guard("INSERT"),
+		"update": guard("UPDATE OF quantity"),
+	}
+	for _, name := range []string{"insert", "update"} {
+		_, err := db.Exec("DROP TRIGGER IF EXISTS inventory_stock_" + name)
+		if err == nil {
+			_, err = db.Exec("CREATE TRIGGER inventory_stock_" + name +
+				" " + triggers[name])
+		}
+		if err != nil {
+			return fmt.Errorf("stock trigger failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// seedBalance gives an item without any balance rows a balance
+// of its whole quantity at its own location, so items recorded
+// before per-location tracking can be moved.
+func seedBalance(exec ExecQuerier, item Item) error {
+	var count int
+	err := exec.QueryRow(`
+        SELECT COUNT(*) FROM stock_balances WHERE item_id = ?`,
+		item.ID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("balance query failed: %v", err)
+	}
+	if count > 0 || item.Quantity == 0 {
+		return nil
+	}
+	_, err = exec.Exec(`
+        INSERT INTO stock_balances (item_id, location, quantity)
+        VALUES (?, ?, ?)`, item.ID, item.Location, item.Quantity)
+	if err != nil {
+		return fmt.Errorf("seed balance failed: %v", err)
+	}
+	return nil
+}
+
+// moveStock changes the balance at one location and logs the
+// movement. Balances never go below zero, and empty balances
+// are removed.
+func moveStock(exec ExecQuerier, itemID int, location string,
+	qty int, kind, ref, note string) error {
+	var have int
+	err := exec.QueryRow(`
+        SELECT COALESCE(SUM(quantity), 0) FROM stock_balances
+        WHERE item_id = ? AND location = ?`,
+		itemID, location).Scan(&have)
+	if err != nil {
+		return fmt.Errorf("balance query failed: %v", err)
+	}
+	if have+qty < 0 {
+		return fmt.Errorf("item %d has only %d at %s",
+			itemID, have, location)
+	}
+
+	_, err = exec.Exec(`
+        INSERT INTO stock_balances (item_id, location, quantity)
+        VALUES (?, ?, ?)
+        ON CONFLICT (item_id, location)
+        DO UPDATE SET quantity = quantity + excluded.quantity`,
+		itemID, location, qty)
+	if err == nil {
+		_, err = exec.Exec(`
+            DELETE FROM stock_balances
+            WHERE item_id = ? AND location = ? AND quantity = 0`,
+			itemID, location)
+	}
+	if err != nil {
+		return fmt.Errorf("update balance failed: %v", err)
+	}
+
+	_, err = exec.Exec(`
+        INSERT INTO stock_movements
+        (item_id, location, quantity, kind, ref, note, ts)
+        VALUES (?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?)`,
+		itemID, location, qty, kind, ref, note,
+		gen.BST().Format("2006-01-02 15:04"))
+	if err != nil {
+		return fmt.Errorf("log movement failed: %v", err)
+	}
+	return nil
+}
+
+// removeBalances removes the stock balances of an item, logging
+// each as a MoveDelete movement so restoreBalances() can put them
+// back.
+func removeBalances(exec Execer, itemID int) error {
+	_, err := exec.Exec(`
+        INSERT INTO stock_movements
+        (item_id, location, quantity, kind, ts)
+        SELECT item_id, location, -quantity, ?, ?
+        FROM stock_balances WHERE item_id = ?
+        ORDER BY location`,
+		MoveDelete, gen.BST().Format("2006-01-02 15:04"), itemID)
+	if err != nil {
+		return fmt.Errorf("log movement failed: %v", err)
+	}
+	_, err = exec.Exec(`
+        DELETE FROM stock_balances
+        WHERE item_id = ?`, itemID)
+	if err != nil {
+		return fmt.Errorf("delete stock balances failed: %v", err)
+	}
+	return nil
+}
+
+// restoreBalances puts back the balances of a deleted item: the
+// MoveDelete movements logged after its last other movement are
+// reversed as MoveRestore movements.
+func restoreBalances(exec ExecQuerier, itemID int) error {
+	rows, err := exec.Query(`
+        SELECT location, -quantity FROM stock_movements
+        WHERE item_id = ? AND kind = ? AND id > (
+            SELECT COALESCE(MAX(id), 0) FROM stock_movements
+            WHERE item_id = ? AND kind != ?)
+        ORDER BY id`, itemID, MoveDelete, itemID, MoveDelete)
+	if err != nil {
+		return fmt.Errorf("movement query failed: %v", err)
+	}
+	var balances []StockBalance
+	for rows.Next() {
+		b := StockBalance{ItemID: itemID}
+		if err := rows.Scan(&b.Location, &b.Quantity); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan failed: %v", err)
+		}
+		balances = append(balances, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("movement query failed: %v", err)
+	}
+
+	for _, b := range balances {
+		err := moveStock(exec, itemID, b.Location, b.Quantity,
+			MoveRestore, "", "")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeStock receives (qty > 0) or issues (qty < 0) stock at a
+// location and keeps the item quantity equal to its total.
+func changeStock(exec ExecQuerier, itemID int, location string,
+	qty int, kind, note string) error {
+	if location == "" {
+		return fmt.Errorf("stock location required")
+	}
+	item, err := GetItemByID(exec, itemID)
+	if err != nil {
+		return err
+	}
+	if err := seedBalance(exec, item); err != nil {
+		return err
+	}
+	err = moveStock(exec, itemID, location, qty, kind, "", note)
+	if err != nil {
+		return err
+	}
+
+	_, err = exec.Exec(`
+        UPDATE inventory SET quantity = quantity + ? WHERE id = ?`,
+		qty, itemID)
+	if err != nil {
+		return fmt.Errorf("update quantity failed: %v", err)
+	}
+
+	verb, n := "received", qty
+	if qty < 0 {
+		verb, n = "issued", -qty
+	}
+	msg := fmt.Sprintf("%s %d at %s", verb, n, location)
+	if note != "" {
+		msg += ": " + note
+	}
+	return AppendRemarksEntry(exec, itemID, msg)
+}
+
+// ReceiveStock adds qty units of an item at a location.
+//
+// The item quantity grows by the same amount, and the receipt is
+// logged in the movement log and the item's remarks.
+//
+// Usage:
+//
+//	err := ReceiveStock(tx, 1004, "Store 1", 20, "PO 118")
+//
+// Works with both *sql.DB and *sql.Tx.
+func ReceiveStock(exec ExecQuerier, itemID int, location string,
+	qty int, note string) error {
+	if qty <= 0 {
+		return fmt.Errorf("quantity must be positive")
+	}
+	return changeStock(exec, itemID, location, qty, MoveReceive, note)
+}
+
+// IssueStock removes qty units of an item from a location,
+// e.g. when consumed. See ReceiveStock().
+//
+// Errors are returned if the location holds less than qty.
+//
+// Works with both *sql.DB and *sql.Tx.
+func IssueStock(exec ExecQuerier, itemID int, location string,
+	qty int, note string) error {
+	if qty <= 0 {
+		return fmt.Errorf("quantity must be positive")
+	}
+	return changeStock(exec, itemID, location, -qty, MoveIssue, note)
+}
+
+// Transfer moves qty units of an item from one location to
+// another.
+//
+// It writes a pair of movements (transfer_out, transfer_in)
+// sharing one reference, and logs the transfer in the item's
+// remarks:
+//
+//	[2025-06-20 16:55] transferred 5 from Store 1 to Store 2
+//
+// The item's total quantity does not change. Call it inside a
+// transaction (InventoryDB.Transfer does) so both halves are
+// applied or none.
+//
+// Usage:
+//
+//	err := Transfer(tx, 1004, "Store 1", "Store 2", 5, "")
+//
+// Errors are returned if the source holds less than qty.
+//
+// Works with both *sql.DB and *sql.Tx.
+func Transfer(exec ExecQuerier, itemID int, from, to string,
+	qty int, note string) error {
+	if qty <= 0 {
+		return fmt.Errorf("quantity must be positive")
+	}
+	if from == "" || to == "" || from == to {
+		return fmt.Errorf("transfer needs two different locations")
+	}
+	item, err := GetItemByID(exec, itemID)
+	if err != nil {
+		return err
+	}
+	if err := seedBalance(exec, item); err != nil {
+		return err
+	}
+
+	var seq int
+	err = exec.QueryRow(`
+        SELECT COALESCE(MAX(id), 0) + 1 FROM stock_movements`).Scan(&seq)
+	if err != nil {
+		return fmt.Errorf("movement query failed: %v", err)
+	}
+	ref := fmt.Sprintf("T%d", seq)
+
+	err = moveStock(exec, itemID, from, -qty, MoveTransferOut, ref, note)
+	if err != nil {
+		return err
+	}
+	err = moveStock(exec, itemID, to, qty, MoveTransferIn, ref, note)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("transferred %d from %s to %s", qty, from, to)
+	if note != "" {
+		msg += ": " + note
+	}
+	return AppendRemarksEntry(exec, itemID, msg)
+}
+
+// scanBalances reads rows of item_id, description, location,
+// quantity.
+func scanBalances(db Querier, query string,
+	args ...interface{}) ([]StockBalance, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("balance query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var list []StockBalance
+	for rows.Next() {
+		var b StockBalance
+		err := rows.Scan(&b.ItemID, &b.Description,
+			&b.Location, &b.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		list = append(list, b)
+	}
+	return list, rows.Err()
+}
+
+// ItemBalances returns the balances of an item by location.
+//
+// Works with both *sql.DB and *sql.Tx.
+func ItemBalances(db Querier, itemID int) ([]StockBalance, error) {
+	return scanBalances(db, `
+        SELECT b.item_id, COALESCE(i.description, ''),
+            b.location, b.quantity
+        FROM stock_balances b
+        LEFT JOIN inventory i ON i.id = b.item_id
+        WHERE b.item_id = ?
+        ORDER BY b.location`, itemID)
+}
+
+// LocationBalances returns what is held at a location,
+// sorted by item ID.
+//
+// Usage:
+//
+//	list, err := LocationBalances(db, "Store 2")
+//
+// Works with both *sql.DB and *sql.Tx.
+func LocationBalances(db Querier, location string) ([]StockBalance, error) {
+	return scanBalances(db, `
+        SELECT b.item_id, COALESCE(i.description, ''),
+            b.location, b.quantity
+        FROM stock_balances b
+        LEFT JOIN inventory i ON i.id = b.item_id
+        WHERE b.location = ?
+        ORDER BY b.item_id`, location)
+}
+
+// ListMovements returns the movement log of an item, oldest
+// first.
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListMovements(db Querier, itemID int) ([]StockMovement, error) {
+	rows, err := db.Query(`
+        SELECT id, item_id, location, quantity, kind,
+            COALESCE(ref, ''), COALESCE(note, ''), COALESCE(ts, '')
+        FROM stock_movements
+        WHERE item_id = ?
+        ORDER BY id`, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("movement query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var list []StockMovement
+	for rows.Next() {
+		var m StockMovement
+		err := rows.Scan(&m.ID, &m.ItemID, &m.Location, &m.Quantity,
+			&m.Kind, &m.Ref, &m.Note, &m.Time)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+// ReceiveStock wraps ReceiveStock with automatic transaction.
+//
+// Usage:
+//
+//	err := inv.ReceiveStock(1004, "Store 1", 20, "PO 118")
+func (inv *InventoryDB) ReceiveStock(itemID int, location string,
+	qty int, note string) error {
+	return inv.withTx("receive_stock", func(tx ExecQuerier) error {
+		return ReceiveStock(tx, itemID, location, qty, note)
+	})
+}
+
+// IssueStock wraps IssueStock with automatic transaction.
+//
+// Usage:
+//
+//	err := inv.IssueStock(1004, "Store 1", 2, "Rack 3 UPS")
+func (inv *InventoryDB) IssueStock(itemID int, location string,
+	qty int, note string) error {
+	return inv.withTx("issue_stock", func(tx ExecQuerier) error {
+		return IssueStock(tx, itemID, location, qty, note)
+	})
+}
+
+// Transfer wraps Transfer in one transaction, so both movements
+// are written or neither.
+//
+// Usage:
+//
+//	err := inv.Transfer(1004, "Store 1", "Store 2", 5, "")
+func (inv *InventoryDB) Transfer(itemID int, from, to string,
+	qty int, note string) error {
+	return inv.withTx("transfer", func(tx ExecQuerier) error {
+		return Transfer(tx, itemID, from, to, qty, note)
+	})
+}
+
+// ItemBalances wraps ItemBalances.
+func (inv *InventoryDB) ItemBalances(itemID int) ([]StockBalance, error) {
+	return ItemBalances(inv.db, itemID)
+}
+
+// LocationBalances wraps LocationBalances.
+func (inv *InventoryDB) LocationBalances(
+	location string) ([]StockBalance, error) {
+	return LocationBalances(inv.db, location)
+}
+
+// ListMovements wraps ListMovements.
+func (inv *InventoryDB) ListMovements(
+	itemID int) ([]StockMovement, error) {
+	return ListMovements(inv.db, itemID)
+}
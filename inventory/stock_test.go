@@ -0,0 +1,210 @@
+// stock_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for per-location stock balances and transfers
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestInventoryDB_Transfer(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	// Recorded before per-location tracking: 10 in Store 1
+	err := inv.AppendItem(inventory.Item{
+		ID: 1001, Description: "Fuse 5A", Location: "Store 1", Quantity: 10,
+	})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+
+	err = inv.Transfer(1001, "Store 1", "Store 2", 4, "rebalance")
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+
+	list, err := inv.ItemBalances(1001)
+	if err != nil {
+		t.Fatalf("ItemBalances failed: %v", err)
+	}
+	if len(list) != 2 || list[0].Quantity != 6 || list[1].Quantity != 4 {
+		t.Errorf("unexpected balances: %+v", list)
+	}
+
+	moves, err := inv.ListMovements(1001)
+	if err != nil {
+		t.Fatalf("ListMovements failed: %v", err)
+	}
+	if len(moves) != 2 || moves[0].Ref == "" ||
+		moves[0].Ref != moves[1].Ref || moves[0].Quantity != -4 ||
+		moves[1].Kind != inventory.MoveTransferIn {
+		t.Errorf("unexpected movements: %+v", moves)
+	}
+
+	item, _ := inv.GetItemByID(1001)
+	if item.Quantity != 10 || !strings.Contains(item.Remarks,
+		"transferred 4 from Store 1 to Store 2: rebalance") {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	// Too much: nothing is written
+	if err := inv.Transfer(1001, "Store 2", "Store 3", 5, ""); err == nil {
+		t.Error("expected error for short balance")
+	}
+	moves, _ = inv.ListMovements(1001)
+	if len(moves) != 2 {
+		t.Errorf("failed transfer left movements: %+v", moves)
+	}
+	if err := inv.Transfer(1001, "Store 2", "Store 2", 1, ""); err == nil {
+		t.Error("expected error for same locations")
+	}
+}
+
+func TestInventoryDB_ReceiveIssueStock(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	err := inv.AppendItem(inventory.Item{ID: 1001, Description: "Battery"})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+	if err := inv.ReceiveStock(1001, "Store 1", 8, "PO 118"); err != nil {
+		t.Fatalf("ReceiveStock failed: %v", err)
+	}
+	if err := inv.ReceiveStock(1001, "Store 2", 2, ""); err != nil {
+		t.Fatalf("ReceiveStock failed: %v", err)
+	}
+	if err := inv.IssueStock(1001, "Store 2", 2, "Rack 3 UPS"); err != nil {
+		t.Fatalf("IssueStock failed: %v", err)
+	}
+	if err := inv.IssueStock(1001, "Store 2", 1, ""); err == nil {
+		t.Error("expected error issuing from empty location")
+	}
+
+	item, _ := inv.GetItemByID(1001)
+	if item.Quantity != 8 ||
+		!strings.Contains(item.Remarks, "received 8 at Store 1: PO 118") ||
+		!strings.Contains(item.Remarks, "issued 2 at Store 2: Rack 3 UPS") {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	list, err := inv.LocationBalances("Store 1")
+	if err != nil {
+		t.Fatalf("LocationBalances failed: %v", err)
+	}
+	if len(list) != 1 || list[0].Description != "Battery" ||
+		list[0].Quantity != 8 {
+		t.Errorf("unexpected balances: %+v", list)
+	}
+	list, _ = inv.LocationBalances("Store 2")
+	if len(list) != 0 {
+		t.Errorf("empty balance kept: %+v", list)
+	}
+}
+
+func TestInventoryDB_QuantityKeptByBalances(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	err := inv.AppendItem(inventory.Item{
+		ID: 1001, Description: "Fuse 5A", Location: "Store 1",
+	})
+	if err != nil {
+		t.Fatalf("AppendItem failed: %v", err)
+	}
+	if err := inv.ReceiveStock(1001, "Store 1", 10, ""); err != nil {
+		t.Fatalf("ReceiveStock failed: %v", err)
+	}
+
+	five := 5
+	for name, fn := range map[string]func() error{
+		"EditItem": func() error {
+			return inv.EditItem(inventory.Item{ID: 1001,
+				Description: "Fuse 5A", Quantity: 5})
+		},
+		"AdjustQuantity": func() error {
+			return inv.AdjustQuantity(1001, -5, "")
+		},
+		"UpdateItem": func() error {
+			_, err := inv.UpdateItem(1001,
+				inventory.ItemPatch{Quantity: &five})
+			return err
+		},
+		"AppendItem": func() error {
+			return inv.AppendItem(inventory.Item{ID: 1001, Quantity: 5})
+		},
+	} {
+		if err := fn(); err == nil ||
+			!strings.Contains(err.Error(), "kept by stock balances") {
+			t.Errorf("%s: expected stock balance error, got %v", name, err)
+		}
+	}
+
+	// Writes that keep the quantity are allowed
+	err = inv.EditItem(inventory.Item{ID: 1001, Description: "Fuse 5A 250V"})
+	if err != nil {
+		t.Errorf("EditItem failed: %v", err)
+	}
+	if err := inv.IssueStock(1001, "Store 1", 10, ""); err != nil {
+		t.Fatalf("IssueStock failed: %v", err)
+	}
+	// No balances left, so the quantity is free again
+	if err := inv.AdjustQuantity(1001, 3, "found"); err != nil {
+		t.Errorf("AdjustQuantity failed: %v", err)
+	}
+	item, _ := inv.GetItemByID(1001)
+	if item.Quantity != 3 {
+		t.Errorf("unexpected quantity %d", item.Quantity)
+	}
+}
@@ -0,0 +1,651 @@
+// stocktake.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Stocktake (physical verification) sessions for Inventory CLI
+// Snapshots the expected items, collects found / missing /
+// found elsewhere marks, reports variance and reconciles.
+//
+
+package inventory
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/boseji/bsg/gen"
+)
+
+// States of a Stocktake.
+const (
+	StocktakeOpen       = "open"
+	StocktakeReconciled = "reconciled"
+)
+
+// Marks of a StocktakeLine. An unmarked line has an empty Mark.
+const (
+	MarkFound     = "found"
+	MarkMissing   = "missing"
+	MarkElsewhere = "elsewhere"
+)
+
+// ErrNotExpected is matched by the error returned for an item the
+// stocktake did not expect when it cannot be marked, use
+// errors.Is(err, ErrNotExpected).
+var ErrNotExpected = errors.New("not expected")
+
+// StatusMissing is set on items reconciled as missing.
+const StatusMissing = "Missing"
+
+// stocktakesTable is the DDL of the stocktakes table.
+//
+// The scope column holds the ItemFilter of the session as JSON.
+const stocktakesTable = `
+    CREATE TABLE IF NOT EXISTS stocktakes (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT,
+        scope TEXT,
+        state TEXT NOT NULL,
+        started_at TEXT,
+        reconciled_at TEXT
+    );`
+
+// stocktakeItemsTable is the DDL of the stocktake_items table.
+//
+// One row per item expected by the snapshot (expected = 1), plus
+// rows for items counted in scope that were not expected there.
+const stocktakeItemsTable = `
+    CREATE TABLE IF NOT EXISTS stocktake_items (
+        stocktake_id INTEGER NOT NULL,
+        item_id INTEGER NOT NULL,
+        description TEXT,
+        expected_location TEXT,
+        expected_status TEXT,
+        expected INTEGER NOT NULL,
+        mark TEXT NOT NULL DEFAULT '',
+        found_location TEXT NOT NULL DEFAULT '',
+        marked_at TEXT,
+        PRIMARY KEY (stocktake_id, item_id)
+    );`
+
+// Stocktake is a physical verification session.
+//
+// Fields:
+//
+//	ID           - auto-increment primary key
+//	Name         - e.g. "FY2025 Lab verification"
+//	Scope        - filter that selected the expected items
+//	State        - StocktakeOpen or StocktakeReconciled
+//	StartedAt    - snapshot time
+//	ReconciledAt - reconcile time, empty while open
+type Stocktake struct {
+	ID           int        `json:"id"`
+	Name         string     `json:"name"`
+	Scope        ItemFilter `json:"scope"`
+	State        string     `json:"state"`
+	StartedAt    string     `json:"started_at"`
+	ReconciledAt string     `json:"reconciled_at,omitempty"`
+}
+
+// StocktakeLine is one item of a stocktake.
+//
+// Fields:
+//
+//	ItemID           - inventory item
+//	Description      - description at snapshot time
+//	ExpectedLocation - location on record at snapshot time
+//	ExpectedStatus   - status on record at snapshot time
+//	Expected         - false for items found but not expected
+//	Mark             - MarkFound, MarkMissing, MarkElsewhere or ""
+//	FoundLocation    - where the item was found, for MarkElsewhere
+//	MarkedAt         - time of the mark
+type StocktakeLine struct {
+	ItemID           int    `json:"item_id"`
+	Description      string `json:"description"`
+	ExpectedLocation string `json:"expected_location"`
+	ExpectedStatus   string `json:"expected_status"`
+	Expected         bool   `json:"expected"`
+	Mark             string `json:"mark"`
+	FoundLocation    string `json:"found_location,omitempty"`
+	MarkedAt         string `json:"marked_at,omitempty"`
+}
+
+// Variance reports whether the line differs from the record:
+// anything but an expected item found in place.
+func (l StocktakeLine) Variance() bool {
+	return !l.Expected || l.Mark != MarkFound
+}
+
+// StartStocktake creates a stocktake session and snapshots the
+// items selected by the filter as expected. Returns its ID.
+//
+// Usage:
+//
+//	id, err := StartStocktake(tx, "Lab FY2025",
+//	    ItemFilter{Location: "Lab"})
+//
+// Works with both *sql.DB and *sql.Tx.
+func StartStocktake(exec ExecQuerier, name string,
+	f ItemFilter) (int, error) {
+	scope, err := json.Marshal(f)
+	if err != nil {
+		return 0, fmt.Errorf("encode stocktake scope failed: %v", err)
+	}
+	items, err := queryFiltered(exec, f)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := exec.Exec(`
+        INSERT INTO stocktakes (name, scope, state, started_at)
+        VALUES (?, ?, ?, ?)`,
+		name, string(scope), StocktakeOpen,
+		gen.BST().Format("2006-01-02 15:04"))
+	if err != nil {
+		return 0, fmt.Errorf("insert stocktake failed: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("stocktake id failed: %v", err)
+	}
+
+	for _, item := range items {
+		_, err := exec.Exec(`
+            INSERT INTO stocktake_items
+            (stocktake_id, item_id, description,
+             expected_location, expected_status, expected)
+            VALUES (?, ?, ?, ?, ?, 1)`,
+			id, item.ID, item.Description, item.Location, item.Status)
+		if err != nil {
+			return 0, fmt.Errorf("snapshot stocktake failed: %v", err)
+		}
+	}
+	return int(id), nil
+}
+
+// GetStocktake returns a stocktake session by ID.
+//
+// Works with both *sql.DB and *sql.Tx.
+func GetStocktake(db Querier, id int) (Stocktake, error) {
+	s, err := scanStocktake(db.QueryRow(`
+        SELECT id, name, scope, state, started_at, reconciled_at
+        FROM stocktakes WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return s, fmt.Errorf("stocktake %d not found", id)
+		}
+		return s, fmt.Errorf("query failed: %v", err)
+	}
+	return s, nil
+}
+
+// ListStocktakes returns all stocktake sessions, newest first.
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListStocktakes(db Querier) ([]Stocktake, error) {
+	rows, err := db.Query(`
+        SELECT id, name, scope, state, started_at, reconciled_at
+        FROM stocktakes ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("stocktake query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var list []Stocktake
+	for rows.Next() {
+		s, err := scanStocktake(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// scanStocktake reads a stocktakes row.
+func scanStocktake(row rowScanner) (Stocktake, error) {
+	var (
+		s          Stocktake
+		name       sql.NullString
+		scope      sql.NullString
+		started    sql.NullString
+		reconciled sql.NullString
+	)
+	err := row.Scan(&s.ID, &name, &scope, &s.State,
+		&started, &reconciled)
+	if err != nil {
+		return s, err
+	}
+	s.Name = name.String
+	s.StartedAt = started.String
+	s.ReconciledAt = reconciled.String
+	if scope.String != "" {
+		if err := json.Unmarshal([]byte(scope.String), &s.Scope); err != nil {
+			return s, fmt.Errorf("invalid stocktake scope: %v", err)
+		}
+	}
+	return s, nil
+}
+
+// openStocktake returns the session if it can still be marked.
+func openStocktake(db Querier, id int) (Stocktake, error) {
+	s, err := GetStocktake(db, id)
+	if err == nil && s.State != StocktakeOpen {
+		err = fmt.Errorf("stocktake %d is already %s", id, s.State)
+	}
+	return s, err
+}
+
+// MarkStocktakeItem records the count of one item.
+//
+// Marks:
+// - MarkFound: the item is where the record says
+// - MarkMissing: the item was not found
+// - MarkElsewhere: the item was found at foundLocation
+//
+// An item that was not expected by the snapshot is added to the
+// session; it can only be marked found elsewhere. Marking an
+// item again replaces the earlier mark.
+//
+// Usage:
+//
+//	err := MarkStocktakeItem(tx, 3, 1002, MarkElsewhere, "Rack 5")
+//
+// Works with both *sql.DB and *sql.Tx.
+func MarkStocktakeItem(exec ExecQuerier, id, itemID int,
+	mark, foundLocation string) error {
+	if _, err := openStocktake(exec, id); err != nil {
+		return err
+	}
+	switch mark {
+	case MarkFound, MarkMissing:
+		foundLocation = ""
+	case MarkElsewhere:
+		if foundLocation == "" {
+			return fmt.Errorf("found location required for %q", mark)
+		}
+	default:
+		return fmt.Errorf("unknown stocktake mark %q", mark)
+	}
+
+	var expected bool
+	err := exec.QueryRow(`
+        SELECT expected FROM stocktake_items
+        WHERE stocktake_id = ? AND item_id = ?`,
+		id, itemID).Scan(&expected)
+	switch {
+	case err == sql.ErrNoRows:
+		item, err := GetItemByID(exec, itemID)
+		if err != nil {
+			return err
+		}
+		if mark != MarkElsewhere {
+			return fmt.Errorf("item %d is %w in stocktake %d, "+
+				"mark it found elsewhere", itemID, ErrNotExpected, id)
+		}
+		_, err = exec.Exec(`
+            INSERT INTO stocktake_items
+            (stocktake_id, item_id, description,
+             expected_location, expected_status, expected)
+            VALUES (?, ?, ?, ?, ?, 0)`,
+			id, item.ID, item.Description, item.Location, item.Status)
+		if err != nil {
+			return fmt.Errorf("insert stocktake item failed: %v", err)
+		}
+	case err != nil:
+		return fmt.Errorf("query failed: %v", err)
+	}
+
+	_, err = exec.Exec(`
+        UPDATE stocktake_items
+        SET mark = ?, found_location = ?, marked_at = ?
+        WHERE stocktake_id = ? AND item_id = ?`,
+		mark, foundLocation, gen.BST().Format("2006-01-02 15:04"),
+		id, itemID)
+	if err != nil {
+		return fmt.Errorf("mark stocktake item failed: %v", err)
+	}
+	return nil
+}
+
+// CountStocktakeItem marks an item as counted at location, as when
+// it is scanned. A blank location means the location of the
+// session scope.
+//
+// The item is marked found when it is expected at that location,
+// otherwise found elsewhere.
+//
+// Works with both *sql.DB and *sql.Tx.
+func CountStocktakeItem(exec ExecQuerier, id, itemID int,
+	location string) error {
+	s, err := openStocktake(exec, id)
+	if err != nil {
+		return err
+	}
+	if location == "" {
+		location = s.Scope.Location
+	}
+
+	var want string
+	err = exec.QueryRow(`
+        SELECT expected_location FROM stocktake_items
+        WHERE stocktake_id = ? AND item_id = ? AND expected = 1`,
+		id, itemID).Scan(&want)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("query failed: %v", err)
+	}
+	if err == nil && (location == "" || location == want) {
+		return MarkStocktakeItem(exec, id, itemID, MarkFound, "")
+	}
+	if location == "" {
+		return fmt.Errorf("item %d is %w in stocktake %d, "+
+			"give the location it was found at", itemID, ErrNotExpected, id)
+	}
+	return MarkStocktakeItem(exec, id, itemID, MarkElsewhere, location)
+}
+
+// ListStocktakeLines returns every line of a stocktake, sorted
+// by item ID.
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListStocktakeLines(db Querier, id int) ([]StocktakeLine, error) {
+	rows, err := db.Query(`
+        SELECT item_id, description, expected_location,
+            expected_status, expected, mark, found_location, marked_at
+        FROM stocktake_items
+        WHERE stocktake_id = ?
+        ORDER BY item_id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("stocktake query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var lines []StocktakeLine
+	for rows.Next() {
+		var (
+			l      StocktakeLine
+			desc   sql.NullString
+			loc    sql.NullString
+			status sql.NullString
+			marked sql.NullString
+		)
+		err := rows.Scan(&l.ItemID, &desc, &loc, &status,
+			&l.Expected, &l.Mark, &l.FoundLocation, &marked)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		l.Description = desc.String
+		l.ExpectedLocation = loc.String
+		l.ExpectedStatus = status.String
+		l.MarkedAt = marked.String
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+// StocktakeVariance returns the lines of a stocktake that differ
+// from the record: missing, found elsewhere, not yet counted,
+// and found but not expected.
+//
+// Works with both *sql.DB and *sql.Tx.
+func StocktakeVariance(db Querier, id int) ([]StocktakeLine, error) {
+	lines, err := ListStocktakeLines(db, id)
+	if err != nil {
+		return nil, err
+	}
+	var out []StocktakeLine
+	for _, l := range lines {
+		if l.Variance() {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+// WriteVarianceReport prints stocktake lines as text columns:
+//
+//	MISSING    1002   UPS 3KVA             Lab
+//	ELSEWHERE  1005   Crimping tool        Lab → Store 2
+//	UNCOUNTED  1007   Multimeter           Lab
+//
+// Errors are returned if writing fails.
+func WriteVarianceReport(w io.Writer, lines []StocktakeLine) error {
+	for _, l := range lines {
+		state, where := "UNCOUNTED", l.ExpectedLocation
+		switch l.Mark {
+		case MarkFound:
+			state = "FOUND"
+		case MarkMissing:
+			state = "MISSING"
+		case MarkElsewhere:
+			state = "ELSEWHERE"
+			where += " → " + l.FoundLocation
+		}
+		_, err := fmt.Fprintf(w, "%-10s %-6d %-20s %s\n",
+			state, l.ItemID, l.Description, where)
+		if err != nil {
+			return fmt.Errorf("write report failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// ReconcileStocktake applies the outcome of a stocktake to the
+// inventory and closes the session.
+//
+// For each line:
+// - Found: the item is logged as verified
+// - Found elsewhere: the location is updated
+// - Missing or never counted: the status becomes StatusMissing
+//
+// Every item gets a remarks entry such as:
+//
+//	[2025-06-20 16:55] stocktake 3: found at Store 2, was Lab
+//
+// Items deleted since the snapshot are skipped. Returns the
+// number of items whose location or status changed.
+//
+// Usage:
+//
+//	n, err := ReconcileStocktake(tx, 3)
+//
+// Works with both *sql.DB and *sql.Tx.
+func ReconcileStocktake(exec ExecQuerier, id int) (int, error) {
+	if _, err := openStocktake(exec, id); err != nil {
+		return 0, err
+	}
+	lines, err := ListStocktakeLines(exec, id)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, l := range lines {
+		_, err := GetItemByID(exec, l.ItemID)
+		if errors.Is(err, ErrItemNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		var set, value, note string
+		switch l.Mark {
+		case MarkFound:
+			note = "verified at " + l.ExpectedLocation
+		case MarkElsewhere:
+			set, value = "location", l.FoundLocation
+			note = fmt.Sprintf("found at %s, was %s",
+				l.FoundLocation, l.ExpectedLocation)
+		case MarkMissing:
+			set, value, note = "status", StatusMissing, "missing"
+		default:
+			set, value = "status", StatusMissing
+			note = "not counted, marked missing"
+		}
+
+		if set != "" {
+			_, err := exec.Exec(`UPDATE inventory SET `+set+` = ?
+                WHERE id = ?`, value, l.ItemID)
+			if err != nil {
+				return 0, fmt.Errorf("reconcile failed: %v", err)
+			}
+			changed++
+		}
+		msg := fmt.Sprintf("stocktake %d: %s", id, note)
+		if err := AppendRemarksEntry(exec, l.ItemID, msg); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = exec.Exec(`
+        UPDATE stocktakes SET state = ?, reconciled_at = ?
+        WHERE id = ?`, StocktakeReconciled,
+		gen.BST().Format("2006-01-02 15:04"), id)
+	if err != nil {
+		return 0, fmt.Errorf("close stocktake failed: %v", err)
+	}
+	return changed, nil
+}
+
+// StartStocktake wraps StartStocktake with automatic transaction.
+//
+// Usage:
+//
+//	id, err := inv.StartStocktake("Lab FY2025", filter)
+func (inv *InventoryDB) StartStocktake(name string,
+	f ItemFilter) (int, error) {
+	var id int
+	err := inv.withTx("start_stocktake", func(tx ExecQuerier) error {
+		var err error
+		id, err = StartStocktake(tx, name, f)
+		return err
+	})
+	return id, err
+}
+
+// GetStocktake wraps GetStocktake.
+func (inv *InventoryDB) GetStocktake(id int) (Stocktake, error) {
+	return GetStocktake(inv.db, id)
+}
+
+// ListStocktakes wraps ListStocktakes.
+func (inv *InventoryDB) ListStocktakes() ([]Stocktake, error) {
+	return ListStocktakes(inv.db)
+}
+
+// MarkStocktakeItem wraps MarkStocktakeItem with automatic
+// transaction.
+//
+// Usage:
+//
+//	err := inv.MarkStocktakeItem(id, 1002, MarkMissing, "")
+func (inv *InventoryDB) MarkStocktakeItem(id, itemID int,
+	mark, foundLocation string) error {
+	return inv.withTx("mark_stocktake", func(tx ExecQuerier) error {
+		return MarkStocktakeItem(tx, id, itemID, mark, foundLocation)
+	})
+}
+
+// ScanStocktake counts scanned item IDs into a stocktake, see
+// CountStocktakeItem() and InventoryDB.Scan() for the loop.
+//
+// Unknown items and items the stocktake did not expect (with no
+// location given) are reported on their line and skipped, so one
+// stray label does not end the session.
+//
+// Usage:
+//
+//	sum, err := inv.ScanStocktake(os.Stdin, os.Stdout, id, "")
+func (inv *InventoryDB) ScanStocktake(r io.Reader, w io.Writer,
+	id int, location string) (ScanSummary, error) {
+	return scanLines(r, w, func(itemID int) (Item, bool, error) {
+		var item Item
+		err := inv.withTx("mark_stocktake", func(tx ExecQuerier) error {
+			err := CountStocktakeItem(tx, id, itemID, location)
+			if err == nil {
+				item, err = GetItemByID(tx, itemID)
+			}
+			return err
+		})
+		return item, err == nil, err
+	})
+}
+
+// ListStocktakeLines wraps ListStocktakeLines.
+func (inv *InventoryDB) ListStocktakeLines(
+	id int) ([]StocktakeLine, error) {
+	return ListStocktakeLines(inv.db, id)
+}
+
+// StocktakeVariance wraps StocktakeVariance.
+//
+// Usage:
+//
+//	lines, err := inv.StocktakeVariance(id)
+//	err = WriteVarianceReport(os.Stdout, lines)
+func (inv *InventoryDB) StocktakeVariance(
+	id int) ([]StocktakeLine, error) {
+	return StocktakeVariance(inv.db, id)
+}
+
+// ReconcileStocktake wraps ReconcileStocktake with automatic
+// transaction, so the whole session is applied or nothing.
+//
+// Usage:
+//
+//	n, err := inv.ReconcileStocktake(id)
+func (inv *InventoryDB) ReconcileStocktake(id int) (int, error) {
+	var n int
+	err := inv.withTx("reconcile_stocktake", func(tx ExecQuerier) error {
+		var err error
+		n, err = ReconcileStocktake(tx, id)
+		return err
+	})
+	return n, err
+}
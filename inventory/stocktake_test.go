@@ -0,0 +1,235 @@
+// stocktake_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for stocktake sessions
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func setupStocktake(t *testing.T) (*inventory.InventoryDB, int) {
+	inv := setupInventoryDB(t)
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "UPS", Location: "Lab", Status: "Active"},
+		{ID: 1002, Description: "PDU", Location: "Lab", Status: "Active"},
+		{ID: 1003, Description: "Drill", Location: "Lab", Status: "Active"},
+		{ID: 1004, Description: "Cable", Location: "Lab", Status: "Active"},
+		{ID: 1005, Description: "Meter", Location: "Store", Status: "Active"},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	id, err := inv.StartStocktake("Lab FY2025",
+		inventory.ItemFilter{Location: "Lab"})
+	if err != nil {
+		t.Fatalf("StartStocktake failed: %v", err)
+	}
+	return inv, id
+}
+
+func TestInventoryDB_StartStocktake(t *testing.T) {
+	inv, id := setupStocktake(t)
+	defer inv.Close()
+
+	s, err := inv.GetStocktake(id)
+	if err != nil {
+		t.Fatalf("GetStocktake failed: %v", err)
+	}
+	if s.State != inventory.StocktakeOpen || s.Scope.Location != "Lab" {
+		t.Errorf("unexpected stocktake: %+v", s)
+	}
+
+	lines, err := inv.ListStocktakeLines(id)
+	if err != nil {
+		t.Fatalf("ListStocktakeLines failed: %v", err)
+	}
+	if len(lines) != 4 || lines[0].Mark != "" || !lines[0].Expected {
+		t.Errorf("unexpected snapshot: %+v", lines)
+	}
+
+	if _, err := inv.GetStocktake(99); err == nil {
+		t.Error("expected error for unknown stocktake")
+	}
+}
+
+func TestInventoryDB_ScanStocktake(t *testing.T) {
+	inv, id := setupStocktake(t)
+	defer inv.Close()
+
+	var out bytes.Buffer
+	sum, err := inv.ScanStocktake(
+		strings.NewReader("1001\n1005\n1009\n"), &out, id, "")
+	if err != nil {
+		t.Fatalf("ScanStocktake failed: %v", err)
+	}
+	if sum.Applied != 2 || sum.NotFound != 1 {
+		t.Errorf("unexpected summary: %+v", sum)
+	}
+
+	// Without a location an unexpected item is skipped, not fatal
+	inv.AppendItem(inventory.Item{ID: 1006, Description: "Fan",
+		Location: "Lab", Status: "Spare"})
+	other, err := inv.StartStocktake("Active",
+		inventory.ItemFilter{Status: "Active"})
+	if err != nil {
+		t.Fatalf("StartStocktake failed: %v", err)
+	}
+	out.Reset()
+	sum, err = inv.ScanStocktake(
+		strings.NewReader("1006\n1005\n"), &out, other, "")
+	if err != nil {
+		t.Fatalf("ScanStocktake failed: %v", err)
+	}
+	if sum.Rejected != 1 || sum.Applied != 1 ||
+		!strings.Contains(out.String(), "1006   item 1006 is not expected") {
+		t.Errorf("unexpected scan: %+v\n%s", sum, out.String())
+	}
+
+	err = inv.MarkStocktakeItem(id, 1002, inventory.MarkElsewhere, "Rack 5")
+	if err != nil {
+		t.Fatalf("MarkStocktakeItem failed: %v", err)
+	}
+	err = inv.MarkStocktakeItem(id, 1003, inventory.MarkMissing, "")
+	if err != nil {
+		t.Fatalf("MarkStocktakeItem failed: %v", err)
+	}
+	err = inv.MarkStocktakeItem(id, 1002, inventory.MarkElsewhere, "")
+	if err == nil {
+		t.Error("expected error for elsewhere without location")
+	}
+	err = inv.MarkStocktakeItem(id, 1002, "lost", "")
+	if err == nil {
+		t.Error("expected error for unknown mark")
+	}
+
+	lines, err := inv.StocktakeVariance(id)
+	if err != nil {
+		t.Fatalf("StocktakeVariance failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := inventory.WriteVarianceReport(&buf, lines); err != nil {
+		t.Fatalf("WriteVarianceReport failed: %v", err)
+	}
+	report := buf.String()
+	for _, want := range []string{
+		"ELSEWHERE  1002", "Lab → Rack 5", "MISSING    1003",
+		"UNCOUNTED  1004", "ELSEWHERE  1005", "Store → Lab",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "1001") {
+		t.Errorf("found item in variance report:\n%s", report)
+	}
+}
+
+func TestInventoryDB_ReconcileStocktake(t *testing.T) {
+	inv, id := setupStocktake(t)
+	defer inv.Close()
+
+	marks := []struct {
+		item      int
+		mark, loc string
+	}{
+		{1001, inventory.MarkFound, ""},
+		{1002, inventory.MarkElsewhere, "Rack 5"},
+		{1003, inventory.MarkMissing, ""},
+	}
+	for _, m := range marks {
+		err := inv.MarkStocktakeItem(id, m.item, m.mark, m.loc)
+		if err != nil {
+			t.Fatalf("MarkStocktakeItem failed: %v", err)
+		}
+	}
+
+	n, err := inv.ReconcileStocktake(id)
+	if err != nil {
+		t.Fatalf("ReconcileStocktake failed: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 changed items, got %d", n)
+	}
+
+	item, _ := inv.GetItemByID(1001)
+	if item.Status != "Active" ||
+		!strings.Contains(item.Remarks, "stocktake 1: verified at Lab") {
+		t.Errorf("unexpected found item: %+v", item)
+	}
+	item, _ = inv.GetItemByID(1002)
+	if item.Location != "Rack 5" ||
+		!strings.Contains(item.Remarks, "found at Rack 5, was Lab") {
+		t.Errorf("unexpected moved item: %+v", item)
+	}
+	for _, id := range []int{1003, 1004} {
+		item, _ = inv.GetItemByID(id)
+		if item.Status != inventory.StatusMissing {
+			t.Errorf("item %d not missing: %+v", id, item)
+		}
+	}
+
+	if _, err := inv.ReconcileStocktake(id); err == nil {
+		t.Error("expected error reconciling twice")
+	}
+	err = inv.MarkStocktakeItem(id, 1004, inventory.MarkFound, "")
+	if err == nil {
+		t.Error("expected error marking a closed stocktake")
+	}
+	list, _ := inv.ListStocktakes()
+	if len(list) != 1 || list[0].State != inventory.StocktakeReconciled {
+		t.Errorf("unexpected stocktakes: %+v", list)
+	}
+}
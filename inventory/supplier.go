@@ -0,0 +1,463 @@
+// supplier.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Suppliers and purchase records for Inventory CLI
+// Links items to the supplier, invoice and cost they were
+// bought at, and answers "what did we buy from X".
+//
+
+package inventory
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// suppliersTable is the DDL of the suppliers table.
+const suppliersTable = `
+    CREATE TABLE IF NOT EXISTS suppliers (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT NOT NULL UNIQUE,
+        contact TEXT,
+        gstin TEXT,
+        notes TEXT
+    );`
+
+// purchasesTable is the DDL of the purchases table.
+const purchasesTable = `
+    CREATE TABLE IF NOT EXISTS purchases (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        supplier_id INTEGER NOT NULL,
+        item_id INTEGER NOT NULL,
+        invoice_no TEXT,
+        invoice_date TEXT,
+        unit_cost REAL NOT NULL DEFAULT 0,
+        quantity INTEGER NOT NULL,
+        notes TEXT
+    );`
+
+// Supplier is a vendor goods are bought from.
+//
+// Fields:
+//
+//	ID      - auto-increment primary key
+//	Name    - unique supplier name
+//	Contact - person, phone or e-mail
+//	GSTIN   - 15 character GST identification number, optional
+//	Notes   - free text
+type Supplier struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Contact string `json:"contact,omitempty"`
+	GSTIN   string `json:"gstin,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+}
+
+// Purchase records a quantity of an item bought from a supplier.
+//
+// Fields:
+//
+//	ID           - auto-increment primary key
+//	SupplierID   - supplier the item was bought from
+//	SupplierName - supplier name (read only, filled by queries)
+//	ItemID       - inventory item
+//	InvoiceNo    - supplier invoice number
+//	InvoiceDate  - invoice date in DateLayout format
+//	UnitCost     - cost of one unit
+//	Quantity     - units bought
+//	Notes        - free text
+type Purchase struct {
+	ID           int     `json:"id"`
+	SupplierID   int     `json:"supplier_id"`
+	SupplierName string  `json:"supplier_name,omitempty"`
+	ItemID       int     `json:"item_id"`
+	InvoiceNo    string  `json:"invoice_no,omitempty"`
+	InvoiceDate  string  `json:"invoice_date,omitempty"`
+	UnitCost     float64 `json:"unit_cost"`
+	Quantity     int     `json:"quantity"`
+	Notes        string  `json:"notes,omitempty"`
+}
+
+// Total returns the cost of the purchase line.
+func (p Purchase) Total() float64 {
+	return p.UnitCost * float64(p.Quantity)
+}
+
+// reGSTIN matches the layout of a GSTIN: state code, PAN,
+// entity number, 'Z' and a check character.
+var reGSTIN = regexp.MustCompile(
+	`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`)
+
+// check verifies the supplier fields and normalises the GSTIN.
+func (s *Supplier) check() error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return fmt.Errorf("supplier name required")
+	}
+	s.GSTIN = strings.ToUpper(strings.TrimSpace(s.GSTIN))
+	if s.GSTIN != "" && !reGSTIN.MatchString(s.GSTIN) {
+		return fmt.Errorf("invalid GSTIN %q", s.GSTIN)
+	}
+	return nil
+}
+
+// AddSupplier creates a supplier and returns its ID.
+//
+// Usage:
+//
+//	id, err := AddSupplier(tx, Supplier{
+//	    Name:  "Sharma Electricals",
+//	    GSTIN: "27AAPFU0939F1ZV",
+//	})
+//
+// Errors are returned for blank or duplicate names and
+// malformed GSTINs.
+//
+// Works with both *sql.DB and *sql.Tx.
+func AddSupplier(exec Execer, s Supplier) (int, error) {
+	if err := s.check(); err != nil {
+		return 0, err
+	}
+	res, err := exec.Exec(`
+        INSERT INTO suppliers (name, contact, gstin, notes)
+        VALUES (?, ?, ?, ?)`, s.Name, s.Contact, s.GSTIN, s.Notes)
+	if err != nil {
+		return 0, fmt.Errorf("insert supplier failed: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("supplier id failed: %v", err)
+	}
+	return int(id), nil
+}
+
+// UpdateSupplier replaces the details of a supplier by ID.
+//
+// Works with both *sql.DB and *sql.Tx.
+func UpdateSupplier(exec Execer, s Supplier) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	res, err := exec.Exec(`
+        UPDATE suppliers SET name = ?, contact = ?, gstin = ?, notes = ?
+        WHERE id = ?`, s.Name, s.Contact, s.GSTIN, s.Notes, s.ID)
+	if err != nil {
+		return fmt.Errorf("update supplier failed: %v", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("supplier %d not found", s.ID)
+	}
+	return nil
+}
+
+// DeleteSupplier removes a supplier that has no purchases.
+//
+// Works with both *sql.DB and *sql.Tx.
+func DeleteSupplier(exec ExecQuerier, id int) error {
+	var count int
+	err := exec.QueryRow(`
+        SELECT COUNT(*) FROM purchases WHERE supplier_id = ?`,
+		id).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("purchase query failed: %v", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("supplier %d has %d purchases", id, count)
+	}
+	_, err = exec.Exec(`DELETE FROM suppliers WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete supplier failed: %v", err)
+	}
+	return nil
+}
+
+// supplierFields is the column list read by scanSupplier().
+const supplierFields = `id, name, COALESCE(contact, ''),
+        COALESCE(gstin, ''), COALESCE(notes, '')`
+
+// scanSupplier reads a row selected with supplierFields.
+func scanSupplier(row rowScanner) (Supplier, error) {
+	var s Supplier
+	err := row.Scan(&s.ID, &s.Name, &s.Contact, &s.GSTIN, &s.Notes)
+	return s, err
+}
+
+// getSupplier returns the supplier selected by a single
+// column match.
+func getSupplier(db Querier, column string,
+	value interface{}) (Supplier, error) {
+	s, err := scanSupplier(db.QueryRow(`
+        SELECT `+supplierFields+`
+        FROM suppliers WHERE `+column+` = ?`, value))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return s, fmt.Errorf("supplier %v not found", value)
+		}
+		return s, fmt.Errorf("query failed: %v", err)
+	}
+	return s, nil
+}
+
+// GetSupplier returns a supplier by ID.
+//
+// Works with both *sql.DB and *sql.Tx.
+func GetSupplier(db Querier, id int) (Supplier, error) {
+	return getSupplier(db, "id", id)
+}
+
+// GetSupplierByName returns a supplier by its exact name.
+//
+// Works with both *sql.DB and *sql.Tx.
+func GetSupplierByName(db Querier, name string) (Supplier, error) {
+	return getSupplier(db, "name", name)
+}
+
+// ListSuppliers returns all suppliers sorted by name.
+//
+// Works with both *sql.DB and *sql.Tx.
+func ListSuppliers(db Querier) ([]Supplier, error) {
+	rows, err := db.Query(`
+        SELECT ` + supplierFields + `
+        FROM suppliers ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("supplier query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var list []Supplier
+	for rows.Next() {
+		s, err := scanSupplier(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// RecordPurchase stores a purchase line and returns its ID.
+//
+// The purchase is logged in the item's remarks:
+//
+//	[2025-06-20 16:55] purchased 4 from Sharma Electricals, invoice SE/118
+//
+// Purchases do not change stock; use ReceiveStock() to put the
+// goods into a store.
+//
+// Usage:
+//
+//	id, err := RecordPurchase(tx, Purchase{
+//	    SupplierID: 1, ItemID: 1004, InvoiceNo: "SE/118",
+//	    InvoiceDate: "2025-06-18", UnitCost: 1450, Quantity: 4,
+//	})
+//
+// Works with both *sql.DB and *sql.Tx.
+func RecordPurchase(exec ExecQuerier, p Purchase) (int, error) {
+	if p.Quantity <= 0 {
+		return 0, fmt.Errorf("purchase quantity must be positive")
+	}
+	if p.UnitCost < 0 {
+		return 0, fmt.Errorf("negative unit cost %v", p.UnitCost)
+	}
+	if p.InvoiceDate != "" {
+		if _, err := time.Parse(DateLayout, p.InvoiceDate); err != nil {
+			return 0, fmt.Errorf("invalid invoice date %q", p.InvoiceDate)
+		}
+	}
+	s, err := GetSupplier(exec, p.SupplierID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := GetItemByID(exec, p.ItemID); err != nil {
+		return 0, err
+	}
+
+	res, err := exec.Exec(`
+        INSERT INTO purchases
+        (supplier_id, item_id, invoice_no, invoice_date,
+         unit_cost, quantity, notes)
+        VALUES (?, ?, ?, NULLIF(?, ''), ?, ?, ?)`,
+		p.SupplierID, p.ItemID, p.InvoiceNo, p.InvoiceDate,
+		p.UnitCost, p.Quantity, p.Notes)
+	if err != nil {
+		return 0, fmt.Errorf("insert purchase failed: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("purchase id failed: %v", err)
+	}
+
+	msg := fmt.Sprintf("purchased %d from %s", p.Quantity, s.Name)
+	if p.InvoiceNo != "" {
+		msg += ", invoice " + p.InvoiceNo
+	}
+	if err := AppendRemarksEntry(exec, p.ItemID, msg); err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// listPurchases returns the purchases matching a column,
+// oldest invoice first.
+func listPurchases(db Querier, column string, id int) ([]Purchase, error) {
+	rows, err := db.Query(`
+        SELECT p.id, p.supplier_id, COALESCE(s.name, ''), p.item_id,
+            COALESCE(p.invoice_no, ''), COALESCE(p.invoice_date, ''),
+            p.unit_cost, p.quantity, COALESCE(p.notes, '')
+        FROM purchases p
+        LEFT JOIN suppliers s ON s.id = p.supplier_id
+        WHERE p.`+column+` = ?
+        ORDER BY p.invoice_date, p.id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("purchase query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var list []Purchase
+	for rows.Next() {
+		var p Purchase
+		err := rows.Scan(&p.ID, &p.SupplierID, &p.SupplierName,
+			&p.ItemID, &p.InvoiceNo, &p.InvoiceDate,
+			&p.UnitCost, &p.Quantity, &p.Notes)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// SupplierPurchases answers "what did we buy from X".
+//
+// Usage:
+//
+//	s, _ := GetSupplierByName(db, "Sharma Electricals")
+//	list, err := SupplierPurchases(db, s.ID)
+//
+// Works with both *sql.DB and *sql.Tx.
+func SupplierPurchases(db Querier, supplierID int) ([]Purchase, error) {
+	return listPurchases(db, "supplier_id", supplierID)
+}
+
+// ItemPurchases answers "where did this item come from".
+//
+// Works with both *sql.DB and *sql.Tx.
+func ItemPurchases(db Querier, itemID int) ([]Purchase, error) {
+	return listPurchases(db, "item_id", itemID)
+}
+
+// AddSupplier wraps AddSupplier with automatic transaction.
+//
+// Usage:
+//
+//	id, err := inv.AddSupplier(supplier)
+func (inv *InventoryDB) AddSupplier(s Supplier) (int, error) {
+	var id int
+	err := inv.withTx("add_supplier", func(tx ExecQuerier) error {
+		var err error
+		id, err = AddSupplier(tx, s)
+		return err
+	})
+	return id, err
+}
+
+// UpdateSupplier wraps UpdateSupplier with automatic transaction.
+func (inv *InventoryDB) UpdateSupplier(s Supplier) error {
+	return inv.withTx("update_supplier", func(tx ExecQuerier) error {
+		return UpdateSupplier(tx, s)
+	})
+}
+
+// DeleteSupplier wraps DeleteSupplier with automatic transaction.
+func (inv *InventoryDB) DeleteSupplier(id int) error {
+	return inv.withTx("delete_supplier", func(tx ExecQuerier) error {
+		return DeleteSupplier(tx, id)
+	})
+}
+
+// GetSupplier wraps GetSupplier.
+func (inv *InventoryDB) GetSupplier(id int) (Supplier, error) {
+	return GetSupplier(inv.db, id)
+}
+
+// GetSupplierByName wraps GetSupplierByName.
+func (inv *InventoryDB) GetSupplierByName(name string) (Supplier, error) {
+	return GetSupplierByName(inv.db, name)
+}
+
+// ListSuppliers wraps ListSuppliers.
+func (inv *InventoryDB) ListSuppliers() ([]Supplier, error) {
+	return ListSuppliers(inv.db)
+}
+
+// RecordPurchase wraps RecordPurchase with automatic transaction.
+//
+// Usage:
+//
+//	id, err := inv.RecordPurchase(purchase)
+func (inv *InventoryDB) RecordPurchase(p Purchase) (int, error) {
+	var id int
+	err := inv.withTx("record_purchase", func(tx ExecQuerier) error {
+		var err error
+		id, err = RecordPurchase(tx, p)
+		return err
+	})
+	return id, err
+}
+
+// SupplierPurchases wraps SupplierPurchases.
+func (inv *InventoryDB) SupplierPurchases(
+	supplierID int) ([]Purchase, error) {
+	return SupplierPurchases(inv.db, supplierID)
+}
+
+// ItemPurchases wraps ItemPurchases.
+func (inv *InventoryDB) ItemPurchases(itemID int) ([]Purchase, error) {
+	return ItemPurchases(inv.db, itemID)
+}
@@ -0,0 +1,176 @@
+// supplier_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for suppliers and purchase records
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestInventoryDB_Suppliers(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	id, err := inv.AddSupplier(inventory.Supplier{
+		Name: "Sharma Electricals", Contact: "98200 00000",
+		GSTIN: "27aapfu0939f1zv",
+	})
+	if err != nil {
+		t.Fatalf("AddSupplier failed: %v", err)
+	}
+
+	s, err := inv.GetSupplierByName("Sharma Electricals")
+	if err != nil {
+		t.Fatalf("GetSupplierByName failed: %v", err)
+	}
+	if s.ID != id || s.GSTIN != "27AAPFU0939F1ZV" {
+		t.Errorf("unexpected supplier: %+v", s)
+	}
+
+	s.Notes = "net 30"
+	if err := inv.UpdateSupplier(s); err != nil {
+		t.Fatalf("UpdateSupplier failed: %v", err)
+	}
+	if got, _ := inv.GetSupplier(id); got.Notes != "net 30" {
+		t.Errorf("supplier not updated: %+v", got)
+	}
+
+	_, err = inv.AddSupplier(inventory.Supplier{Name: "Sharma Electricals"})
+	if err == nil {
+		t.Error("expected error for duplicate name")
+	}
+	_, err = inv.AddSupplier(inventory.Supplier{Name: "X", GSTIN: "1234"})
+	if err == nil {
+		t.Error("expected error for invalid GSTIN")
+	}
+	if _, err := inv.GetSupplier(99); err == nil {
+		t.Error("expected error for unknown supplier")
+	}
+
+	list, err := inv.ListSuppliers()
+	if err != nil || len(list) != 1 {
+		t.Errorf("unexpected suppliers: %+v %v", list, err)
+	}
+}
+
+func TestInventoryDB_RecordPurchase(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "Battery 12V"},
+		{ID: 1002, Description: "UPS 3KVA"},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+	a, _ := inv.AddSupplier(inventory.Supplier{Name: "Sharma Electricals"})
+	b, _ := inv.AddSupplier(inventory.Supplier{Name: "Power House"})
+
+	for _, p := range []inventory.Purchase{
+		{SupplierID: a, ItemID: 1001, InvoiceNo: "SE/118",
+			InvoiceDate: "2025-06-18", UnitCost: 1450, Quantity: 4},
+		{SupplierID: a, ItemID: 1002, InvoiceNo: "SE/090",
+			InvoiceDate: "2025-01-10", UnitCost: 38000, Quantity: 1},
+		{SupplierID: b, ItemID: 1001, InvoiceDate: "2025-03-02",
+			UnitCost: 1380, Quantity: 2},
+	} {
+		if _, err := inv.RecordPurchase(p); err != nil {
+			t.Fatalf("RecordPurchase failed: %v", err)
+		}
+	}
+
+	list, err := inv.SupplierPurchases(a)
+	if err != nil {
+		t.Fatalf("SupplierPurchases failed: %v", err)
+	}
+	if len(list) != 2 || list[0].ItemID != 1002 ||
+		list[1].Total() != 5800 {
+		t.Errorf("unexpected supplier purchases: %+v", list)
+	}
+
+	list, err = inv.ItemPurchases(1001)
+	if err != nil {
+		t.Fatalf("ItemPurchases failed: %v", err)
+	}
+	if len(list) != 2 || list[0].SupplierName != "Power House" {
+		t.Errorf("unexpected item purchases: %+v", list)
+	}
+
+	item, _ := inv.GetItemByID(1001)
+	if !strings.Contains(item.Remarks,
+		"purchased 4 from Sharma Electricals, invoice SE/118") {
+		t.Errorf("purchase not logged: %q", item.Remarks)
+	}
+
+	_, err = inv.RecordPurchase(inventory.Purchase{
+		SupplierID: a, ItemID: 1009, Quantity: 1,
+	})
+	if err == nil {
+		t.Error("expected error for unknown item")
+	}
+	_, err = inv.RecordPurchase(inventory.Purchase{
+		SupplierID: a, ItemID: 1001, Quantity: 1, InvoiceDate: "18/06/2025",
+	})
+	if err == nil {
+		t.Error("expected error for invalid invoice date")
+	}
+	if err := inv.DeleteSupplier(a); err == nil {
+		t.Error("expected error deleting supplier with purchases")
+	}
+	c, _ := inv.AddSupplier(inventory.Supplier{Name: "Unused"})
+	if err := inv.DeleteSupplier(c); err != nil {
+		t.Errorf("DeleteSupplier failed: %v", err)
+	}
+}
@@ -0,0 +1,1002 @@
+// sync.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Offline sync for Inventory CLI
+// Merges two inventory database files both ways, field by field,
+// using per-row versions and the state at the last sync.
+//
+
+package inventory
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// itemVersionsTable is the DDL of the item_versions table.
+//
+// Triggers on the inventory table bump the version of a row and
+// stamp it (IST) on every insert, update and delete. Deleted rows
+// keep their entry with deleted = 1.
+//
+// uid is a random ID given to the row when it is first inserted.
+// It names the item across database files, whose autoincrement
+// IDs are handed out independently; Sync() matches items by uid.
+const itemVersionsTable = `
+    CREATE TABLE IF NOT EXISTS item_versions (
+        item_id INTEGER PRIMARY KEY,
+        version INTEGER NOT NULL,
+        updated_at TEXT NOT NULL,
+        deleted INTEGER NOT NULL DEFAULT 0,
+        uid TEXT
+    );`
+
+// syncMetaTable holds settings of the database file, such as
+// its random sync ID and where it was last opened.
+const syncMetaTable = `
+    CREATE TABLE IF NOT EXISTS sync_meta (
+        key TEXT PRIMARY KEY,
+        value TEXT
+    );`
+
+// syncBaseTable is the DDL of the sync_base table.
+//
+// It keeps, per peer database, each item (by uid) as it was
+// agreed at the last sync, with this file's IDs (row is NULL once
+// deleted on both sides), and the versions both files had then.
+const syncBaseTable = `
+    CREATE TABLE IF NOT EXISTS sync_base (
+        peer TEXT NOT NULL,
+        uid TEXT NOT NULL,
+        local_version INTEGER NOT NULL,
+        remote_version INTEGER NOT NULL,
+        row TEXT,
+        PRIMARY KEY (peer, uid)
+    );`
+
+// newUID is the SQL expression of a random uid.
+const newUID = `lower(hex(randomblob(16)))`
+
+// installVersionTriggers (re)creates the triggers that keep
+// item_versions up to date. Called by OpenDB.
+//
+// A row keeps its uid through updates and deletes, and when
+// Revert() inserts it again with the same ID.
+func installVersionTriggers(db *sql.DB) error {
+	bump := func(ref string, deleted int) string {
+		return fmt.Sprintf(`
+            INSERT OR REPLACE INTO item_versions
+            (item_id, version, updated_at, deleted, uid)
+            VALUES (%[1]sid,
+                COALESCE((SELECT version FROM item_versions
+                    WHERE item_id = %[1]sid), 0) + 1,
+                strftime('%%Y-%%m-%%d %%H:%%M:%%S', 'now', '+330 minutes'),
+                %[2]d,
+                COALESCE((SELECT uid FROM item_versions
+                    WHERE item_id = %[1]sid), %[3]s));`,
+			ref, deleted, newUID)
+	}
+	triggers := map[string]string{
+		"insert": `AFTER INSERT ON inventory BEGIN` +
+			bump("NEW.", 0) + ` END;`,
+		"update": `AFTER UPDATE ON inventory BEGIN` +
+			bump("NEW.", 0) + ` END;`,
+		"delete": `AFTER DELETE ON inventory BEGIN` +
+			bump("OLD.", 1) + ` END;`,
+	}
+	for _, name := range []string{"insert", "update", "delete"} {
+		_, err := db.Exec("DROP TRIGGER IF EXISTS inventory_version_" + name)
+		if err == nil {
+			_, err = db.Exec("CREATE TRIGGER inventory_version_" + name +
+				" " + triggers[name])
+		}
+		if err != nil {
+			return fmt.Errorf("version trigger failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// initSyncTables gives every item a uid and upgrades sync_base
+// tables keyed by item ID, whose bases are dropped and rebuilt by
+// the next sync. Called by OpenDB.
+func initSyncTables(db *sql.DB) error {
+	_, err := db.Exec(`
+        INSERT OR IGNORE INTO item_versions
+        (item_id, version, updated_at, deleted, uid)
+        SELECT id, 0,
+            strftime('%Y-%m-%d %H:%M:%S', 'now', '+330 minutes'),
+            0, ` + newUID + `
+        FROM inventory`)
+	if err == nil {
+		_, err = db.Exec(`
+            UPDATE item_versions SET uid = ` + newUID + `
+            WHERE uid IS NULL`)
+	}
+	if err == nil {
+		_, err = db.Exec(`
+            CREATE UNIQUE INDEX IF NOT EXISTS item_versions_uid
+            ON item_versions (uid)`)
+	}
+	if err != nil {
+		return fmt.Errorf("item uid init failed: %v", err)
+	}
+
+	cols, err := tableColumns(db, "sync_base")
+	if err != nil {
+		return err
+	}
+	for _, c := range cols {
+		if c == "uid" {
+			return nil
+		}
+	}
+	_, err = db.Exec(`DROP TABLE sync_base`)
+	if err == nil {
+		_, err = db.Exec(syncBaseTable)
+	}
+	if err != nil {
+		return fmt.Errorf("sync base upgrade failed: %v", err)
+	}
+	return nil
+}
+
+// dbOrigin returns the host and path of the database file as
+// "host:/path", or "" for an in-memory database.
+func dbOrigin(db Querier) (string, error) {
+	var file string
+	err := db.QueryRow(`
+        SELECT file FROM pragma_database_list
+        WHERE name = 'main'`).Scan(&file)
+	if err != nil {
+		return "", fmt.Errorf("database file query failed: %v", err)
+	}
+	if file == "" {
+		return "", nil
+	}
+	host, _ := os.Hostname()
+	return host + ":" + file, nil
+}
+
+// renewSyncID gives the database a new sync ID and drops its
+// sync bases, which belong to the file it was copied from.
+func renewSyncID(exec Execer, origin string) error {
+	_, err := exec.Exec(`
+        UPDATE sync_meta SET value = ` + newUID + `
+        WHERE key = 'sync_id'`)
+	if err == nil {
+		_, err = exec.Exec(`DELETE FROM sync_base`)
+	}
+	if err == nil {
+		_, err = exec.Exec(`
+            INSERT OR REPLACE INTO sync_meta (key, value)
+            VALUES ('origin', ?)`, origin)
+	}
+	if err != nil {
+		return fmt.Errorf("renew sync id failed: %v", err)
+	}
+	return nil
+}
+
+// checkOrigin records where the database file lives. A file
+// opened at another path or on another host than before is taken
+// for a copy and gets a new sync ID, see renewSyncID(). Called by
+// OpenDB.
+func checkOrigin(db *sql.DB) error {
+	origin, err := dbOrigin(db)
+	if err != nil || origin == "" {
+		return err
+	}
+	var stored string
+	err = db.QueryRow(`
+        SELECT value FROM sync_meta WHERE key = 'origin'`).Scan(&stored)
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = db.Exec(`
+            INSERT INTO sync_meta (key, value)
+            VALUES ('origin', ?)`, origin)
+		if err != nil {
+			return fmt.Errorf("set origin failed: %v", err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("origin query failed: %v", err)
+	case stored == origin:
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx failed: %v", err)
+	}
+	if err := renewSyncID(tx, origin); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx failed: %v", err)
+	}
+	return nil
+}
+
+// SyncConflict is a field changed differently on both sides.
+//
+// Field "deleted" means one side deleted the item while the other
+// changed it; Local or Remote then reads "deleted". ItemID is the
+// local ID of the item, RemoteID its ID in the other file when
+// that differs.
+type SyncConflict struct {
+	ItemID     int    `json:"item_id"`
+	RemoteID   int    `json:"remote_id,omitempty"`
+	Field      string `json:"field"`
+	Local      string `json:"local"`
+	Remote     string `json:"remote"`
+	LocalTime  string `json:"local_time"`
+	RemoteTime string `json:"remote_time"`
+}
+
+// SyncRenumber is an item copied under a new ID, because its ID
+// was already used by another item in the file it was copied to.
+// Side is "local" or "remote", the file that received the item.
+// ImportDelta() reports its renumbered items as "local".
+type SyncRenumber struct {
+	Side string `json:"side"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+}
+
+// SyncReport is the outcome of a Sync().
+//
+// Fields:
+//
+//	Pulled     - items changed in the local file
+//	Pushed     - items changed in the other file
+//	Renumbered - items copied under a new ID
+//	Conflicts  - fields left as they are on each side
+type SyncReport struct {
+	Pulled     []int          `json:"pulled"`
+	Pushed     []int          `json:"pushed"`
+	Renumbered []SyncRenumber `json:"renumbered,omitempty"`
+	Conflicts  []SyncConflict `json:"conflicts,omitempty"`
+}
+
+// WriteSyncReport prints a sync report:
+//
+//	pulled 2: 1003 1007
+//	pushed 1: 1012
+//	renumbered local 1012 → 1013
+//	conflict 1004 location: local "Lab" (2025-06-20 10:02:11),
+//	    remote "Site B" (2025-06-21 16:40:05)
+//
+// Errors are returned if writing fails.
+func WriteSyncReport(w io.Writer, r SyncReport) error {
+	ids := func(list []int) string {
+		var parts []string
+		for _, id := range list {
+			parts = append(parts, fmt.Sprint(id))
+		}
+		return strings.Join(parts, " ")
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "pulled %d: %s\n", len(r.Pulled), ids(r.Pulled))
+	fmt.Fprintf(&b, "pushed %d: %s\n", len(r.Pushed), ids(r.Pushed))
+	for _, n := range r.Renumbered {
+		fmt.Fprintf(&b, "renumbered %s %d → %d\n", n.Side, n.From, n.To)
+	}
+	for _, c := range r.Conflicts {
+		id := fmt.Sprint(c.ItemID)
+		if c.RemoteID != 0 {
+			id += fmt.Sprintf(" (remote %d)", c.RemoteID)
+		}
+		fmt.Fprintf(&b, "conflict %s %s: local %q (%s),\n"+
+			"    remote %q (%s)\n", id, c.Field,
+			c.Local, c.LocalTime, c.Remote, c.RemoteTime)
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("write report failed: %v", err)
+	}
+	return nil
+}
+
+// rowVersion is an item_versions entry.
+type rowVersion struct {
+	id        int
+	version   int
+	updatedAt string
+}
+
+// syncBase is a sync_base entry.
+type syncBase struct {
+	local  int
+	remote int
+	item   *Item
+}
+
+// syncSide is the state of one database file during a sync.
+// Items, versions and bases are keyed by uid; ids maps each uid
+// known to this file, or given an ID by allocate(), to its ID.
+type syncSide struct {
+	tx       ExecQuerier
+	id       string
+	name     string
+	items    map[string]Item
+	versions map[string]rowVersion
+	ids      map[string]int
+	uids     map[int]string
+	base     map[string]syncBase
+	changed  map[int]bool
+}
+
+// syncIDInit gives a database file its random sync ID, once.
+// OpenDB runs it on every open.
+const syncIDInit = `
+    INSERT OR IGNORE INTO sync_meta (key, value)
+    VALUES ('sync_id', ` + newUID + `);`
+
+// syncID returns the sync ID of the database, see syncIDInit.
+func syncID(db Querier) (string, error) {
+	var id string
+	err := db.QueryRow(`
+        SELECT value FROM sync_meta WHERE key = 'sync_id'`).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("sync id query failed: %v", err)
+	}
+	return id, nil
+}
+
+// loadVersions reads item_versions by uid.
+func loadVersions(db Querier) (map[string]rowVersion, error) {
+	rows, err := db.Query(`
+        SELECT uid, item_id, version, updated_at FROM item_versions
+        WHERE uid IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("version query failed: %v", err)
+	}
+	defer rows.Close()
+	versions := make(map[string]rowVersion)
+	for rows.Next() {
+		var uid string
+		var v rowVersion
+		err := rows.Scan(&uid, &v.id, &v.version, &v.updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		versions[uid] = v
+	}
+	return versions, rows.Err()
+}
+
+// loadSyncSide reads the items and versions of one database.
+func loadSyncSide(tx ExecQuerier, name string) (*syncSide, error) {
+	s := &syncSide{tx: tx, name: name, changed: make(map[int]bool)}
+	var err error
+	if s.id, err = syncID(tx); err != nil {
+		return nil, err
+	}
+	if s.versions, err = loadVersions(tx); err != nil {
+		return nil, err
+	}
+	s.ids = make(map[string]int, len(s.versions))
+	s.uids = make(map[int]string, len(s.versions))
+	for uid, v := range s.versions {
+		s.ids[uid] = v.id
+		s.uids[v.id] = uid
+	}
+
+	items, err := queryFiltered(tx, ItemFilter{})
+	if err != nil {
+		return nil, err
+	}
+	s.items = make(map[string]Item, len(items))
+	for _, item := range items {
+		uid, ok := s.uids[item.ID]
+		if !ok {
+			return nil, fmt.Errorf("item %d has no uid", item.ID)
+		}
+		s.items[uid] = item
+	}
+	return s, nil
+}
+
+// loadBase reads the sync base kept for a peer.
+func (s *syncSide) loadBase(peer string) error {
+	rows, err := s.tx.Query(`
+        SELECT uid, local_version, remote_version, COALESCE(row, '')
+        FROM sync_base WHERE peer = ?`, peer)
+	if err != nil {
+		return fmt.Errorf("sync base query failed: %v", err)
+	}
+	defer rows.Close()
+	s.base = make(map[string]syncBase)
+	for rows.Next() {
+		var uid, row string
+		var b syncBase
+		if err := rows.Scan(&uid, &b.local, &b.remote, &row); err != nil {
+			return fmt.Errorf("scan failed: %v", err)
+		}
+		if b.item, err = auditItem(row); err != nil {
+			return err
+		}
+		s.base[uid] = b
+	}
+	return rows.Err()
+}
+
+// idAllocator hands out IDs for items copied from another
+// database file: the item's own ID when no row of this file ever
+// had it, else the next ID above every ID in use.
+type idAllocator struct {
+	next int
+	used map[int]bool
+}
+
+// newIDAllocator reads the IDs in use, live or deleted.
+func newIDAllocator(db Querier) (*idAllocator, error) {
+	a := &idAllocator{used: make(map[int]bool)}
+	err := db.QueryRow(`
+        SELECT COALESCE((SELECT seq FROM sqlite_sequence
+            WHERE name = 'inventory'), 0)`).Scan(&a.next)
+	if err != nil {
+		return nil, fmt.Errorf("sequence query failed: %v", err)
+	}
+	rows, err := db.Query(`
+        SELECT item_id FROM item_versions
+        UNION SELECT id FROM inventory`)
+	if err != nil {
+		return nil, fmt.Errorf("id query failed: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		a.claim(id)
+	}
+	return a, rows.Err()
+}
+
+// claim takes the ID if it is free and reports whether it was.
+func (a *idAllocator) claim(id int) bool {
+	if id <= 0 || a.used[id] {
+		return false
+	}
+	a.used[id] = true
+	if id > a.next {
+		a.next = id
+	}
+	return true
+}
+
+// nextID takes the next free ID.
+func (a *idAllocator) nextID() int {
+	a.next++
+	a.used[a.next] = true
+	return a.next
+}
+
+// allocate gives every item of the other side that this side
+// has never had an ID here, see idAllocator. Items that could not
+// keep their ID are added to the report.
+func (s *syncSide) allocate(other *syncSide, report *SyncReport) error {
+	alloc, err := newIDAllocator(s.tx)
+	if err != nil {
+		return err
+	}
+
+	var missing []Item
+	for uid, item := range other.items {
+		if _, ok := s.ids[uid]; !ok {
+			missing = append(missing, item)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i].ID < missing[j].ID
+	})
+
+	var renumber []Item
+	for _, item := range missing {
+		if !alloc.claim(item.ID) {
+			renumber = append(renumber, item)
+			continue
+		}
+		uid := other.uids[item.ID]
+		s.ids[uid], s.uids[item.ID] = item.ID, uid
+	}
+	for _, item := range renumber {
+		id, uid := alloc.nextID(), other.uids[item.ID]
+		s.ids[uid], s.uids[id] = id, uid
+		report.Renumbered = append(report.Renumbered, SyncRenumber{
+			Side: s.name, From: item.ID, To: id,
+		})
+	}
+	return nil
+}
+
+// translate returns an item of the from side with the IDs of the
+// to side, for its own ID and its parent. A parent the to side
+// does not have is dropped.
+func translate(item Item, from, to *syncSide) Item {
+	item.ID = to.ids[from.uids[item.ID]]
+	if item.ParentID != 0 {
+		item.ParentID = to.ids[from.uids[item.ParentID]]
+	}
+	return item
+}
+
+// put writes an item to this side under its uid.
+func (s *syncSide) put(uid string, item Item) error {
+	s.changed[item.ID] = true
+	if err := restoreItem(s.tx, item); err != nil {
+		return err
+	}
+	_, err := s.tx.Exec(`
+        UPDATE item_versions SET uid = ? WHERE item_id = ?`, uid, item.ID)
+	if err != nil {
+		return fmt.Errorf("set item uid failed: %v", err)
+	}
+	return nil
+}
+
+// remove deletes an item from this side.
+func (s *syncSide) remove(id int) error {
+	s.changed[id] = true
+	return DeleteItem(s.tx, id)
+}
+
+// unionRemarks merges two remark logs: the local entries,
+// followed by the remote entries missing locally.
+func unionRemarks(local, remote string) string {
+	if local == remote || remote == "" {
+		return local
+	}
+	if local == "" {
+		return remote
+	}
+	have := make(map[string]bool)
+	lines := strings.Split(local, "\n")
+	for _, l := range lines {
+		have[l] = true
+	}
+	for _, l := range strings.Split(remote, "\n") {
+		if !have[l] {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mergeRemarks merges the remark logs of an item. Logs that only
+// had entries appended since the base are unioned. Without a
+// base, a log that extends the other is taken; any other change
+// on both sides is a conflict (ok is false).
+func mergeRemarks(base *Item, local, remote string) (string, bool) {
+	switch {
+	case local == remote:
+		return local, true
+	case base == nil && strings.HasPrefix(remote, local):
+		return remote, true
+	case base == nil && strings.HasPrefix(local, remote):
+		return local, true
+	case base == nil:
+		return "", false
+	case local == base.Remarks:
+		return remote, true
+	case remote == base.Remarks:
+		return local, true
+	case strings.HasPrefix(local, base.Remarks) &&
+		strings.HasPrefix(remote, base.Remarks):
+		return unionRemarks(local, remote), true
+	}
+	return "", false
+}
+
+// mergeItems merges the local and remote versions of an item
+// against the base, field by field, and returns the new local
+// and remote items and the new base. All three use local IDs.
+// A field changed on one side only takes that change; a field
+// changed differently on both sides is a conflict and keeps its
+// base value in the new base. Without a base every differing
+// field is a conflict. Remarks are merged by mergeRemarks().
+func mergeItems(base *Item, local, remote Item) (Item, Item, Item,
+	[]string) {
+	var b Item
+	if base != nil {
+		b = *base
+	}
+	newLocal, newRemote, newBase := local, remote, local
+	lv := reflect.ValueOf(&newLocal).Elem()
+	rv := reflect.ValueOf(&newRemote).Elem()
+	nb := reflect.ValueOf(&newBase).Elem()
+	bv := reflect.ValueOf(b)
+
+	var conflicts []string
+	t := lv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || name == "id" || name == "remarks" {
+			continue
+		}
+		l, r := lv.Field(i), rv.Field(i)
+		switch {
+		case l.Interface() == r.Interface():
+		case base != nil && l.Interface() == bv.Field(i).Interface():
+			l.Set(r)
+			nb.Field(i).Set(r)
+		case base != nil && r.Interface() == bv.Field(i).Interface():
+			r.Set(l)
+		default:
+			conflicts = append(conflicts, name)
+			nb.Field(i).Set(bv.Field(i))
+		}
+	}
+
+	if remarks, ok := mergeRemarks(base, local.Remarks,
+		remote.Remarks); ok {
+		newLocal.Remarks, newRemote.Remarks = remarks, remarks
+		newBase.Remarks = remarks
+	} else {
+		conflicts = append(conflicts, "remarks")
+		newBase.Remarks = b.Remarks
+	}
+	return newLocal, newRemote, newBase, conflicts
+}
+
+// syncFieldText renders a field of an item by JSON name.
+func syncFieldText(item Item, name string) string {
+	v := reflect.ValueOf(item)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("json"), ",")[0] == name {
+			return fmt.Sprint(v.Field(i).Interface())
+		}
+	}
+	return ""
+}
+
+// syncItem merges one item between the two sides and returns the
+// new base item in local IDs (nil when gone on both sides, keep
+// when the base must stay as it was).
+func syncItem(local, remote *syncSide, uid string, base *Item,
+	report *SyncReport) (next *Item, keep bool, err error) {
+	l, hasL := local.items[uid]
+	r, hasR := remote.items[uid]
+	conflict := func(field, lt, rt string) {
+		c := SyncConflict{
+			ItemID: local.ids[uid], Field: field, Local: lt, Remote: rt,
+			LocalTime:  local.versions[uid].updatedAt,
+			RemoteTime: remote.versions[uid].updatedAt,
+		}
+		if id := remote.ids[uid]; id != c.ItemID {
+			c.RemoteID = id
+		}
+		report.Conflicts = append(report.Conflicts, c)
+	}
+
+	switch {
+	case hasL && hasR:
+		rl := translate(r, remote, local)
+		nl, nr, nb, fields := mergeItems(base, l, rl)
+		for _, f := range fields {
+			conflict(f, syncFieldText(l, f), syncFieldText(rl, f))
+		}
+		if nl != l {
+			err = local.put(uid, nl)
+		}
+		if nr = translate(nr, local, remote); err == nil && nr != r {
+			err = remote.put(uid, nr)
+		}
+		return &nb, false, err
+
+	case hasL && base == nil:
+		return &l, false, remote.put(uid, translate(l, local, remote))
+	case hasR && base == nil:
+		rl := translate(r, remote, local)
+		return &rl, false, local.put(uid, rl)
+
+	case hasL:
+		if l != *base {
+			conflict("deleted", "changed", "deleted")
+			return nil, true, nil
+		}
+		return nil, false, local.remove(l.ID)
+	case hasR:
+		if translate(r, remote, local) != *base {
+			conflict("deleted", "deleted", "changed")
+			return nil, true, nil
+		}
+		return nil, false, remote.remove(r.ID)
+	}
+	return nil, false, nil
+}
+
+// saveBase records the agreed items in sync_base on both sides,
+// each in its own IDs, with the versions the files have now.
+// Items with conflicts get version -1, so the next sync looks at
+// them again.
+func saveBase(local, remote *syncSide, next map[string]*Item,
+	conflicted map[string]bool) error {
+	lvers, err := loadVersions(local.tx)
+	if err != nil {
+		return err
+	}
+	rvers, err := loadVersions(remote.tx)
+	if err != nil {
+		return err
+	}
+
+	row := func(item *Item) (interface{}, error) {
+		if item == nil {
+			return nil, nil
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("marshal sync base failed: %v", err)
+		}
+		return string(data), nil
+	}
+	for uid, item := range next {
+		lv, rv := lvers[uid].version, rvers[uid].version
+		if conflicted[uid] {
+			lv, rv = -1, -1
+		}
+		var ritem *Item
+		if item != nil {
+			r := translate(*item, local, remote)
+			ritem = &r
+		}
+		for _, s := range []struct {
+			side          *syncSide
+			peer          string
+			local, remote int
+			item          *Item
+		}{
+			{local, remote.id, lv, rv, item},
+			{remote, local.id, rv, lv, ritem},
+		} {
+			data, err := row(s.item)
+			if err != nil {
+				return err
+			}
+			_, err = s.side.tx.Exec(`
+                INSERT OR REPLACE INTO sync_base
+                (peer, uid, local_version, remote_version, row)
+                VALUES (?, ?, ?, ?, ?)`,
+				s.peer, uid, s.local, s.remote, data)
+			if err != nil {
+				return fmt.Errorf("save sync base failed: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// sameDatabase reports whether two sides are the same file. A
+// copy of a file opened at the same path keeps its sync ID, so
+// a remote side with the local sync ID but another file gets a
+// new sync ID here.
+func sameDatabase(local, remote *syncSide) (bool, error) {
+	if local.id != remote.id {
+		return false, nil
+	}
+	lo, err := dbOrigin(local.tx)
+	if err != nil {
+		return false, err
+	}
+	ro, err := dbOrigin(remote.tx)
+	if err != nil {
+		return false, err
+	}
+	if lo == ro {
+		return true, nil
+	}
+	if err := renewSyncID(remote.tx, ro); err != nil {
+		return false, err
+	}
+	remote.id, err = syncID(remote.tx)
+	return false, err
+}
+
+// syncDatabases merges two databases, each inside its own
+// transaction. See InventoryDB.Sync().
+func syncDatabases(ltx, rtx ExecQuerier) (SyncReport, error) {
+	var report SyncReport
+	local, err := loadSyncSide(ltx, "local")
+	if err != nil {
+		return report, err
+	}
+	remote, err := loadSyncSide(rtx, "remote")
+	if err != nil {
+		return report, err
+	}
+	same, err := sameDatabase(local, remote)
+	if err != nil {
+		return report, err
+	}
+	if same {
+		return report, fmt.Errorf("cannot sync a database with itself")
+	}
+	if err := local.loadBase(remote.id); err != nil {
+		return report, err
+	}
+	if err := local.allocate(remote, &report); err != nil {
+		return report, err
+	}
+	if err := remote.allocate(local, &report); err != nil {
+		return report, err
+	}
+
+	uids := make(map[string]bool)
+	for uid := range local.items {
+		uids[uid] = true
+	}
+	for uid := range remote.items {
+		uids[uid] = true
+	}
+	for uid := range local.base {
+		uids[uid] = true
+	}
+	sorted := make([]string, 0, len(uids))
+	for uid := range uids {
+		sorted = append(sorted, uid)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := local.ids[sorted[i]], local.ids[sorted[j]]
+		if a != b {
+			return a < b
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	next := make(map[string]*Item)
+	conflicted := make(map[string]bool)
+	for _, uid := range sorted {
+		b, hasBase := local.base[uid]
+		if hasBase && b.local == local.versions[uid].version &&
+			b.remote == remote.versions[uid].version {
+			continue
+		}
+		n := len(report.Conflicts)
+		item, keep, err := syncItem(local, remote, uid, b.item, &report)
+		if err != nil {
+			return report, fmt.Errorf("item %d: %v", local.ids[uid], err)
+		}
+		conflicted[uid] = len(report.Conflicts) > n
+		if !keep {
+			next[uid] = item
+		}
+	}
+	if err := saveBase(local, remote, next, conflicted); err != nil {
+		return report, err
+	}
+
+	for _, s := range []struct {
+		side *syncSide
+		list *[]int
+	}{
+		{local, &report.Pulled},
+		{remote, &report.Pushed},
+	} {
+		for id := range s.side.changed {
+			*s.list = append(*s.list, id)
+		}
+		sort.Ints(*s.list)
+	}
+	return report, nil
+}
+
+// SyncWith merges this database with another one, both ways.
+//
+// Items are matched by a uid given to every row when it is
+// inserted, not by ID, and each item is compared with its state
+// at the last sync between the two files:
+//   - a field changed on one side only is copied to the other
+//   - a field changed differently on both sides is a conflict,
+//     left as it is on each side and reported
+//   - remark logs appended to on both sides are unioned
+//   - new items are copied, deleted items are deleted on the other
+//     side unless changed there (a conflict)
+//
+// A copied item keeps its ID when that is free in the other file,
+// otherwise it gets the next free ID (see SyncReport.Renumbered),
+// so items created independently in both files with the same ID
+// stay two separate items.
+//
+// Rows whose versions did not change since the last sync are
+// skipped. The same item with no state from an earlier sync, as
+// in a copy of the file edited before its first sync, has its
+// differing fields reported as conflicts, and its remarks taken
+// only when one log extends the other.
+//
+// Both files are changed in one transaction each, as inv's actor,
+// who needs RoleManager on both.
+//
+// Usage:
+//
+//	report, err := inv.SyncWith(laptop)
+//	err = WriteSyncReport(os.Stdout, report)
+func (inv *InventoryDB) SyncWith(other *InventoryDB) (SyncReport, error) {
+	if other.db == inv.db {
+		return SyncReport{}, fmt.Errorf("cannot sync a database with itself")
+	}
+	other = other.WithActor(inv.actor)
+	var report SyncReport
+	err := inv.withTx("sync", func(ltx ExecQuerier) error {
+		return other.withTx("sync", func(rtx ExecQuerier) error {
+			var err error
+			report, err = syncDatabases(ltx, rtx)
+			return err
+		})
+	})
+	if err != nil {
+		return SyncReport{}, err
+	}
+	return report, nil
+}
+
+// Sync merges this database with the database file at path,
+// like `bvl sync other.db`. See SyncWith().
+//
+// Usage:
+//
+//	report, err := inv.Sync("laptop.db")
+//
+// The file must exist; a mistyped path is an error rather than
+// a new, empty database.
+func (inv *InventoryDB) Sync(path string) (SyncReport, error) {
+	if _, err := os.Stat(path); err != nil {
+		return SyncReport{}, fmt.Errorf("sync failed: %v", err)
+	}
+	other := NewInventoryDB(path)
+	defer other.Close()
+	return inv.SyncWith(other)
+}
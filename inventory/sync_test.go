@@ -0,0 +1,256 @@
+// sync_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for offline sync
+// Uses two SQLite database files in a temp directory
+//
+
+package inventory_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestInventoryDB_Sync(t *testing.T) {
+	dir := t.TempDir()
+	office := inventory.NewInventoryDB(filepath.Join(dir, "office.db"))
+	defer office.Close()
+	laptopFile := filepath.Join(dir, "laptop.db")
+	laptop := inventory.NewInventoryDB(laptopFile)
+	defer laptop.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "Generator", Location: "Yard",
+			Status: "OK", Remarks: "[2025-06-01 09:00] installed"},
+		{ID: 1002, Description: "Cable drum", Location: "Yard",
+			Status: "OK"},
+	} {
+		if err := office.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	report, err := office.Sync(laptopFile)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(report.Pushed) != 2 || len(report.Pulled) != 0 {
+		t.Fatalf("unexpected first sync: %+v", report)
+	}
+
+	// Edit both copies offline
+	g, _ := laptop.GetItemByID(1001)
+	g.Location, g.Remarks = "Site B", "taken to site"
+	laptop.EditItem(g)
+	g, _ = office.GetItemByID(1001)
+	g.Status, g.Remarks = "Serviced", "oil changed"
+	office.EditItem(g)
+
+	drum, _ := laptop.GetItemByID(1002)
+	drum.Description = "Cable drum 100m"
+	laptop.EditItem(drum)
+	drum.Description = "Cable drum 50m"
+	office.EditItem(drum)
+
+	laptop.AppendItem(inventory.Item{ID: 2001, Description: "Ladder"})
+
+	report, err = office.SyncWith(laptop)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].ItemID != 1002 ||
+		report.Conflicts[0].Field != "description" ||
+		report.Conflicts[0].Local != "Cable drum 50m" {
+		t.Errorf("unexpected conflicts: %+v", report.Conflicts)
+	}
+	for _, inv := range []*inventory.InventoryDB{office, laptop} {
+		g, _ := inv.GetItemByID(1001)
+		if g.Location != "Site B" || g.Status != "Serviced" ||
+			!strings.Contains(g.Remarks, "taken to site") ||
+			!strings.Contains(g.Remarks, "oil changed") {
+			t.Errorf("fields not merged: %+v", g)
+		}
+		if _, err := inv.GetItemByID(2001); err != nil {
+			t.Errorf("new item not copied: %v", err)
+		}
+	}
+	if d, _ := laptop.GetItemByID(1002); d.Description != "Cable drum 100m" {
+		t.Errorf("conflicting field overwritten: %+v", d)
+	}
+
+	var out strings.Builder
+	if err := inventory.WriteSyncReport(&out, report); err != nil {
+		t.Fatalf("WriteSyncReport failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "conflict 1002 description") {
+		t.Errorf("unexpected report:\n%s", out.String())
+	}
+
+	// Resolve the conflict, then delete on one side
+	drum.Description = "Cable drum 50m"
+	laptop.EditItem(drum)
+	office.DeleteItem(2001)
+	report, err = office.SyncWith(laptop)
+	if err != nil || len(report.Conflicts) != 0 {
+		t.Fatalf("Sync failed: %+v %v", report, err)
+	}
+	if _, err := laptop.GetItemByID(2001); err == nil {
+		t.Error("deleted item not deleted on the other side")
+	}
+	a, _ := office.GetItemByID(1002)
+	b, _ := laptop.GetItemByID(1002)
+	if a != b {
+		t.Errorf("copies differ:\n%+v\n%+v", a, b)
+	}
+
+	report, err = laptop.SyncWith(office)
+	if err != nil || len(report.Pulled)+len(report.Pushed) != 0 {
+		t.Errorf("expected nothing to sync: %+v %v", report, err)
+	}
+	if _, err := office.SyncWith(office); err == nil {
+		t.Error("expected error syncing with itself")
+	}
+}
+
+func TestInventoryDB_Sync_SameIDs(t *testing.T) {
+	dir := t.TempDir()
+	office := inventory.NewInventoryDB(filepath.Join(dir, "office.db"))
+	defer office.Close()
+	store := inventory.NewInventoryDB(filepath.Join(dir, "store.db"))
+	defer store.Close()
+
+	// Two different items created with the same ID in each file
+	office.AppendItem(inventory.Item{ID: 1001, Description: "Monitor",
+		Remarks: "[2025-06-01 09:00] bought"})
+	store.AppendItem(inventory.Item{ID: 1001, Description: "Drill",
+		Remarks: "[2025-06-02 10:00] lent out"})
+
+	report, err := office.SyncWith(store)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(report.Conflicts) != 0 || len(report.Renumbered) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	for _, inv := range []*inventory.InventoryDB{office, store} {
+		items, _ := inv.ListAll()
+		if len(items) != 2 {
+			t.Fatalf("expected both items: %+v", items)
+		}
+		for _, item := range items {
+			if strings.Contains(item.Remarks, "bought") ==
+				strings.Contains(item.Remarks, "lent out") {
+				t.Errorf("remarks mixed: %+v", item)
+			}
+		}
+	}
+	if d, _ := office.GetItemByID(1002); d.Description != "Drill" {
+		t.Errorf("item not renumbered: %+v", d)
+	}
+
+	var out strings.Builder
+	inventory.WriteSyncReport(&out, report)
+	if !strings.Contains(out.String(), "renumbered local 1001 → 1002") {
+		t.Errorf("unexpected report:\n%s", out.String())
+	}
+
+	// The renumbered copies stay linked to their originals
+	d, _ := office.GetItemByID(1002)
+	d.Location = "Workshop"
+	office.EditItem(d)
+	report, err = office.SyncWith(store)
+	if err != nil || len(report.Conflicts) != 0 ||
+		len(report.Pushed) != 1 || report.Pushed[0] != 1001 {
+		t.Fatalf("unexpected sync: %+v %v", report, err)
+	}
+	if d, _ := store.GetItemByID(1001); d.Location != "Workshop" {
+		t.Errorf("change not synced to the original: %+v", d)
+	}
+}
+
+func TestInventoryDB_Sync_Copy(t *testing.T) {
+	dir := t.TempDir()
+	officeFile := filepath.Join(dir, "office.db")
+	office := inventory.NewInventoryDB(officeFile)
+	defer office.Close()
+	office.AppendItem(inventory.Item{ID: 1001, Description: "Generator"})
+
+	data, err := os.ReadFile(officeFile)
+	if err != nil {
+		t.Fatalf("read file failed: %v", err)
+	}
+	laptopFile := filepath.Join(dir, "laptop.db")
+	if err := os.WriteFile(laptopFile, data, 0o644); err != nil {
+		t.Fatalf("copy file failed: %v", err)
+	}
+	laptop := inventory.NewInventoryDB(laptopFile)
+	defer laptop.Close()
+
+	laptop.AddItem(inventory.Item{Description: "Ladder"})
+	report, err := office.SyncWith(laptop)
+	if err != nil {
+		t.Fatalf("Sync with a copy failed: %v", err)
+	}
+	if len(report.Pulled) != 1 || len(report.Renumbered) != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	items, _ := office.ListAll()
+	if len(items) != 2 {
+		t.Errorf("items duplicated or missing: %+v", items)
+	}
+
+	if _, err := office.Sync(filepath.Join(dir, "lapotp.db")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "lapotp.db")); err == nil {
+		t.Error("missing file was created")
+	}
+}
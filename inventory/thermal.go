@@ -0,0 +1,350 @@
+// thermal.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Thermal label printer output for Inventory CLI
+// Emits ZPL or EPL item tags for Zebra-style printers,
+// to a file or straight to a raw printer device.
+//
+
+package inventory
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Printer languages for ThermalTemplate.
+const (
+	ThermalZPL = "zpl"
+	ThermalEPL = "epl"
+)
+
+// Fields that can be printed on a thermal label.
+const (
+	FieldBarcode     = "barcode"
+	FieldID          = "id"
+	FieldDescription = "description"
+	FieldLocation    = "location"
+	FieldStatus      = "status"
+)
+
+// ThermalTemplate configures a thermal label.
+// Sizes are in millimetres and converted to printer dots.
+//
+// Fields are printed top to bottom in the given order, each on
+// its own line. Blank values take the DefaultThermalTemplate
+// setting, so a template file only needs what it changes.
+//
+// Example template file:
+//
+//	{
+//	  "language": "zpl",
+//	  "width": 50, "height": 25, "dpi": 203,
+//	  "fields": ["barcode", "id", "description"]
+//	}
+type ThermalTemplate struct {
+	Language      string   `json:"language,omitempty"`
+	Width         float64  `json:"width,omitempty"`
+	Height        float64  `json:"height,omitempty"`
+	DPI           int      `json:"dpi,omitempty"`
+	Margin        float64  `json:"margin,omitempty"`
+	BarcodeHeight float64  `json:"barcode_height,omitempty"`
+	TextHeight    float64  `json:"text_height,omitempty"`
+	Fields        []string `json:"fields,omitempty"`
+}
+
+// DefaultThermalTemplate is a 50 x 25 mm ZPL tag at 203 DPI with
+// the ID barcode, the description and the location.
+var DefaultThermalTemplate = ThermalTemplate{
+	Language:      ThermalZPL,
+	Width:         50,
+	Height:        25,
+	DPI:           203,
+	Margin:        2,
+	BarcodeHeight: 10,
+	TextHeight:    3,
+	Fields: []string{
+		FieldBarcode, FieldDescription, FieldLocation,
+	},
+}
+
+// withDefaults fills blank settings from DefaultThermalTemplate
+// and validates the result.
+func (t ThermalTemplate) withDefaults() (ThermalTemplate, error) {
+	d := DefaultThermalTemplate
+	if t.Language == "" {
+		t.Language = d.Language
+	}
+	if t.Width == 0 {
+		t.Width = d.Width
+	}
+	if t.Height == 0 {
+		t.Height = d.Height
+	}
+	if t.DPI == 0 {
+		t.DPI = d.DPI
+	}
+	if t.Margin == 0 {
+		t.Margin = d.Margin
+	}
+	if t.BarcodeHeight == 0 {
+		t.BarcodeHeight = d.BarcodeHeight
+	}
+	if t.TextHeight == 0 {
+		t.TextHeight = d.TextHeight
+	}
+	if len(t.Fields) == 0 {
+		t.Fields = d.Fields
+	}
+
+	t.Language = strings.ToLower(t.Language)
+	if t.Language != ThermalZPL && t.Language != ThermalEPL {
+		return t, fmt.Errorf("unknown printer language %q", t.Language)
+	}
+	for _, f := range t.Fields {
+		switch f {
+		case FieldBarcode, FieldID, FieldDescription,
+			FieldLocation, FieldStatus:
+		default:
+			return t, fmt.Errorf("unknown label field %q", f)
+		}
+	}
+	return t, nil
+}
+
+// dots converts millimetres to printer dots.
+func (t ThermalTemplate) dots(v float64) int {
+	return int(math.Round(v * float64(t.DPI) / 25.4))
+}
+
+// LoadThermalTemplate reads a ThermalTemplate from a JSON file.
+//
+// Usage:
+//
+//	tmpl, err := LoadThermalTemplate("zebra-50x25.json")
+func LoadThermalTemplate(filename string) (ThermalTemplate, error) {
+	var t ThermalTemplate
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return t, fmt.Errorf("read template failed: %v", err)
+	}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return t, fmt.Errorf("invalid template: %v", err)
+	}
+	return t.withDefaults()
+}
+
+// fieldText returns the printed text of a label field.
+func fieldText(item Item, field string) string {
+	switch field {
+	case FieldID:
+		return strconv.Itoa(item.ID)
+	case FieldDescription:
+		return item.Description
+	case FieldLocation:
+		return item.Location
+	case FieldStatus:
+		return item.Status
+	}
+	return ""
+}
+
+// zplField escapes text for a ^FH field: '_' starts a hex escape,
+// so it and the command prefixes '^' and '~' are written as hex.
+// Line breaks become spaces, the field is printed on one line.
+var zplField = strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E",
+	"\r\n", " ", "\r", " ", "\n", " ")
+
+// writeZPL writes one ZPL label.
+func writeZPL(w *bufio.Writer, t ThermalTemplate, item Item) {
+	x, y := t.dots(t.Margin), t.dots(t.Margin)
+	bh, th := t.dots(t.BarcodeHeight), t.dots(t.TextHeight)
+
+	fmt.Fprintf(w, "^XA\n^CI28\n^PW%d\n^LL%d\n",
+		t.dots(t.Width), t.dots(t.Height))
+	for _, f := range t.Fields {
+		if f == FieldBarcode {
+			fmt.Fprintf(w, "^FO%d,%d^BY2^BCN,%d,N,N,N^FD%d^FS\n",
+				x, y, bh, item.ID)
+			y += bh + th/2
+			continue
+		}
+		fmt.Fprintf(w, "^FO%d,%d^A0N,%d,%d^FH_^FD%s^FS\n",
+			x, y, th, th, zplField.Replace(fieldText(item, f)))
+		y += th + th/3
+	}
+	w.WriteString("^XZ\n")
+}
+
+// eplField escapes text for a quoted EPL field. Line breaks become
+// spaces, as a raw one would end the command.
+var eplField = strings.NewReplacer(`\`, `\\`, `"`, `\"`,
+	"\r\n", " ", "\r", " ", "\n", " ")
+
+// eplFont picks the largest EPL resident font (1-5) whose glyphs
+// fit the text height in dots.
+func eplFont(t ThermalTemplate, height int) int {
+	// Glyph heights of fonts 1-5 at 203 DPI, scaled for 300 DPI.
+	sizes := []int{12, 16, 20, 24, 48}
+	font := 1
+	for i, s := range sizes {
+		if s*t.DPI/203 <= height {
+			font = i + 1
+		}
+	}
+	return font
+}
+
+// writeEPL writes one EPL label.
+func writeEPL(w *bufio.Writer, t ThermalTemplate, item Item) {
+	x, y := t.dots(t.Margin), t.dots(t.Margin)
+	bh, th := t.dots(t.BarcodeHeight), t.dots(t.TextHeight)
+	font := eplFont(t, th)
+
+	fmt.Fprintf(w, "\nN\nq%d\nQ%d,24\n", t.dots(t.Width), t.dots(t.Height))
+	for _, f := range t.Fields {
+		if f == FieldBarcode {
+			fmt.Fprintf(w, "B%d,%d,0,1,2,4,%d,N,\"%d\"\n",
+				x, y, bh, item.ID)
+			y += bh + th/2
+			continue
+		}
+		fmt.Fprintf(w, "A%d,%d,0,%d,1,1,N,\"%s\"\n",
+			x, y, font, eplField.Replace(fieldText(item, f)))
+		y += th + th/3
+	}
+	w.WriteString("P1\n")
+}
+
+// WriteThermalLabels writes one label per item in the template's
+// printer language.
+//
+// Usage:
+//
+//	err := WriteThermalLabels(os.Stdout, DefaultThermalTemplate, items)
+//
+// Example ZPL output for one item:
+//
+//	^XA
+//	^CI28
+//	^PW400
+//	^LL200
+//	^FO16,16^BY2^BCN,80,N,N,N^FD1001^FS
+//	^FO16,108^A0N,24,24^FH_^FDUPS 3KVA^FS
+//	^FO16,140^A0N,24,24^FH_^FDRack 1^FS
+//	^XZ
+//
+// Errors are returned for invalid templates or if writing fails.
+func WriteThermalLabels(out io.Writer, t ThermalTemplate,
+	items []Item) error {
+	t, err := t.withDefaults()
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(out)
+	for _, item := range items {
+		if t.Language == ThermalEPL {
+			writeEPL(w, t, item)
+		} else {
+			writeZPL(w, t, item)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write labels failed: %v", err)
+	}
+	return nil
+}
+
+// PrintThermalLabels writes labels for the items selected by the
+// filter to path, which may be a file or a raw printer device
+// such as /dev/usb/lp0.
+//
+// Usage:
+//
+//	f, _ := ParseItemFilter("location=Store 2")
+//	n, err := PrintThermalLabels(db, f, tmpl, "/dev/usb/lp0")
+//
+// Returns the number of labels written.
+//
+// Works with both *sql.DB and *sql.Tx.
+func PrintThermalLabels(db Querier, f ItemFilter, t ThermalTemplate,
+	path string) (int, error) {
+	items, err := queryFiltered(db, f)
+	if err != nil {
+		return 0, err
+	}
+
+	// A regular file is replaced, a device just receives the data.
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open label output: %v", err)
+	}
+	err = WriteThermalLabels(out, t, items)
+	if cerr := out.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to close label output: %v", cerr)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
+
+// PrintThermalLabels wraps PrintThermalLabels.
+//
+// Usage:
+//
+//	n, err := inv.PrintThermalLabels(filter, tmpl, "labels.zpl")
+func (inv *InventoryDB) PrintThermalLabels(f ItemFilter,
+	t ThermalTemplate, path string) (int, error) {
+	return PrintThermalLabels(inv.db, f, t, path)
+}
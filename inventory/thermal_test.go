@@ -0,0 +1,182 @@
+// thermal_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for thermal label printer output
+// Compares generated ZPL/EPL against expected files
+//
+
+package inventory_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestWriteThermalLabels_ZPL(t *testing.T) {
+	var buf bytes.Buffer
+	items := []inventory.Item{
+		{ID: 1001, Description: "UPS 3KVA", Location: "Rack_1 ^A"},
+	}
+	err := inventory.WriteThermalLabels(
+		&buf, inventory.DefaultThermalTemplate, items)
+	if err != nil {
+		t.Fatalf("WriteThermalLabels failed: %v", err)
+	}
+
+	want := "^XA\n^CI28\n^PW400\n^LL200\n" +
+		"^FO16,16^BY2^BCN,80,N,N,N^FD1001^FS\n" +
+		"^FO16,108^A0N,24,24^FH_^FDUPS 3KVA^FS\n" +
+		"^FO16,140^A0N,24,24^FH_^FDRack_5F1 _5EA^FS\n" +
+		"^XZ\n"
+	if buf.String() != want {
+		t.Errorf("unexpected ZPL:\n%s", buf.String())
+	}
+}
+
+func TestWriteThermalLabels_EPL(t *testing.T) {
+	var buf bytes.Buffer
+	tmpl := inventory.ThermalTemplate{
+		Language: "EPL",
+		Fields:   []string{"barcode", "id", "status"},
+	}
+	items := []inventory.Item{{ID: 1002, Status: `Say "hi"`}}
+	if err := inventory.WriteThermalLabels(&buf, tmpl, items); err != nil {
+		t.Fatalf("WriteThermalLabels failed: %v", err)
+	}
+
+	want := "\nN\nq400\nQ200,24\n" +
+		"B16,16,0,1,2,4,80,N,\"1002\"\n" +
+		"A16,108,0,4,1,1,N,\"1002\"\n" +
+		"A16,140,0,4,1,1,N,\"Say \\\"hi\\\"\"\n" +
+		"P1\n"
+	if buf.String() != want {
+		t.Errorf("unexpected EPL:\n%q", buf.String())
+	}
+
+	tmpl.Fields = []string{"price"}
+	if err := inventory.WriteThermalLabels(&buf, tmpl, items); err == nil {
+		t.Error("expected error for unknown field")
+	}
+	tmpl = inventory.ThermalTemplate{Language: "dpl"}
+	if err := inventory.WriteThermalLabels(&buf, tmpl, items); err == nil {
+		t.Error("expected error for unknown language")
+	}
+}
+
+func TestWriteThermalLabels_LineBreaks(t *testing.T) {
+	items := []inventory.Item{
+		{ID: 1001, Description: "UPS\r\n3KVA", Location: "Rack 1\nP1\rQ2"},
+	}
+	for _, lang := range []string{"ZPL", "EPL"} {
+		var buf bytes.Buffer
+		tmpl := inventory.ThermalTemplate{
+			Language: lang,
+			Fields:   []string{"description", "location"},
+		}
+		err := inventory.WriteThermalLabels(&buf, tmpl, items)
+		if err != nil {
+			t.Fatalf("WriteThermalLabels failed: %v", err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, "UPS 3KVA") ||
+			!strings.Contains(out, "Rack 1 P1 Q2") ||
+			strings.Contains(out, "\r") {
+			t.Errorf("line breaks not replaced in %s:\n%q", lang, out)
+		}
+	}
+}
+
+func TestInventoryDB_PrintThermalLabels(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "UPS", Location: "Store"},
+		{ID: 1002, Description: "PDU", Location: "Lab"},
+		{ID: 1003, Description: "Cable", Location: "Store"},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	dir := t.TempDir()
+	tmplFile := filepath.Join(dir, "tag.json")
+	err := os.WriteFile(tmplFile,
+		[]byte(`{"dpi": 300, "fields": ["barcode", "id"]}`), 0644)
+	if err != nil {
+		t.Fatalf("write template failed: %v", err)
+	}
+	tmpl, err := inventory.LoadThermalTemplate(tmplFile)
+	if err != nil {
+		t.Fatalf("LoadThermalTemplate failed: %v", err)
+	}
+
+	out := filepath.Join(dir, "labels.zpl")
+	n, err := inv.PrintThermalLabels(
+		inventory.ItemFilter{Location: "Store"}, tmpl, out)
+	if err != nil {
+		t.Fatalf("PrintThermalLabels failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 labels, got %d", n)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read output failed: %v", err)
+	}
+	if strings.Count(string(data), "^XA") != 2 ||
+		!strings.Contains(string(data), "^PW591") ||
+		!strings.Contains(string(data), "^FD1003^FS") {
+		t.Errorf("unexpected output:\n%s", data)
+	}
+}
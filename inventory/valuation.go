@@ -0,0 +1,613 @@
+// valuation.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Inventory valuation and depreciation for Inventory CLI
+// Values stock at average cost or FIFO, groups the totals, and
+// computes straight-line and written-down-value depreciation.
+//
+
+package inventory
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Valuation methods.
+const (
+	ValuationAverage = "average"
+	ValuationFIFO    = "fifo"
+)
+
+// Depreciation methods.
+const (
+	DepreciationSL  = "sl"  // straight line
+	DepreciationWDV = "wdv" // written-down value
+)
+
+// ValuationLine is the value of one item.
+//
+// Fields:
+//
+//	Units     - units valued, see itemUnits()
+//	UnitValue - value of one unit by the chosen method
+//	Value     - Units x UnitValue
+type ValuationLine struct {
+	ItemID      int     `json:"item_id"`
+	Description string  `json:"description"`
+	Location    string  `json:"location"`
+	Status      string  `json:"status"`
+	Units       int     `json:"units"`
+	UnitValue   float64 `json:"unit_value"`
+	Value       float64 `json:"value"`
+}
+
+// ValuationGroup is the total of the items sharing a location
+// or status.
+type ValuationGroup struct {
+	Key   string  `json:"key"`
+	Units int     `json:"units"`
+	Value float64 `json:"value"`
+}
+
+// ValuationReport is a complete valuation, ready for export.
+type ValuationReport struct {
+	Method  string           `json:"method"`
+	Date    string           `json:"date"`
+	GroupBy string           `json:"group_by,omitempty"`
+	Total   float64          `json:"total"`
+	Groups  []ValuationGroup `json:"groups,omitempty"`
+	Lines   []ValuationLine  `json:"lines"`
+}
+
+// itemUnits returns the units of an item to value.
+//
+// Items that do not track stock (quantity and reorder quantity
+// both 0) are single assets and count as one unit.
+func itemUnits(item Item) int {
+	if item.Quantity == 0 && item.ReorderQty == 0 {
+		return 1
+	}
+	return item.Quantity
+}
+
+// purchasesByItem loads all purchase lines, newest first,
+// keyed by item ID.
+func purchasesByItem(db Querier) (map[int][]Purchase, error) {
+	rows, err := db.Query(`
+        SELECT item_id, unit_cost, quantity
+        FROM purchases
+        ORDER BY COALESCE(invoice_date, '') DESC, id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("purchase query failed: %v", err)
+	}
+	defer rows.Close()
+
+	byItem := make(map[int][]Purchase)
+	for rows.Next() {
+		var p Purchase
+		if err := rows.Scan(&p.ItemID, &p.UnitCost, &p.Quantity); err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		byItem[p.ItemID] = append(byItem[p.ItemID], p)
+	}
+	return byItem, rows.Err()
+}
+
+// unitValue values units of an item from its purchases.
+//
+// Average cost is the weighted mean of all purchases. FIFO
+// assumes the oldest units were used first, so the units on hand
+// come from the latest purchases. Units not covered by any
+// purchase are valued at the item's UnitCost.
+func unitValue(item Item, units int, buys []Purchase,
+	method string) float64 {
+	if len(buys) == 0 || units == 0 {
+		return item.UnitCost
+	}
+
+	if method == ValuationAverage {
+		var cost float64
+		var qty int
+		for _, p := range buys {
+			cost += p.Total()
+			qty += p.Quantity
+		}
+		return cost / float64(qty)
+	}
+
+	var value float64
+	left := units
+	for _, p := range buys {
+		take := p.Quantity
+		if take > left {
+			take = left
+		}
+		value += float64(take) * p.UnitCost
+		left -= take
+		if left == 0 {
+			break
+		}
+	}
+	value += float64(left) * item.UnitCost
+	return value / float64(units)
+}
+
+// Valuate values the items selected by the filter, sorted by ID.
+//
+// Usage:
+//
+//	lines, err := Valuate(db, ItemFilter{}, ValuationFIFO)
+//
+// Works with both *sql.DB and *sql.Tx.
+func Valuate(db Querier, f ItemFilter, method string) ([]ValuationLine, error) {
+	if method != ValuationAverage && method != ValuationFIFO {
+		return nil, fmt.Errorf("unknown valuation method %q", method)
+	}
+	items, err := queryFiltered(db, f)
+	if err != nil {
+		return nil, err
+	}
+	buys, err := purchasesByItem(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []ValuationLine
+	for _, item := range items {
+		units := itemUnits(item)
+		value := unitValue(item, units, buys[item.ID], method)
+		lines = append(lines, ValuationLine{
+			ItemID:      item.ID,
+			Description: item.Description,
+			Location:    item.Location,
+			Status:      item.Status,
+			Units:       units,
+			UnitValue:   roundMoney(value),
+			Value:       roundMoney(value * float64(units)),
+		})
+	}
+	return lines, nil
+}
+
+// roundMoney rounds to two decimal places.
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// GroupValuation totals valuation lines by "location" or
+// "status", sorted by key.
+//
+// Items carry no tags, so there is no grouping by tag; it needs
+// a tag field or table first.
+func GroupValuation(lines []ValuationLine,
+	by string) ([]ValuationGroup, error) {
+	key := func(l ValuationLine) string { return l.Location }
+	switch by {
+	case "location":
+	case "status":
+		key = func(l ValuationLine) string { return l.Status }
+	default:
+		return nil, fmt.Errorf("cannot group valuation by %q", by)
+	}
+
+	sums := make(map[string]*ValuationGroup)
+	for _, l := range lines {
+		g, ok := sums[key(l)]
+		if !ok {
+			g = &ValuationGroup{Key: key(l)}
+			sums[key(l)] = g
+		}
+		g.Units += l.Units
+		g.Value = roundMoney(g.Value + l.Value)
+	}
+
+	groups := make([]ValuationGroup, 0, len(sums))
+	for _, g := range sums {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+	return groups, nil
+}
+
+// NewValuationReport values the items selected by the filter and
+// totals them, grouped by "location" or "status" unless groupBy
+// is blank.
+//
+// Usage:
+//
+//	r, err := NewValuationReport(db, ItemFilter{},
+//	    ValuationAverage, "location")
+//	err = WriteValuationReport(os.Stdout, r)
+//
+// Works with both *sql.DB and *sql.Tx.
+func NewValuationReport(db Querier, f ItemFilter,
+	method, groupBy string) (ValuationReport, error) {
+	r := ValuationReport{Method: method, Date: today(), GroupBy: groupBy}
+	lines, err := Valuate(db, f, method)
+	if err != nil {
+		return r, err
+	}
+	r.Lines = lines
+	for _, l := range lines {
+		r.Total = roundMoney(r.Total + l.Value)
+	}
+	if groupBy != "" {
+		r.Groups, err = GroupValuation(lines, groupBy)
+	}
+	return r, err
+}
+
+// WriteValuationReport prints the group totals, or the item
+// lines when not grouped, followed by the grand total:
+//
+//	Lab                       12     184500.00
+//	Store 2                   40      58000.00
+//	TOTAL                     52     242500.00
+//
+// Errors are returned if writing fails.
+func WriteValuationReport(w io.Writer, r ValuationReport) error {
+	var err error
+	print := func(name string, units int, value float64) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, "%-25s %5d %13.2f\n",
+				name, units, value)
+		}
+	}
+
+	units := 0
+	if r.GroupBy != "" {
+		for _, g := range r.Groups {
+			print(g.Key, g.Units, g.Value)
+		}
+	}
+	for _, l := range r.Lines {
+		if r.GroupBy == "" {
+			print(strconv.Itoa(l.ItemID)+" "+l.Description,
+				l.Units, l.Value)
+		}
+		units += l.Units
+	}
+	print("TOTAL", units, r.Total)
+	if err != nil {
+		return fmt.Errorf("write report failed: %v", err)
+	}
+	return nil
+}
+
+// money formats an amount for CSV output.
+func money(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// ExportValuationCSV writes valuation lines to a CSV file
+// with the columns:
+//
+//	item_id, description, location, status, units, unit_value, value
+//
+// Existing file will be overwritten.
+func ExportValuationCSV(filename string, lines []ValuationLine) error {
+	rows := make([][]string, 0, len(lines))
+	for _, l := range lines {
+		rows = append(rows, []string{
+			strconv.Itoa(l.ItemID), l.Description, l.Location,
+			l.Status, strconv.Itoa(l.Units),
+			money(l.UnitValue), money(l.Value),
+		})
+	}
+	return writeCSVFile(filename, []string{
+		"item_id", "description", "location", "status",
+		"units", "unit_value", "value",
+	}, rows)
+}
+
+// ExportReportJSON writes any report of this package (a
+// ValuationReport, book values or a depreciation schedule)
+// to a file as indented JSON.
+//
+// Usage:
+//
+//	err := ExportReportJSON("valuation.json", report)
+func ExportReportJSON(filename string, report interface{}) error {
+	return writeJSONFile(report, filename)
+}
+
+// DepreciationPlan sets how assets lose value.
+//
+// Fields:
+//
+//	Method      - DepreciationSL or DepreciationWDV
+//	LifeYears   - useful life in years
+//	SalvageRate - fraction of cost left at end of life, e.g. 0.05
+//	Rate        - yearly WDV rate, e.g. 0.25; 0 derives it from
+//	              LifeYears and SalvageRate
+type DepreciationPlan struct {
+	Method      string  `json:"method"`
+	LifeYears   int     `json:"life_years"`
+	SalvageRate float64 `json:"salvage_rate"`
+	Rate        float64 `json:"rate,omitempty"`
+}
+
+// rate returns the yearly WDV rate of the plan.
+func (p DepreciationPlan) rate() (float64, error) {
+	if p.Rate > 0 {
+		return p.Rate, nil
+	}
+	if p.SalvageRate <= 0 {
+		return 0, fmt.Errorf("WDV needs a rate or a salvage rate")
+	}
+	return 1 - math.Pow(p.SalvageRate, 1/float64(p.LifeYears)), nil
+}
+
+// check validates the plan.
+func (p DepreciationPlan) check() error {
+	if p.Method != DepreciationSL && p.Method != DepreciationWDV {
+		return fmt.Errorf("unknown depreciation method %q", p.Method)
+	}
+	if p.LifeYears <= 0 {
+		return fmt.Errorf("useful life must be positive")
+	}
+	if p.SalvageRate < 0 || p.SalvageRate >= 1 {
+		return fmt.Errorf("salvage rate must be in [0, 1)")
+	}
+	if p.Rate < 0 || p.Rate >= 1 {
+		return fmt.Errorf("depreciation rate must be in [0, 1)")
+	}
+	return nil
+}
+
+// DepreciationYear is one year of a depreciation schedule.
+// The year runs from From up to, but not including, To.
+type DepreciationYear struct {
+	Year         int     `json:"year"`
+	From         string  `json:"from"`
+	To           string  `json:"to"`
+	Opening      float64 `json:"opening"`
+	Depreciation float64 `json:"depreciation"`
+	Closing      float64 `json:"closing"`
+}
+
+// DepreciationSchedule returns the yearly schedule of an asset
+// bought for cost on the start date (DateLayout).
+//
+// Straight line writes off (cost - salvage) in equal parts over
+// the useful life. Written-down value writes off a fixed rate of
+// the opening value every year.
+//
+// Usage:
+//
+//	years, err := DepreciationSchedule(60000, "2024-04-01",
+//	    DepreciationPlan{Method: DepreciationSL, LifeYears: 5})
+func DepreciationSchedule(cost float64, start string,
+	p DepreciationPlan) ([]DepreciationYear, error) {
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	from, err := time.Parse(DateLayout, start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date %q", start)
+	}
+
+	rate := 0.0
+	if p.Method == DepreciationWDV {
+		if rate, err = p.rate(); err != nil {
+			return nil, err
+		}
+	}
+	straight := (cost - cost*p.SalvageRate) / float64(p.LifeYears)
+
+	years := make([]DepreciationYear, 0, p.LifeYears)
+	value := cost
+	for y := 1; y <= p.LifeYears; y++ {
+		dep := straight
+		if p.Method == DepreciationWDV {
+			dep = value * rate
+		}
+		years = append(years, DepreciationYear{
+			Year:         y,
+			From:         from.AddDate(y-1, 0, 0).Format(DateLayout),
+			To:           from.AddDate(y, 0, 0).Format(DateLayout),
+			Opening:      roundMoney(value),
+			Depreciation: roundMoney(dep),
+			Closing:      roundMoney(value - dep),
+		})
+		value -= dep
+	}
+	return years, nil
+}
+
+// accumulated returns the depreciation of a schedule up to a
+// date, pro rata by days within the running year.
+func accumulated(years []DepreciationYear, asOf string) float64 {
+	var sum float64
+	for _, y := range years {
+		if asOf >= y.To {
+			sum += y.Depreciation
+			continue
+		}
+		if asOf > y.From {
+			from, _ := time.Parse(DateLayout, y.From)
+			to, _ := time.Parse(DateLayout, y.To)
+			at, _ := time.Parse(DateLayout, asOf)
+			sum += y.Depreciation * at.Sub(from).Hours() /
+				to.Sub(from).Hours()
+		}
+		break
+	}
+	return roundMoney(sum)
+}
+
+// BookValue is the depreciated value of an asset on a date.
+type BookValue struct {
+	ItemID       int     `json:"item_id"`
+	Description  string  `json:"description"`
+	PurchaseDate string  `json:"purchase_date"`
+	Cost         float64 `json:"cost"`
+	Accumulated  float64 `json:"accumulated"`
+	BookValue    float64 `json:"book_value"`
+}
+
+// BookValues depreciates the items selected by the filter up to
+// asOf (today when blank), sorted by ID.
+//
+// The cost of an item is its average-cost valuation. Items
+// without a purchase date or cost are skipped.
+//
+// Usage:
+//
+//	plan := DepreciationPlan{Method: DepreciationWDV, Rate: 0.15}
+//	plan.LifeYears = 10
+//	list, err := BookValues(db, ItemFilter{}, plan, "2026-03-31")
+//
+// Works with both *sql.DB and *sql.Tx.
+func BookValues(db Querier, f ItemFilter, p DepreciationPlan,
+	asOf string) ([]BookValue, error) {
+	if asOf == "" {
+		asOf = today()
+	}
+	if _, err := time.Parse(DateLayout, asOf); err != nil {
+		return nil, fmt.Errorf("invalid date %q", asOf)
+	}
+	items, err := queryFiltered(db, f)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := Valuate(db, f, ValuationAverage)
+	if err != nil {
+		return nil, err
+	}
+	costs := make(map[int]float64, len(lines))
+	for _, l := range lines {
+		costs[l.ItemID] = l.Value
+	}
+
+	var list []BookValue
+	for _, item := range items {
+		cost := costs[item.ID]
+		if item.PurchaseDate == "" || cost <= 0 {
+			continue
+		}
+		years, err := DepreciationSchedule(cost, item.PurchaseDate, p)
+		if err != nil {
+			return nil, err
+		}
+		acc := accumulated(years, asOf)
+		list = append(list, BookValue{
+			ItemID:       item.ID,
+			Description:  item.Description,
+			PurchaseDate: item.PurchaseDate,
+			Cost:         cost,
+			Accumulated:  acc,
+			BookValue:    roundMoney(cost - acc),
+		})
+	}
+	return list, nil
+}
+
+// ExportBookValuesCSV writes book values to a CSV file with the
+// columns:
+//
+//	item_id, description, purchase_date, cost, accumulated, book_value
+//
+// Existing file will be overwritten.
+func ExportBookValuesCSV(filename string, list []BookValue) error {
+	rows := make([][]string, 0, len(list))
+	for _, b := range list {
+		rows = append(rows, []string{
+			strconv.Itoa(b.ItemID), b.Description, b.PurchaseDate,
+			money(b.Cost), money(b.Accumulated), money(b.BookValue),
+		})
+	}
+	return writeCSVFile(filename, []string{
+		"item_id", "description", "purchase_date",
+		"cost", "accumulated", "book_value",
+	}, rows)
+}
+
+// ExportScheduleCSV writes a depreciation schedule to a CSV
+// file with the columns:
+//
+//	year, from, to, opening, depreciation, closing
+//
+// Existing file will be overwritten.
+func ExportScheduleCSV(filename string, years []DepreciationYear) error {
+	rows := make([][]string, 0, len(years))
+	for _, y := range years {
+		rows = append(rows, []string{
+			strconv.Itoa(y.Year), y.From, y.To, money(y.Opening),
+			money(y.Depreciation), money(y.Closing),
+		})
+	}
+	return writeCSVFile(filename, []string{
+		"year", "from", "to", "opening", "depreciation", "closing",
+	}, rows)
+}
+
+// NewValuationReport wraps NewValuationReport.
+//
+// Usage:
+//
+//	r, err := inv.NewValuationReport(filter, ValuationFIFO, "status")
+func (inv *InventoryDB) NewValuationReport(f ItemFilter,
+	method, groupBy string) (ValuationReport, error) {
+	return NewValuationReport(inv.db, f, method, groupBy)
+}
+
+// BookValues wraps BookValues.
+//
+// Usage:
+//
+//	list, err := inv.BookValues(filter, plan, "")
+func (inv *InventoryDB) BookValues(f ItemFilter, p DepreciationPlan,
+	asOf string) ([]BookValue, error) {
+	return BookValues(inv.db, f, p, asOf)
+}
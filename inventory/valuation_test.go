@@ -0,0 +1,235 @@
+// valuation_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for inventory valuation and depreciation
+// Uses in-memory SQLite DB
+//
+
+package inventory_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boseji/bvl/inventory"
+)
+
+func TestInventoryDB_Valuation(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "Battery 12V", Location: "Store 2",
+			Status: "Available", Quantity: 5, ReorderQty: 10},
+		{ID: 1002, Description: "UPS 3KVA", Location: "Lab",
+			Status: "Operational", UnitCost: 38000},
+		{ID: 1003, Description: "Cable", Location: "Store 2",
+			Status: "Available", Quantity: 3, ReorderQty: 10,
+			UnitCost: 100},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+	s, _ := inv.AddSupplier(inventory.Supplier{Name: "Sharma Electricals"})
+	for _, p := range []inventory.Purchase{
+		{SupplierID: s, ItemID: 1001, InvoiceDate: "2025-01-10",
+			UnitCost: 1000, Quantity: 4},
+		{SupplierID: s, ItemID: 1001, InvoiceDate: "2025-06-18",
+			UnitCost: 1600, Quantity: 2},
+	} {
+		if _, err := inv.RecordPurchase(p); err != nil {
+			t.Fatalf("RecordPurchase failed: %v", err)
+		}
+	}
+
+	avg, err := inv.NewValuationReport(inventory.ItemFilter{},
+		inventory.ValuationAverage, "location")
+	if err != nil {
+		t.Fatalf("NewValuationReport failed: %v", err)
+	}
+	// Battery: (4x1000 + 2x1600) / 6 = 1200 x 5 units
+	if avg.Lines[0].UnitValue != 1200 || avg.Lines[0].Value != 6000 {
+		t.Errorf("unexpected average line: %+v", avg.Lines[0])
+	}
+	if avg.Lines[1].Units != 1 || avg.Lines[1].Value != 38000 {
+		t.Errorf("unexpected asset line: %+v", avg.Lines[1])
+	}
+	if avg.Total != 44300 || len(avg.Groups) != 2 ||
+		avg.Groups[1].Key != "Store 2" || avg.Groups[1].Value != 6300 {
+		t.Errorf("unexpected report: %+v", avg)
+	}
+
+	fifo, err := inv.NewValuationReport(inventory.ItemFilter{},
+		inventory.ValuationFIFO, "")
+	if err != nil {
+		t.Fatalf("NewValuationReport failed: %v", err)
+	}
+	// Battery: 2 x 1600 (latest) + 3 x 1000
+	if fifo.Lines[0].Value != 6200 {
+		t.Errorf("unexpected FIFO line: %+v", fifo.Lines[0])
+	}
+
+	var out strings.Builder
+	if err := inventory.WriteValuationReport(&out, avg); err != nil {
+		t.Fatalf("WriteValuationReport failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "TOTAL") ||
+		!strings.Contains(out.String(), "Store 2") {
+		t.Errorf("unexpected report:\n%s", out.String())
+	}
+
+	dir := t.TempDir()
+	csvFile := filepath.Join(dir, "valuation.csv")
+	if err := inventory.ExportValuationCSV(csvFile, avg.Lines); err != nil {
+		t.Errorf("ExportValuationCSV failed: %v", err)
+	}
+	jsonFile := filepath.Join(dir, "valuation.json")
+	if err := inventory.ExportReportJSON(jsonFile, avg); err != nil {
+		t.Errorf("ExportReportJSON failed: %v", err)
+	}
+
+	if _, err := inv.NewValuationReport(inventory.ItemFilter{},
+		"lifo", ""); err == nil {
+		t.Error("expected error for unknown method")
+	}
+	if _, err := inv.NewValuationReport(inventory.ItemFilter{},
+		inventory.ValuationFIFO, "supplier"); err == nil {
+		t.Error("expected error for unknown grouping")
+	}
+}
+
+func TestDepreciationSchedule(t *testing.T) {
+	sl, err := inventory.DepreciationSchedule(60000, "2024-04-01",
+		inventory.DepreciationPlan{
+			Method: inventory.DepreciationSL, LifeYears: 5,
+			SalvageRate: 0.05,
+		})
+	if err != nil {
+		t.Fatalf("DepreciationSchedule failed: %v", err)
+	}
+	if len(sl) != 5 || sl[0].Depreciation != 11400 ||
+		sl[4].Closing != 3000 || sl[1].From != "2025-04-01" {
+		t.Errorf("unexpected SL schedule: %+v", sl)
+	}
+	file := filepath.Join(t.TempDir(), "schedule.csv")
+	if err := inventory.ExportScheduleCSV(file, sl); err != nil {
+		t.Errorf("ExportScheduleCSV failed: %v", err)
+	}
+
+	wdv, err := inventory.DepreciationSchedule(10000, "2024-04-01",
+		inventory.DepreciationPlan{
+			Method: inventory.DepreciationWDV, LifeYears: 3, Rate: 0.25,
+		})
+	if err != nil {
+		t.Fatalf("DepreciationSchedule failed: %v", err)
+	}
+	if wdv[0].Depreciation != 2500 || wdv[1].Depreciation != 1875 ||
+		wdv[2].Closing != 4218.75 {
+		t.Errorf("unexpected WDV schedule: %+v", wdv)
+	}
+
+	for _, p := range []inventory.DepreciationPlan{
+		{Method: "sum-of-years", LifeYears: 5},
+		{Method: inventory.DepreciationSL},
+		{Method: inventory.DepreciationSL, LifeYears: 5, SalvageRate: 1},
+		{Method: inventory.DepreciationWDV, LifeYears: 5},
+	} {
+		if _, err := inventory.DepreciationSchedule(
+			1000, "2024-04-01", p); err == nil {
+			t.Errorf("expected error for plan %+v", p)
+		}
+	}
+	if _, err := inventory.DepreciationSchedule(1000, "01/04/2024",
+		inventory.DepreciationPlan{
+			Method: inventory.DepreciationSL, LifeYears: 5,
+		}); err == nil {
+		t.Error("expected error for invalid start date")
+	}
+}
+
+func TestInventoryDB_BookValues(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	for _, item := range []inventory.Item{
+		{ID: 1001, Description: "UPS 3KVA", UnitCost: 36500,
+			PurchaseDate: "2024-01-01"},
+		{ID: 1002, Description: "Old Desk"},
+	} {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	plan := inventory.DepreciationPlan{
+		Method: inventory.DepreciationSL, LifeYears: 5,
+	}
+	// One full year (7300) and 183 of the 365 days of the second
+	list, err := inv.BookValues(inventory.ItemFilter{}, plan, "2025-07-03")
+	if err != nil {
+		t.Fatalf("BookValues failed: %v", err)
+	}
+	if len(list) != 1 || list[0].Accumulated != 10960 ||
+		list[0].BookValue != 25540 {
+		t.Errorf("unexpected book values: %+v", list)
+	}
+
+	list, _ = inv.BookValues(inventory.ItemFilter{}, plan, "2030-01-01")
+	if list[0].BookValue != 0 {
+		t.Errorf("expected fully depreciated: %+v", list[0])
+	}
+
+	file := filepath.Join(t.TempDir(), "book.csv")
+	if err := inventory.ExportBookValuesCSV(file, list); err != nil {
+		t.Errorf("ExportBookValuesCSV failed: %v", err)
+	}
+	if _, err := inv.BookValues(inventory.ItemFilter{}, plan,
+		"soon"); err == nil {
+		t.Error("expected error for invalid date")
+	}
+}
@@ -0,0 +1,391 @@
+// xlsx.go - Part of the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+package inventory
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxSheet is the worksheet written by ExportXLSX().
+const xlsxSheet = "Inventory"
+
+// xlsxWidths lists the column widths, in characters, for the
+// columns of csvHeader. Columns not listed use xlsxDefaultWidth.
+var xlsxWidths = map[string]float64{
+	"id":          8,
+	"description": 40,
+	"location":    20,
+	"status":      16,
+	"remarks":     60,
+}
+
+// xlsxDefaultWidth is the width of columns not in xlsxWidths.
+const xlsxDefaultWidth = 14
+
+// ExportXLSX writes all inventory records to an Excel workbook.
+//
+// Usage:
+//
+//	err := ExportXLSX(db, "inventory.xlsx")
+//
+// The workbook has one sheet named "Inventory" with the same
+// columns as ExportCSV(), a bold header row frozen at the top,
+// fixed column widths and wrapped text, so multi-line remarks
+// stay in one cell.
+//
+// Existing file will be overwritten.
+func ExportXLSX(db *sql.DB, filename string) error {
+	return ExportXLSXFiltered(db, filename, ItemFilter{})
+}
+
+// ExportXLSXFiltered writes the inventory records selected
+// by the filter to an Excel workbook.
+//
+// Usage:
+//
+//	f := ItemFilter{Location: "Store"}
+//	err := ExportXLSXFiltered(db, "store.xlsx", f)
+//
+// The layout is the same as ExportXLSX().
+func ExportXLSXFiltered(db *sql.DB, filename string, f ItemFilter) error {
+	book := excelize.NewFile()
+	defer book.Close()
+
+	if err := book.SetSheetName("Sheet1", xlsxSheet); err != nil {
+		return fmt.Errorf("create xlsx failed: %v", err)
+	}
+	sw, err := book.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		return fmt.Errorf("create xlsx failed: %v", err)
+	}
+
+	headStyle, err := book.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Alignment: &excelize.Alignment{Vertical: "top"},
+	})
+	if err != nil {
+		return fmt.Errorf("create xlsx style failed: %v", err)
+	}
+	cellStyle, err := book.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Vertical: "top", WrapText: true},
+	})
+	if err != nil {
+		return fmt.Errorf("create xlsx style failed: %v", err)
+	}
+
+	for i, name := range csvHeader {
+		width, ok := xlsxWidths[name]
+		if !ok {
+			width = xlsxDefaultWidth
+		}
+		if err := sw.SetColWidth(i+1, i+1, width); err != nil {
+			return fmt.Errorf("set xlsx column width failed: %v", err)
+		}
+	}
+	err = sw.SetPanes(&excelize.Panes{
+		Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft",
+	})
+	if err != nil {
+		return fmt.Errorf("freeze xlsx header failed: %v", err)
+	}
+
+	head := make([]interface{}, len(csvHeader))
+	for i, name := range csvHeader {
+		head[i] = excelize.Cell{StyleID: headStyle, Value: name}
+	}
+	if err := sw.SetRow("A1", head); err != nil {
+		return fmt.Errorf("write xlsx header failed: %v", err)
+	}
+
+	iter, err := NewFilteredItemIterator(db, f)
+	if err != nil {
+		return fmt.Errorf("query inventory failed: %v", err)
+	}
+	defer iter.Close()
+
+	for row := 2; ; row++ {
+		item, ok, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("scan failed: %v", err)
+		}
+		if !ok {
+			break
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		if err := sw.SetRow(cell, itemToXLSX(item, cellStyle)); err != nil {
+			return fmt.Errorf("write xlsx row failed: %v", err)
+		}
+	}
+
+	if err := sw.Flush(); err != nil {
+		return fmt.Errorf("write xlsx failed: %v", err)
+	}
+	if err := book.SaveAs(filename); err != nil {
+		return fmt.Errorf("save xlsx failed: %v", err)
+	}
+	return nil
+}
+
+// itemToXLSX returns the cells for an item, in csvHeader order.
+//
+// Numbers are written as number cells and everything else as
+// text, so Excel keeps leading zeros and does not turn dates into
+// serial numbers. An unset parent is left blank.
+func itemToXLSX(item Item, style int) []interface{} {
+	record := itemToCSV(item)
+	cells := make([]interface{}, len(record))
+	for i, name := range csvHeader {
+		var v interface{} = record[i]
+		switch name {
+		case "id":
+			v = item.ID
+		case "parent_id":
+			v = nil
+			if item.ParentID != 0 {
+				v = item.ParentID
+			}
+		case "quantity":
+			v = item.Quantity
+		case "reorder_level":
+			v = item.ReorderLevel
+		case "reorder_qty":
+			v = item.ReorderQty
+		case "unit_cost":
+			v = item.UnitCost
+		}
+		cells[i] = excelize.Cell{StyleID: style, Value: v}
+	}
+	return cells
+}
+
+// ImportXLSX reads inventory records from an Excel workbook and
+// imports them.
+//
+// Existing records with matching IDs will be replaced.
+//
+// Usage:
+//
+//	err := ImportXLSX(db, "inventory.xlsx")
+//
+// The rows are read by ReadXLSXItems() and imported using
+// AppendItem().
+//
+// Returns error on file error, parse error, or DB error.
+func ImportXLSX(exec Execer, filename string) error {
+	items, err := ReadXLSXItems(filename)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		if err := AppendItem(exec, item); err != nil {
+			return fmt.Errorf("import row %d failed: %v", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// ReadXLSXItems parses an Excel workbook into items, without
+// touching the database.
+//
+// Usage:
+//
+//	items, err := ReadXLSXItems("inventory.xlsx")
+//
+// The "Inventory" sheet is read, or the first sheet if there is
+// none. The first row is a header, mapped the same way as
+// ImportCSV(): columns by name, in any order, only "id" required.
+// Blank rows are skipped. Cells formatted as dates in Excel are
+// read as YYYY-MM-DD.
+//
+// Returns error on file error or parse error.
+func ReadXLSXItems(filename string) ([]Item, error) {
+	book, err := excelize.OpenFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("open xlsx failed: %v", err)
+	}
+	defer book.Close()
+
+	sheet := xlsxSheet
+	if idx, _ := book.GetSheetIndex(sheet); idx < 0 {
+		sheet = book.GetSheetName(0)
+	}
+	rows, err := book.GetRows(sheet,
+		excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("read xlsx failed: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header, err := csvHeaderIndex(rows[0])
+	if err != nil {
+		return nil, err
+	}
+	props, _ := book.GetWorkbookProps()
+	date1904 := props.Date1904 != nil && *props.Date1904
+
+	var items []Item
+	for i, row := range rows[1:] {
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+		// Trailing empty cells are not stored in the sheet.
+		for len(row) < len(rows[0]) {
+			row = append(row, "")
+		}
+		for col := range row {
+			row[col] = xlsxDateCell(book, sheet, col+1, i+2, row[col],
+				date1904)
+		}
+		item, err := itemFromCSV(header, row)
+		if err != nil {
+			return nil, fmt.Errorf("xlsx row %d: %v", i+2, err)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// xlsxDateFormats are the built-in number formats that show
+// a date.
+var xlsxDateFormats = map[int]bool{
+	14: true, 15: true, 16: true, 17: true, 22: true,
+	27: true, 28: true, 29: true, 30: true, 31: true,
+	32: true, 33: true, 34: true, 35: true, 36: true,
+	50: true, 51: true, 52: true, 53: true, 54: true,
+	55: true, 56: true, 57: true, 58: true,
+}
+
+// reXLSXFormatText matches the quoted text, escapes and [...]
+// sections of a number format, which are not date codes.
+var reXLSXFormatText = regexp.MustCompile(`"[^"]*"|\\.|\[[^\]]*\]`)
+
+// xlsxDateCell returns a cell value as YYYY-MM-DD if the cell is
+// a date. Excel keeps dates as serial day numbers shown through a
+// date number format, and raw cell values are those numbers
+// (counted from 1904 in workbooks with date1904 set). Other cells
+// are returned as they are.
+func xlsxDateCell(book *excelize.File, sheet string, col, row int,
+	value string, date1904 bool) string {
+	serial, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	cell, err := excelize.CoordinatesToCellName(col, row)
+	if err != nil {
+		return value
+	}
+	idx, err := book.GetCellStyle(sheet, cell)
+	if err != nil || idx == 0 {
+		return value
+	}
+	style, err := book.GetStyle(idx)
+	if err != nil {
+		return value
+	}
+
+	isDate := xlsxDateFormats[style.NumFmt]
+	if style.CustomNumFmt != nil {
+		code := strings.ToLower(
+			reXLSXFormatText.ReplaceAllString(*style.CustomNumFmt, ""))
+		isDate = strings.ContainsAny(code, "dy")
+	}
+	if !isDate {
+		return value
+	}
+	t, err := excelize.ExcelDateToTime(serial, date1904)
+	if err != nil {
+		return value
+	}
+	return t.Format(DateLayout)
+}
+
+// ExportXLSX writes all inventory records to Excel using
+// InventoryDB.
+//
+// Usage:
+//
+//	err := inv.ExportXLSX("inventory.xlsx")
+//
+// Same as ExportXLSX() raw.
+func (inv *InventoryDB) ExportXLSX(filename string) error {
+	return ExportXLSX(inv.db, filename)
+}
+
+// ExportXLSXFiltered writes filtered records to Excel using
+// InventoryDB.
+//
+// Usage:
+//
+//	err := inv.ExportXLSXFiltered("store.xlsx", filter)
+func (inv *InventoryDB) ExportXLSXFiltered(
+	filename string, f ItemFilter) error {
+	return ExportXLSXFiltered(inv.db, filename, f)
+}
+
+// ImportXLSX imports inventory records from Excel using
+// InventoryDB.
+//
+// Usage:
+//
+//	err := inv.ImportXLSX("inventory.xlsx")
+//
+// The import runs inside a transaction.
+func (inv *InventoryDB) ImportXLSX(filename string) error {
+	return inv.withTx("import_xlsx", func(tx ExecQuerier) error {
+		return ImportXLSX(tx, filename)
+	})
+}
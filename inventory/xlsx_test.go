@@ -0,0 +1,200 @@
+// xlsx_test.go - Part of Tests for the `inventory` Package
+//
+//     ॐ भूर्भुवः स्वः
+//     तत्स॑वि॒तुर्वरे॑ण्यं॒
+//    भर्गो॑ दे॒वस्य॑ धीमहि।
+//   धियो॒ यो नः॑ प्रचो॒दया॑त्॥
+//
+//
+//  बी.वी.एल - बोसजी के द्वारा रचित भंडार लेखांकन हेतु तन्त्राक्ष्।
+// =============================================
+//
+// एक सुगम एवं उपयोगी भंडार संचालन हेतु तन्त्राक्ष्।
+//
+// एक रचनात्मक भारतीय उत्पाद ।
+//
+// bvl - Boseji's Inventory Management Program
+//
+// Easy to use and useful stock, goods and materials handling software.
+//
+// Sources
+// -------
+// https://github.com/boseji/bvl
+//
+// License
+// -------
+//
+//   bvl - Boseji's Inventory Management Program.
+//   Copyright (C) 2025 by Abhijit Bose (aka. Boseji)
+//
+//   This program is free software: you can redistribute it and/or modify
+//   it under the terms of the GNU General Public License version 2 only
+//   as published by the Free Software Foundation.
+//
+//   This program is distributed in the hope that it will be useful,
+//   but WITHOUT ANY WARRANTY; without even the implied warranty of
+//   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+//
+//   You should have received a copy of the GNU General Public License
+//   along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+//  SPDX-License-Identifier: GPL-2.0-only
+//  Full Name: GNU General Public License v2.0 only
+//  Please visit <https://spdx.org/licenses/GPL-2.0-only.html> for details.
+//
+
+//
+// Unit tests for Excel (.xlsx) import and export
+// Uses in-memory SQLite DB and temp workbooks
+//
+
+package inventory_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boseji/bvl/inventory"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExportImportXLSX(t *testing.T) {
+	inv := setupInventoryDB(t)
+	defer inv.Close()
+
+	want := []inventory.Item{
+		{ID: 1001, Description: "यूपीएस 3KVA", Location: "Rack 1",
+			Status: "Operational", Remarks: "installed\nserviced 2025",
+			Quantity: 2, UnitCost: 38000.5},
+		{ID: 1002, Description: "Cable", Location: "00412",
+			ParentID: 1001, PurchaseDate: "2025-06-18"},
+	}
+	for _, item := range want {
+		if err := inv.AppendItem(item); err != nil {
+			t.Fatalf("AppendItem failed: %v", err)
+		}
+	}
+
+	want, _ = inv.ListAll()
+
+	file := filepath.Join(t.TempDir(), "inventory.xlsx")
+	if err := inv.ExportXLSX(file); err != nil {
+		t.Fatalf("ExportXLSX failed: %v", err)
+	}
+
+	book, err := excelize.OpenFile(file)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	panes, err := book.GetPanes("Inventory")
+	if err != nil || !panes.Freeze || panes.YSplit != 1 {
+		t.Errorf("header not frozen: %+v %v", panes, err)
+	}
+	if w, _ := book.GetColWidth("Inventory", "E"); w != 60 {
+		t.Errorf("unexpected remarks width: %v", w)
+	}
+	if v, _ := book.GetCellValue("Inventory", "C3"); v != "00412" {
+		t.Errorf("leading zeros lost: %q", v)
+	}
+	book.Close()
+
+	items, err := inventory.ReadXLSXItems(file)
+	if err != nil {
+		t.Fatalf("ReadXLSXItems failed: %v", err)
+	}
+	if len(items) != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), len(items))
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("row %d: got %+v, want %+v", i, items[i], want[i])
+		}
+	}
+
+	other := setupInventoryDB(t)
+	defer other.Close()
+	if err := other.ImportXLSX(file); err != nil {
+		t.Fatalf("ImportXLSX failed: %v", err)
+	}
+	got, _ := other.GetItemByID(1001)
+	if got.Remarks != want[0].Remarks {
+		t.Errorf("multi-line remarks changed: %q", got.Remarks)
+	}
+}
+
+func TestReadXLSXItems_Mapping(t *testing.T) {
+	book := excelize.NewFile()
+	rows := [][]interface{}{
+		{"Description", "ID", "Quantity"},
+		{"Fuse 5A", 1005, 10},
+		{},
+		{"Fuse 10A", 1006},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := book.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatalf("SetSheetRow failed: %v", err)
+		}
+	}
+	file := filepath.Join(t.TempDir(), "fuses.xlsx")
+	if err := book.SaveAs(file); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+
+	items, err := inventory.ReadXLSXItems(file)
+	if err != nil {
+		t.Fatalf("ReadXLSXItems failed: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != 1005 ||
+		items[0].Quantity != 10 || items[1].Description != "Fuse 10A" {
+		t.Errorf("unexpected items: %+v", items)
+	}
+
+	book.SetCellValue("Sheet1", "C2", "ten")
+	if err := book.SaveAs(file); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if _, err := inventory.ReadXLSXItems(file); err == nil {
+		t.Error("expected error for invalid quantity")
+	}
+	if _, err := inventory.ReadXLSXItems("no_such_file.xlsx"); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestReadXLSXItems_Dates(t *testing.T) {
+	book := excelize.NewFile()
+	row := []interface{}{"id", "purchase_date", "expiry_date",
+		"warranty_until", "quantity"}
+	if err := book.SetSheetRow("Sheet1", "A1", &row); err != nil {
+		t.Fatalf("SetSheetRow failed: %v", err)
+	}
+	book.SetCellValue("Sheet1", "A2", 1001)
+	book.SetCellValue("Sheet1", "B2",
+		time.Date(2025, 6, 18, 0, 0, 0, 0, time.UTC))
+	book.SetCellValue("Sheet1", "C2", 46022) // 2025-12-31
+	custom := "dd/mm/yyyy"
+	style, err := book.NewStyle(&excelize.Style{CustomNumFmt: &custom})
+	if err != nil {
+		t.Fatalf("NewStyle failed: %v", err)
+	}
+	book.SetCellStyle("Sheet1", "C2", "C2", style)
+	book.SetCellValue("Sheet1", "D2", "2026-06-18")
+	book.SetCellValue("Sheet1", "E2", 45828)
+
+	file := filepath.Join(t.TempDir(), "dates.xlsx")
+	if err := book.SaveAs(file); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	items, err := inventory.ReadXLSXItems(file)
+	if err != nil {
+		t.Fatalf("ReadXLSXItems failed: %v", err)
+	}
+	if len(items) != 1 || items[0].PurchaseDate != "2025-06-18" ||
+		items[0].ExpiryDate != "2025-12-31" ||
+		items[0].WarrantyUntil != "2026-06-18" ||
+		items[0].Quantity != 45828 {
+		t.Errorf("unexpected items: %+v", items)
+	}
+}
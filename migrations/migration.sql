@@ -32,12 +32,189 @@ PRAGMA foreign_keys = OFF;
 BEGIN TRANSACTION;
 
 -- Create inventory table
+-- The triggers that reject kit cycles in parent_id are installed by the
+-- application when it opens the database.
 CREATE TABLE IF NOT EXISTS inventory (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     description TEXT,
     location TEXT,
     status TEXT,
-    remarks TEXT
+    remarks TEXT,
+    parent_id INTEGER,
+    purchase_date TEXT,
+    warranty_until TEXT,
+    expiry_date TEXT,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    reorder_level INTEGER NOT NULL DEFAULT 0,
+    reorder_qty INTEGER NOT NULL DEFAULT 0,
+    unit_cost REAL NOT NULL DEFAULT 0
+);
+
+-- Create maintenance plans table
+CREATE TABLE IF NOT EXISTS maintenance_plans (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    task TEXT,
+    interval_days INTEGER NOT NULL,
+    last_done TEXT,
+    next_due TEXT NOT NULL
+);
+
+-- Create attachments table
+CREATE TABLE IF NOT EXISTS attachments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    filename TEXT NOT NULL,
+    mime_type TEXT,
+    sha256 TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    created_at TEXT,
+    data BLOB
+);
+
+-- Create audit log tables
+-- The audit triggers on the inventory table, and the triggers that
+-- keep audit_log append-only, are installed by the application when
+-- it opens the database.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ts TEXT NOT NULL,
+    actor TEXT,
+    operation TEXT,
+    action TEXT NOT NULL,
+    item_id INTEGER,
+    before TEXT,
+    after TEXT,
+    changeset_id INTEGER
+);
+CREATE INDEX IF NOT EXISTS audit_log_item ON audit_log (item_id);
+CREATE INDEX IF NOT EXISTS audit_log_changeset ON audit_log (changeset_id);
+
+CREATE TABLE IF NOT EXISTS audit_session (
+    ts TEXT,
+    actor TEXT,
+    operation TEXT,
+    changeset_id INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS changesets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ts TEXT NOT NULL,
+    actor TEXT,
+    operation TEXT,
+    reverted_by INTEGER
+);
+
+-- Create access control tables
+CREATE TABLE IF NOT EXISTS users (
+    name TEXT PRIMARY KEY,
+    role TEXT NOT NULL,
+    created_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    label TEXT,
+    created_at TEXT
+);
+
+-- Create stocktake tables
+CREATE TABLE IF NOT EXISTS stocktakes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT,
+    scope TEXT,
+    state TEXT NOT NULL,
+    started_at TEXT,
+    reconciled_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS stocktake_items (
+    stocktake_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    description TEXT,
+    expected_location TEXT,
+    expected_status TEXT,
+    expected INTEGER NOT NULL,
+    mark TEXT NOT NULL DEFAULT '',
+    found_location TEXT NOT NULL DEFAULT '',
+    marked_at TEXT,
+    PRIMARY KEY (stocktake_id, item_id)
+);
+
+-- Create per-location stock tables
+-- The triggers that keep inventory.quantity equal to the balances are
+-- installed by the application when it opens the database.
+CREATE TABLE IF NOT EXISTS stock_balances (
+    item_id INTEGER NOT NULL,
+    location TEXT NOT NULL,
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (item_id, location)
+);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    location TEXT NOT NULL,
+    quantity INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    ref TEXT,
+    note TEXT,
+    ts TEXT
+);
+
+-- Create supplier and purchase tables
+CREATE TABLE IF NOT EXISTS suppliers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    contact TEXT,
+    gstin TEXT,
+    notes TEXT
+);
+
+CREATE TABLE IF NOT EXISTS purchases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    supplier_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    invoice_no TEXT,
+    invoice_date TEXT,
+    unit_cost REAL NOT NULL DEFAULT 0,
+    quantity INTEGER NOT NULL,
+    notes TEXT
+);
+
+-- Create sync tables
+-- The version triggers on the inventory table, which give each new
+-- row its uid, are installed by the application when it opens the
+-- database.
+CREATE TABLE IF NOT EXISTS item_versions (
+    item_id INTEGER PRIMARY KEY,
+    version INTEGER NOT NULL,
+    updated_at TEXT NOT NULL,
+    deleted INTEGER NOT NULL DEFAULT 0,
+    uid TEXT
+);
+CREATE UNIQUE INDEX IF NOT EXISTS item_versions_uid ON item_versions (uid);
+
+CREATE TABLE IF NOT EXISTS sync_meta (
+    key TEXT PRIMARY KEY,
+    value TEXT
+);
+
+CREATE TABLE IF NOT EXISTS sync_base (
+    peer TEXT NOT NULL,
+    uid TEXT NOT NULL,
+    local_version INTEGER NOT NULL,
+    remote_version INTEGER NOT NULL,
+    row TEXT,
+    PRIMARY KEY (peer, uid)
+);
+
+CREATE TABLE IF NOT EXISTS delta_sources (
+    source TEXT PRIMARY KEY,
+    last_seq INTEGER NOT NULL,
+    applied_at TEXT
 );
 
 -- Initialize AUTOINCREMENT sequence to start at 1001